@@ -0,0 +1,122 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package capture implements packet capturing on an interface with AF_PACKET sockets.
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// DefaultSnapLen is the default maximum number of bytes captured from each packet.
+	DefaultSnapLen = 262144
+	// readTimeout is the timeout of a socket read, which defines how often
+	// the capture checks if it should be stopped.
+	readTimeout = 100 * time.Millisecond
+)
+
+// Handle is a packet capture handle bound to an interface.
+type Handle struct {
+	fd      int
+	snapLen int
+}
+
+// Open opens a packet capture handle on the interface ifName in the current network namespace.
+// Once opened, the handle keeps capturing in that network namespace regardless of the namespace it is used from.
+// When filter is not empty, it is attached to the socket to filter the captured packets in the kernel.
+func Open(ifName string, snapLen int, filter []unix.SockFilter) (*Handle, error) {
+	iface, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup interface %q: %v", ifName, err)
+	}
+
+	// the socket is created with no protocol, so that it doesn't receive any packets
+	// until it is bound to the interface, after the filter is attached
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %v", err)
+	}
+	h := &Handle{fd: fd, snapLen: snapLen}
+
+	if len(filter) != 0 {
+		prog := &unix.SockFprog{
+			Len:    uint16(len(filter)),
+			Filter: &filter[0],
+		}
+		if err := unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, prog); err != nil {
+			h.Close()
+			return nil, fmt.Errorf("failed to attach filter: %v", err)
+		}
+	}
+
+	tv := unix.NsecToTimeval(readTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	err = unix.Bind(fd, &unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_ALL),
+		Ifindex:  iface.Index,
+	})
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to bind packet socket to interface %q: %v", ifName, err)
+	}
+
+	return h, nil
+}
+
+// Capture writes the captured packets to w until count packets are captured or ctx is done.
+// A zero count means no limit. Capture returns the number of captured packets.
+func (h *Handle) Capture(ctx context.Context, w *PcapngWriter, count int) (int, error) {
+	buf := make([]byte, h.snapLen)
+	captured := 0
+
+	for count == 0 || captured < count {
+		select {
+		case <-ctx.Done():
+			return captured, nil
+		default:
+		}
+
+		// with MSG_TRUNC the length of the packet on the wire is returned even if it exceeds the buffer
+		n, _, err := unix.Recvfrom(h.fd, buf, unix.MSG_TRUNC)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return captured, err
+		}
+
+		capLen := n
+		if capLen > len(buf) {
+			capLen = len(buf)
+		}
+
+		if err := w.WritePacket(time.Now(), buf[:capLen], n); err != nil {
+			return captured, err
+		}
+		captured++
+	}
+
+	return captured, nil
+}
+
+// Close closes the capture handle.
+func (h *Handle) Close() error {
+	return unix.Close(h.fd)
+}
+
+// htons converts a short from host to network byte order.
+func htons(i uint16) uint16 {
+	return (i<<8)&0xff00 | i>>8
+}
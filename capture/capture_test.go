@@ -0,0 +1,63 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestPcapngWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	w, err := NewPcapngWriter(buf, "eth1", DefaultSnapLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePacket(time.Unix(1, 0), []byte{1, 2, 3, 4, 5}, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+	// expected blocks: section header, interface description and enhanced packet
+	wantBlocks := []struct {
+		t uint32
+		l uint32
+	}{
+		{pcapngSectionHeaderBlock, 28},
+		{pcapngInterfaceDescBlock, 32},
+		{pcapngEnhancedPacketBlock, 40},
+	}
+
+	for _, wb := range wantBlocks {
+		if len(b) < 12 {
+			t.Fatalf("unexpected end of output")
+		}
+		bt := binary.LittleEndian.Uint32(b[0:])
+		bl := binary.LittleEndian.Uint32(b[4:])
+		if bt != wb.t || bl != wb.l {
+			t.Fatalf("expected block type %#x with length %d, got type %#x with length %d", wb.t, wb.l, bt, bl)
+		}
+		if trailer := binary.LittleEndian.Uint32(b[bl-4:]); trailer != bl {
+			t.Errorf("block type %#x trailing length %d doesn't match the length %d", bt, trailer, bl)
+		}
+
+		if bt == pcapngEnhancedPacketBlock {
+			if ts := binary.LittleEndian.Uint32(b[16:]); ts != 1000000 {
+				t.Errorf("expected timestamp 1000000, got %d", ts)
+			}
+			if capLen, origLen := binary.LittleEndian.Uint32(b[20:]), binary.LittleEndian.Uint32(b[24:]); capLen != 5 || origLen != 60 {
+				t.Errorf("expected captured/original length 5/60, got %d/%d", capLen, origLen)
+			}
+		}
+		b = b[bl:]
+	}
+
+	if len(b) != 0 {
+		t.Errorf("unexpected %d trailing bytes", len(b))
+	}
+}
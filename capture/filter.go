@@ -0,0 +1,285 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeARP  = 0x0806
+	etherTypeIPv6 = 0x86dd
+
+	ipProtoICMP   = 1
+	ipProtoTCP    = 6
+	ipProtoUDP    = 17
+	ipProtoICMPv6 = 58
+
+	// offsets of the fields in an Ethernet frame
+	etherTypeOff   = 12
+	ipOff          = 14
+	ipv4FragOff    = ipOff + 6
+	ipv4ProtoOff   = ipOff + 9
+	ipv4SrcOff     = ipOff + 12
+	ipv4DstOff     = ipOff + 16
+	ipv6NextHdrOff = ipOff + 6
+	ipv6SrcOff     = ipOff + 8
+	ipv6DstOff     = ipOff + 24
+	ipv6PayloadOff = ipOff + 40
+)
+
+// filterSyntax is the grammar of the capture filters reported with the syntax errors.
+const filterSyntax = `[not] <primitive> [and [not] <primitive>]..., ` +
+	`where <primitive> is one of arp, ip, ip6, icmp, icmp6, tcp, udp, host <address> or port <port>`
+
+// CompileFilter compiles the filter expression into a classic BPF program for a packet socket
+// capturing on an Ethernet interface, which accepts snapLen bytes of the matched packets.
+//
+// The filter is a minimal subset of the pcap-filter(7) syntax:
+//
+//	filter    = [not] primitive { and [not] primitive }
+//	primitive = arp | ip | ip6 | icmp | icmp6 | tcp | udp | host <ipv4/ipv6 address> | port <port>
+//
+// host matches the source or destination address and port matches the source or destination TCP or UDP port
+// of IPv4 non-fragmented packets and IPv6 packets without extension headers.
+// Other pcap-filter syntax, e.g. or and parentheses, is rejected.
+func CompileFilter(expr string, snapLen int) ([]unix.SockFilter, error) {
+	insns, err := compileFilter(expr, snapLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile filter %q: %v", expr, err)
+	}
+
+	raw, err := bpf.Assemble(insns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble filter %q: %v", expr, err)
+	}
+
+	prog := make([]unix.SockFilter, 0, len(raw))
+	for _, r := range raw {
+		prog = append(prog, unix.SockFilter{Code: r.Op, Jt: r.Jt, Jf: r.Jf, K: r.K})
+	}
+
+	return prog, nil
+}
+
+// compileFilter compiles the filter expression into a program, which drops the packets not matched
+// by any of the primitives one after another and accepts snapLen bytes of the rest.
+func compileFilter(expr string, snapLen int) ([]bpf.Instruction, error) {
+	tokens := strings.Fields(expr)
+	if len(tokens) == 0 {
+		return nil, errors.New("empty filter expression")
+	}
+
+	var prog []bpf.Instruction
+	for i := 0; i < len(tokens); {
+		if i != 0 {
+			if tokens[i] != "and" {
+				return nil, fmt.Errorf("unsupported syntax at %q, expected %s", tokens[i], filterSyntax)
+			}
+			i++
+		}
+
+		negate := false
+		if i < len(tokens) && tokens[i] == "not" {
+			negate = true
+			i++
+		}
+
+		m, n, err := parsePrimitive(tokens[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		prog, err = m.compile(prog, negate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(prog, bpf.RetConstant{Val: uint32(snapLen)}), nil
+}
+
+// parsePrimitive parses the primitive at the start of tokens
+// and returns its match and the number of the tokens it consists of.
+func parsePrimitive(tokens []string) (match, int, error) {
+	if len(tokens) == 0 {
+		return nil, 0, fmt.Errorf("unexpected end of filter expression, expected %s", filterSyntax)
+	}
+
+	switch t := tokens[0]; t {
+	case "arp":
+		return match{{etherType(etherTypeARP)}}, 1, nil
+	case "ip":
+		return match{{etherType(etherTypeIPv4)}}, 1, nil
+	case "ip6":
+		return match{{etherType(etherTypeIPv6)}}, 1, nil
+	case "icmp":
+		return match{ipv4Proto(ipProtoICMP)}, 1, nil
+	case "icmp6":
+		return match{ipv6Proto(ipProtoICMPv6)}, 1, nil
+	case "tcp":
+		return match{ipv4Proto(ipProtoTCP), ipv6Proto(ipProtoTCP)}, 1, nil
+	case "udp":
+		return match{ipv4Proto(ipProtoUDP), ipv6Proto(ipProtoUDP)}, 1, nil
+	case "host":
+		if len(tokens) < 2 {
+			return nil, 0, errors.New("missing host address")
+		}
+		ip := net.ParseIP(tokens[1])
+		if ip == nil {
+			return nil, 0, fmt.Errorf("invalid host address %q", tokens[1])
+		}
+		return hostMatch(ip), 2, nil
+	case "port":
+		if len(tokens) < 2 {
+			return nil, 0, errors.New("missing port")
+		}
+		port, err := strconv.ParseUint(tokens[1], 10, 16)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid port %q", tokens[1])
+		}
+		return portMatch(uint32(port)), 2, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported syntax at %q, expected %s", t, filterSyntax)
+	}
+}
+
+// test loads a value of the packet into the accumulator and tests it against val.
+type test struct {
+	load []bpf.Instruction
+	cond bpf.JumpTest
+	val  uint32
+}
+
+// match matches the packets passing all the tests of any of its test lists.
+type match [][]test
+
+// equals tests that the value of size bytes at the offset off equals val.
+func equals(off uint32, size int, val uint32) test {
+	return test{
+		load: []bpf.Instruction{bpf.LoadAbsolute{Off: off, Size: size}},
+		cond: bpf.JumpEqual,
+		val:  val,
+	}
+}
+
+func etherType(t uint32) test {
+	return equals(etherTypeOff, 2, t)
+}
+
+func ipv4Proto(p uint32) []test {
+	return []test{etherType(etherTypeIPv4), equals(ipv4ProtoOff, 1, p)}
+}
+
+func ipv6Proto(p uint32) []test {
+	return []test{etherType(etherTypeIPv6), equals(ipv6NextHdrOff, 1, p)}
+}
+
+// hostMatch matches the IP packets with the source or destination address ip.
+func hostMatch(ip net.IP) match {
+	et, offs := uint32(etherTypeIPv6), []uint32{ipv6SrcOff, ipv6DstOff}
+	if ip4 := ip.To4(); ip4 != nil {
+		et, offs, ip = etherTypeIPv4, []uint32{ipv4SrcOff, ipv4DstOff}, ip4
+	}
+
+	var m match
+	for _, off := range offs {
+		tests := []test{etherType(et)}
+		// the address is compared 4 bytes at a time
+		for i := 0; i < len(ip); i += 4 {
+			tests = append(tests, equals(off+uint32(i), 4, uint32(ip[i])<<24|uint32(ip[i+1])<<16|uint32(ip[i+2])<<8|uint32(ip[i+3])))
+		}
+		m = append(m, tests)
+	}
+
+	return m
+}
+
+// portMatch matches the TCP and UDP packets with the source or destination port.
+func portMatch(port uint32) match {
+	var m match
+	for _, p := range []uint32{ipProtoTCP, ipProtoUDP} {
+		for _, off := range []uint32{0, 2} {
+			// only the first fragment of a packet has the ports,
+			// which follow the IPv4 header of the length loaded to the index register
+			m = append(m, append(ipv4Proto(p),
+				test{
+					load: []bpf.Instruction{bpf.LoadAbsolute{Off: ipv4FragOff, Size: 2}},
+					cond: bpf.JumpBitsNotSet,
+					val:  0x1fff,
+				},
+				test{
+					load: []bpf.Instruction{bpf.LoadMemShift{Off: ipOff}, bpf.LoadIndirect{Off: ipOff + off, Size: 2}},
+					cond: bpf.JumpEqual,
+					val:  port,
+				},
+			))
+			m = append(m, append(ipv6Proto(p), equals(ipv6PayloadOff+off, 2, port)))
+		}
+	}
+
+	return m
+}
+
+// compile appends the instructions of the match to prog, which are followed by the instructions of the next primitive.
+// The packets matched, or not matched when negate is set, continue to the next primitive, the rest are dropped.
+func (m match) compile(prog []bpf.Instruction, negate bool) ([]bpf.Instruction, error) {
+	// the test lists start at the offsets from the start of the match instructions,
+	// which are followed by the instruction dropping the packet
+	starts := make([]int, len(m)+1)
+	for i, tests := range m {
+		starts[i+1] = starts[i]
+		for _, t := range tests {
+			starts[i+1] += len(t.load) + 1
+		}
+	}
+	drop := starts[len(m)]
+	matched, unmatched := drop+1, drop
+	if negate {
+		matched, unmatched = drop, drop+1
+	}
+
+	var insns []bpf.Instruction
+	for i, tests := range m {
+		// the test list fails to the next test list, the last test list fails the match
+		fail := unmatched
+		if i != len(m)-1 {
+			fail = starts[i+1]
+		}
+
+		for j, t := range tests {
+			insns = append(insns, t.load...)
+			pos := len(insns)
+
+			pass := pos + 1
+			if j == len(tests)-1 {
+				pass = matched
+			}
+			if pass-pos-1 > math.MaxUint8 || fail-pos-1 > math.MaxUint8 {
+				return nil, errors.New("filter expression is too long")
+			}
+
+			insns = append(insns, bpf.JumpIf{
+				Cond:      t.cond,
+				Val:       t.val,
+				SkipTrue:  uint8(pass - pos - 1),
+				SkipFalse: uint8(fail - pos - 1),
+			})
+		}
+	}
+	insns = append(insns, bpf.RetConstant{Val: 0})
+
+	return append(prog, insns...), nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package capture
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"golang.org/x/net/bpf"
+)
+
+var (
+	testMAC1 = net.HardwareAddr{0xaa, 0xc1, 0xab, 0x00, 0x00, 0x01}
+	testMAC2 = net.HardwareAddr{0xaa, 0xc1, 0xab, 0x00, 0x00, 0x02}
+)
+
+func ethFrame(etherType uint16, payload []byte) []byte {
+	b := make([]byte, 14, 14+len(payload))
+	copy(b[0:], testMAC2)
+	copy(b[6:], testMAC1)
+	binary.BigEndian.PutUint16(b[12:], etherType)
+	return append(b, payload...)
+}
+
+// ipv4Packet returns an IPv4 packet with the header of ihl 4 byte words.
+func ipv4Packet(proto byte, src, dst string, ihl int, fragOff uint16, payload []byte) []byte {
+	b := make([]byte, ihl*4, ihl*4+len(payload))
+	b[0] = 0x40 | byte(ihl)
+	binary.BigEndian.PutUint16(b[6:], fragOff)
+	b[9] = proto
+	copy(b[12:], net.ParseIP(src).To4())
+	copy(b[16:], net.ParseIP(dst).To4())
+	return ethFrame(etherTypeIPv4, append(b, payload...))
+}
+
+func ipv6Packet(nextHdr byte, src, dst string, payload []byte) []byte {
+	b := make([]byte, 40, 40+len(payload))
+	b[0] = 0x60
+	b[6] = nextHdr
+	copy(b[8:], net.ParseIP(src))
+	copy(b[24:], net.ParseIP(dst))
+	return ethFrame(etherTypeIPv6, append(b, payload...))
+}
+
+func l4Ports(src, dst uint16) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint16(b[0:], src)
+	binary.BigEndian.PutUint16(b[2:], dst)
+	return b
+}
+
+func TestCompileFilter(t *testing.T) {
+	icmp := ipv4Packet(ipProtoICMP, "192.168.0.1", "10.0.0.1", 5, 0, make([]byte, 8))
+	tcp4 := ipv4Packet(ipProtoTCP, "192.168.0.1", "10.0.0.1", 5, 0, l4Ports(40000, 22))
+	tcp4Opts := ipv4Packet(ipProtoTCP, "192.168.0.1", "10.0.0.1", 6, 0, l4Ports(40000, 22))
+	tcp4Frag := ipv4Packet(ipProtoTCP, "192.168.0.1", "10.0.0.1", 5, 10, l4Ports(40000, 22))
+	udp6 := ipv6Packet(ipProtoUDP, "2001:db8::1", "2001:db8:1::2", l4Ports(53, 50000))
+	icmp6 := ipv6Packet(ipProtoICMPv6, "2001:db8::1", "2001:db8:1::2", make([]byte, 8))
+	arp := ethFrame(etherTypeARP, make([]byte, 28))
+
+	tests := []struct {
+		expr    string
+		matches [][]byte
+		misses  [][]byte
+	}{
+		{
+			expr:    "icmp",
+			matches: [][]byte{icmp},
+			misses:  [][]byte{tcp4, icmp6, arp},
+		},
+		{
+			expr:    "ip6",
+			matches: [][]byte{udp6, icmp6},
+			misses:  [][]byte{icmp, arp},
+		},
+		{
+			expr:    "not arp",
+			matches: [][]byte{icmp, udp6},
+			misses:  [][]byte{arp},
+		},
+		{
+			expr:    "tcp and port 22",
+			matches: [][]byte{tcp4, tcp4Opts},
+			misses:  [][]byte{tcp4Frag, udp6, icmp},
+		},
+		{
+			expr: "port 53",
+			matches: [][]byte{
+				udp6,
+				ipv4Packet(ipProtoUDP, "10.0.0.1", "10.0.0.2", 5, 0, l4Ports(1000, 53)),
+			},
+			misses: [][]byte{tcp4, ipv6Packet(ipProtoICMPv6, "2001:db8::1", "2001:db8:1::2", l4Ports(53, 53))},
+		},
+		{
+			expr:    "host 10.0.0.1",
+			matches: [][]byte{icmp, tcp4, ipv4Packet(ipProtoICMP, "10.0.0.1", "10.0.0.3", 5, 0, nil)},
+			misses:  [][]byte{udp6, arp, ipv4Packet(ipProtoICMP, "10.0.0.2", "10.0.0.3", 5, 0, nil)},
+		},
+		{
+			expr:    "host 2001:db8:1::2",
+			matches: [][]byte{udp6, icmp6},
+			misses:  [][]byte{icmp, ipv6Packet(ipProtoUDP, "2001:db8::1", "2001:db8:1::3", nil)},
+		},
+		{
+			expr:    "ip and not host 10.0.0.1 and not icmp",
+			matches: [][]byte{ipv4Packet(ipProtoUDP, "10.0.0.2", "10.0.0.3", 5, 0, l4Ports(1, 2))},
+			misses:  [][]byte{icmp, tcp4, udp6, ipv4Packet(ipProtoICMP, "10.0.0.2", "10.0.0.3", 5, 0, nil)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			insns, err := compileFilter(tt.expr, DefaultSnapLen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vm, err := bpf.NewVM(insns)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, pkt := range tt.matches {
+				if n, err := vm.Run(pkt); err != nil || n == 0 {
+					t.Errorf("expected packet %d to match, got %d, %v", i, n, err)
+				}
+			}
+			for i, pkt := range tt.misses {
+				if n, err := vm.Run(pkt); err != nil || n != 0 {
+					t.Errorf("expected packet %d not to match, got %d, %v", i, n, err)
+				}
+			}
+
+			if _, err := CompileFilter(tt.expr, DefaultSnapLen); err != nil {
+				t.Errorf("failed to compile filter: %v", err)
+			}
+		})
+	}
+}
+
+func TestCompileFilterSnapLen(t *testing.T) {
+	insns, err := compileFilter("tcp", 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret, ok := insns[len(insns)-1].(bpf.RetConstant); !ok || ret.Val != 64 {
+		t.Errorf("expected the filter to accept 64 bytes, got %v", insns[len(insns)-1])
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"vlan",
+		"host",
+		"host 10.0.0.256",
+		"port 65536",
+		"port",
+		"arp or icmp",
+		"!arp",
+		"(icmp)",
+		"src host 10.0.0.1",
+		"net 10.0.0.0/8",
+		"tcp port 22",
+		"ether proto 0x806",
+		"icmp and",
+		"not",
+	} {
+		if _, err := CompileFilter(expr, DefaultSnapLen); err == nil {
+			t.Errorf("expected error for filter %q", expr)
+		}
+	}
+}
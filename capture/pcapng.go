@@ -0,0 +1,109 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package capture
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// pcapng block types and constants.
+// see https://www.ietf.org/archive/id/draft-tuexen-opsawg-pcapng-05.html
+const (
+	pcapngSectionHeaderBlock    uint32 = 0x0A0D0D0A
+	pcapngInterfaceDescBlock    uint32 = 0x00000001
+	pcapngEnhancedPacketBlock   uint32 = 0x00000006
+	pcapngByteOrderMagic        uint32 = 0x1A2B3C4D
+	pcapngOptEndOfOpt           uint16 = 0
+	pcapngOptIfName             uint16 = 2
+	pcapngLinkTypeEthernet      uint16 = 1
+	pcapngSectionLengthUnknown  uint64 = 0xFFFFFFFFFFFFFFFF // -1
+	pcapngBlockHeaderTrailerLen        = 12
+)
+
+// PcapngWriter writes captured packets of a single interface in the pcapng format.
+type PcapngWriter struct {
+	w io.Writer
+}
+
+// NewPcapngWriter writes the section header and the interface description blocks
+// for the ethernet interface ifName to w and returns the writer for the packets captured on it.
+func NewPcapngWriter(w io.Writer, ifName string, snapLen int) (*PcapngWriter, error) {
+	pw := &PcapngWriter{w: w}
+
+	// section header block body: byte-order magic, version 1.0 and unspecified section length
+	shb := make([]byte, 16)
+	binary.LittleEndian.PutUint32(shb[0:], pcapngByteOrderMagic)
+	binary.LittleEndian.PutUint16(shb[4:], 1)
+	binary.LittleEndian.PutUint16(shb[6:], 0)
+	binary.LittleEndian.PutUint64(shb[8:], pcapngSectionLengthUnknown)
+
+	if err := pw.writeBlock(pcapngSectionHeaderBlock, shb); err != nil {
+		return nil, err
+	}
+
+	// interface description block body: link type, reserved, snap length and if_name option
+	idb := make([]byte, 8)
+	binary.LittleEndian.PutUint16(idb[0:], pcapngLinkTypeEthernet)
+	binary.LittleEndian.PutUint32(idb[4:], uint32(snapLen))
+	idb = appendOption(idb, pcapngOptIfName, []byte(ifName))
+	idb = appendOption(idb, pcapngOptEndOfOpt, nil)
+
+	if err := pw.writeBlock(pcapngInterfaceDescBlock, idb); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// WritePacket writes the packet data captured at ts as an enhanced packet block.
+// origLen is the length of the packet on the wire, which is greater than the length of data
+// for the packets truncated to the snap length.
+func (pw *PcapngWriter) WritePacket(ts time.Time, data []byte, origLen int) error {
+	// timestamps are in microseconds, which is the default interface time resolution
+	usec := uint64(ts.UnixNano() / int64(time.Microsecond))
+
+	epb := make([]byte, 20, 20+pad4(len(data)))
+	binary.LittleEndian.PutUint32(epb[0:], 0) // interface id
+	binary.LittleEndian.PutUint32(epb[4:], uint32(usec>>32))
+	binary.LittleEndian.PutUint32(epb[8:], uint32(usec))
+	binary.LittleEndian.PutUint32(epb[12:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(epb[16:], uint32(origLen))
+	epb = append(epb, data...)
+	epb = append(epb, make([]byte, pad4(len(data))-len(data))...)
+
+	return pw.writeBlock(pcapngEnhancedPacketBlock, epb)
+}
+
+// writeBlock writes the block of type t with the body padded to 32 bits.
+func (pw *PcapngWriter) writeBlock(t uint32, body []byte) error {
+	l := uint32(pcapngBlockHeaderTrailerLen + len(body))
+
+	b := make([]byte, l)
+	binary.LittleEndian.PutUint32(b[0:], t)
+	binary.LittleEndian.PutUint32(b[4:], l)
+	copy(b[8:], body)
+	binary.LittleEndian.PutUint32(b[l-4:], l)
+
+	// a block is written at once to not leave partial blocks in the output
+	_, err := pw.w.Write(b)
+	return err
+}
+
+// appendOption appends the option with the code c and value v padded to 32 bits to b.
+func appendOption(b []byte, c uint16, v []byte) []byte {
+	opt := make([]byte, 4+pad4(len(v)))
+	binary.LittleEndian.PutUint16(opt[0:], c)
+	binary.LittleEndian.PutUint16(opt[2:], uint16(len(v)))
+	copy(opt[4:], v)
+
+	return append(b, opt...)
+}
+
+// pad4 returns n rounded up to the multiple of 4.
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
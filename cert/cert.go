@@ -6,6 +6,9 @@ package cert
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -16,6 +19,7 @@ import (
 	"github.com/cloudflare/cfssl/cli/genkey"
 	"github.com/cloudflare/cfssl/config"
 	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudflare/cfssl/initca"
 	"github.com/cloudflare/cfssl/signer"
 	"github.com/cloudflare/cfssl/signer/universal"
@@ -40,6 +44,9 @@ type CertInput struct {
 	Organization     string
 	OrganizationUnit string
 	Expiry           string
+	// KeyAlgorithm and KeySize of the certificate key, rsa 2048 by default
+	KeyAlgorithm string
+	KeySize      int
 
 	Name     string
 	LongName string
@@ -61,37 +68,40 @@ type CaRootInput struct {
 	Names  map[string]string // Not used right now
 	// prefix for certificate/key file name
 	NamePrefix string
+	// KeyAlgorithm and KeySize of the CA key, rsa 2048 by default
+	KeyAlgorithm string
+	KeySize      int
 }
 
 var rootCACSRTempl string = `{
-    "CN": "{{.Prefix}} Root CA",
+    "CN": "{{.CommonName}}",
     "key": {
-       "algo": "rsa",
-       "size": 2048
+       "algo": "{{.KeyAlgorithm}}",
+       "size": {{.KeySize}}
     },
     "names": [{
-       "C": "BE",
-       "L": "Antwerp",
-       "O": "Nokia",
-       "OU": "Container lab"
+       "C": "{{.Country}}",
+       "L": "{{.Locality}}",
+       "O": "{{.Organization}}",
+       "OU": "{{.OrganizationUnit}}"
     }],
     "ca": {
-       "expiry": "262800h"
+       "expiry": "{{.Expiry}}"
     }
 }
 `
 
 var NodeCSRTempl string = `{
-    "CN": "{{.Name}}.{{.Prefix}}.io",
+    "CN": "{{.CommonName}}",
     "key": {
-      "algo": "rsa",
-      "size": 2048
+      "algo": "{{.KeyAlgorithm}}",
+      "size": {{.KeySize}}
     },
     "names": [{
-      "C": "BE",
-      "L": "Antwerp",
-      "O": "Nokia",
-      "OU": "Container lab"
+      "C": "{{.Country}}",
+      "L": "{{.Locality}}",
+      "O": "{{.Organization}}",
+      "OU": "{{.OrganizationUnit}}"
     }],
     "hosts": [
       "{{.Name}}",
@@ -107,6 +117,9 @@ func GenerateRootCa(labCARoot string, csrRootJsonTpl *template.Template, input C
 	log.Debug("Creating root CA")
 	// create root CA root directory
 	utils.CreateDirectory(labCARoot, 0755)
+	if err := setKeyDefaults(&input.KeyAlgorithm, &input.KeySize); err != nil {
+		return nil, err
+	}
 	var err error
 	csrBuff := new(bytes.Buffer)
 	err = csrRootJsonTpl.Execute(csrBuff, input)
@@ -122,7 +135,12 @@ func GenerateRootCa(labCARoot string, csrRootJsonTpl *template.Template, input C
 	}
 
 	var key, csrPEM, cert []byte
-	cert, csrPEM, key, err = initca.New(&req)
+	// cfssl doesn't generate ed25519 keys
+	if req.KeyRequest.Algo() == types.KeyAlgorithmEd25519 {
+		cert, csrPEM, key, err = newEd25519CA(&req)
+	} else {
+		cert, csrPEM, key, err = initca.New(&req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +157,9 @@ func GenerateRootCa(labCARoot string, csrRootJsonTpl *template.Template, input C
 // CA used to sign the cert is passed as ca and caKey file paths.
 func GenerateCert(ca, caKey string, csrJSONTpl *template.Template, input CertInput, targetPath string) (*Certificates, error) {
 	utils.CreateDirectory(targetPath, 0755)
+	if err := setKeyDefaults(&input.KeyAlgorithm, &input.KeySize); err != nil {
+		return nil, err
+	}
 	var err error
 	csrBuff := new(bytes.Buffer)
 	err = csrJSONTpl.Execute(csrBuff, input)
@@ -155,8 +176,13 @@ func GenerateCert(ca, caKey string, csrJSONTpl *template.Template, input CertInp
 	}
 
 	var key, csrBytes []byte
-	gen := &csr.Generator{Validator: genkey.Validator}
-	csrBytes, key, err = gen.ProcessRequest(req)
+	isEd25519 := req.KeyRequest.Algo() == types.KeyAlgorithmEd25519
+	if isEd25519 {
+		csrBytes, key, _, err = newEd25519CSR(req)
+	} else {
+		gen := &csr.Generator{Validator: genkey.Validator}
+		csrBytes, key, err = gen.ProcessRequest(req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -165,27 +191,48 @@ func GenerateCert(ca, caKey string, csrJSONTpl *template.Template, input CertInp
 		Profiles: map[string]*config.SigningProfile{},
 		Default:  config.DefaultConfig(),
 	}
-	root := universal.Root{
-		Config: map[string]string{
-			"cert-file": ca,
-			"key-file":  caKey,
-		},
-		ForceRemote: false,
-	}
-	s, err := universal.NewSigner(root, policy)
+
+	caKeyPEM, err := os.ReadFile(caKey)
 	if err != nil {
 		return nil, err
 	}
+	caSigner, err := helpers.ParsePrivateKeyPEM(caKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key %s: %v", caKey, err)
+	}
+	if _, ok := caSigner.Public().(ed25519.PublicKey); ok {
+		isEd25519 = true
+	}
 
 	var cert []byte
-	signReq := signer.SignRequest{
-		Request: string(csrBytes),
-	}
-	cert, err = s.Sign(signReq)
-	if err != nil {
-		return nil, err
+	if isEd25519 {
+		// cfssl signers don't support ed25519 keys, so the certificates with ed25519 keys
+		// and the ones signed by an ed25519 CA are signed by the standard library
+		cert, err = signEd25519(ca, caSigner, csrBytes, policy.Default.Expiry)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		root := universal.Root{
+			Config: map[string]string{
+				"cert-file": ca,
+				"key-file":  caKey,
+			},
+			ForceRemote: false,
+		}
+		s, err := universal.NewSigner(root, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err = s.Sign(signer.SignRequest{
+			Request: string(csrBytes),
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
-	if len(signReq.Hosts) == 0 && len(req.Hosts) == 0 {
+	if len(req.Hosts) == 0 {
 		log.Warning(generator.CSRNoHostMessage)
 	}
 	certs := &Certificates{
@@ -227,19 +274,132 @@ func RetrieveNodeCertData(n *types.NodeConfig, labCADir string) (*Certificates,
 	return certs, nil
 }
 
+// NodeCertificate returns the node private key and certificate kept in the lab CA directory.
+// When they don't exist, they are generated and signed by the lab root CA.
+func NodeCertificate(n *types.NodeConfig, configName, labCADir, labCARoot string) (*Certificates, error) {
+	certs, err := RetrieveNodeCertData(n, labCADir)
+	if err == nil && certs != nil {
+		return certs, nil
+	}
+
+	return RenewNodeCertificate(n, configName, labCADir, labCARoot)
+}
+
+// RenewNodeCertificate generates a new node private key and certificate signed by the lab root CA,
+// replacing the ones kept in the lab CA directory.
+// The certificate of the root CA is written along with them as ca.pem.
+func RenewNodeCertificate(n *types.NodeConfig, configName, labCADir, labCARoot string) (*Certificates, error) {
+	certTpl, err := template.New("node-cert").Parse(NodeCSRTempl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Node CSR Template: %v", err)
+	}
+	log.Debugf("creating node certificate for %s with SANs %s", n.ShortName, n.SANs)
+
+	subj := labSubject(n.Certificate.GetSubject(), n.ShortName+"."+configName+".io")
+	certInput := CertInput{
+		CommonName:       subj.CommonName,
+		Country:          subj.Country,
+		Locality:         subj.Locality,
+		Organization:     subj.Organization,
+		OrganizationUnit: subj.OrganizationUnit,
+		KeyAlgorithm:     n.Certificate.GetKeyAlgorithm(),
+		KeySize:          n.Certificate.GetKeySize(),
+
+		Name:     n.ShortName,
+		LongName: n.LongName,
+		Fqdn:     n.Fqdn,
+		SANs:     n.SANs,
+		Prefix:   configName,
+	}
+	nodeCertFilesDir := filepath.Join(labCADir, certInput.Name)
+	certs, err := GenerateCert(
+		filepath.Join(labCARoot, "root-ca.pem"),
+		filepath.Join(labCARoot, "root-ca-key.pem"),
+		certTpl,
+		certInput,
+		nodeCertFilesDir,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate certificates for node %s: %v", n.ShortName, err)
+	}
+
+	if err := utils.CopyFile(filepath.Join(labCARoot, "root-ca.pem"),
+		filepath.Join(nodeCertFilesDir, "ca.pem"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to copy the root CA certificate for node %s: %v", n.ShortName, err)
+	}
+
+	log.Debugf("%s CSR: %s", n.ShortName, string(certs.Csr))
+	log.Debugf("%s Cert: %s", n.ShortName, string(certs.Cert))
+	log.Debugf("%s Key: %s", n.ShortName, string(certs.Key))
+
+	return certs, nil
+}
+
+// setKeyDefaults sets the default algorithm and size of the key to generate when they are not set,
+// and checks that they are valid.
+func setKeyDefaults(algo *string, size *int) error {
+	if *algo == "" {
+		*algo = types.KeyAlgorithmRSA
+	}
+	if *size == 0 {
+		*size = types.DefaultKeySize(*algo)
+	}
+	return types.ValidateKey(*algo, *size)
+}
+
+// labSubject returns the subject of the lab root CA and node certificates
+// with the fields not set in s taken from the default subject of the lab certificates.
+func labSubject(s *types.CertificateSubject, commonName string) types.CertificateSubject {
+	subj := types.CertificateSubject{
+		CommonName:       commonName,
+		Country:          "BE",
+		Locality:         "Antwerp",
+		Organization:     "Nokia",
+		OrganizationUnit: "Container lab",
+	}
+	if s == nil {
+		return subj
+	}
+
+	if s.CommonName != "" {
+		subj.CommonName = s.CommonName
+	}
+	if s.Country != "" {
+		subj.Country = s.Country
+	}
+	if s.Locality != "" {
+		subj.Locality = s.Locality
+	}
+	if s.Organization != "" {
+		subj.Organization = s.Organization
+	}
+	if s.OrganizationUnit != "" {
+		subj.OrganizationUnit = s.OrganizationUnit
+	}
+
+	return subj
+}
+
 func writeCertFiles(certs *Certificates, filesPrefix string) {
 	utils.CreateFile(filesPrefix+".pem", string(certs.Cert))
 	utils.CreateFile(filesPrefix+"-key.pem", string(certs.Key))
 	utils.CreateFile(filesPrefix+".csr", string(certs.Csr))
 }
 
+// NeedsCertificate returns true when a certificate is issued to the node by the lab root CA,
+// which is the case for srl nodes and the nodes with the certificate issuing enabled.
+func NeedsCertificate(n *types.NodeConfig) bool {
+	return n.Kind == "srl" || n.Certificate.IsIssued()
+}
+
 // CreateRootCA creates RootCA key/certificate if it is needed by the topology.
-func CreateRootCA(configName, labCARoot string, ns map[string]nodes.Node) error {
-	rootCANeeded := false
-	// check if srl kinds defined in topo
-	// for them we need to create rootCA and certs
+// The certificate and key of the CA are imported when ca sets them,
+// otherwise they are generated with the key algorithm, size and expiry of ca.
+func CreateRootCA(configName, labCARoot string, ca *types.CertificateAuthority, ns map[string]nodes.Node) error {
+	// the CA is created when it is configured or the nodes need certificates
+	rootCANeeded := ca != nil
 	for _, n := range ns {
-		if n.Config().Kind == "srl" {
+		if NeedsCertificate(n.Config()) {
 			rootCANeeded = true
 			break
 		}
@@ -249,25 +409,15 @@ func CreateRootCA(configName, labCARoot string, ns map[string]nodes.Node) error
 		return nil
 	}
 
+	if ca.IsImported() {
+		return importRootCA(labCARoot, ca)
+	}
+
 	rootCaCertPath := filepath.Join(labCARoot, "root-ca.pem")
 	rootCaKeyPath := filepath.Join(labCARoot, "root-ca-key.pem")
 
-	rootCaCertExists := false
-	rootCaKeyExists := false
-
-	_, err := os.Stat(rootCaCertPath)
-	if err == nil {
-		rootCaCertExists = true
-	}
-	_, err = os.Stat(rootCaKeyPath)
-	if err == nil {
-		rootCaKeyExists = true
-	}
 	// if both files exist skip root CA creation
-	if rootCaCertExists && rootCaKeyExists {
-		rootCANeeded = false
-	}
-	if !rootCANeeded {
+	if utils.FileExists(rootCaCertPath) && utils.FileExists(rootCaKeyPath) {
 		return nil
 	}
 
@@ -275,9 +425,19 @@ func CreateRootCA(configName, labCARoot string, ns map[string]nodes.Node) error
 	if err != nil {
 		return fmt.Errorf("failed to parse Root CA CSR Template: %v", err)
 	}
+	subj := labSubject(ca.GetSubject(), configName+" Root CA")
 	rootCerts, err := GenerateRootCa(labCARoot, tpl, CaRootInput{
-		Prefix:     configName,
-		NamePrefix: "root-ca",
+		CommonName:       subj.CommonName,
+		Country:          subj.Country,
+		Locality:         subj.Locality,
+		Organization:     subj.Organization,
+		OrganizationUnit: subj.OrganizationUnit,
+
+		Prefix:       configName,
+		NamePrefix:   "root-ca",
+		KeyAlgorithm: ca.GetKeyAlgorithm(),
+		KeySize:      ca.GetKeySize(),
+		Expiry:       ca.GetExpiry(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to generate rootCa: %v", err)
@@ -288,3 +448,30 @@ func CreateRootCA(configName, labCARoot string, ns map[string]nodes.Node) error
 	log.Debugf("root Key: %s", string(rootCerts.Key))
 	return nil
 }
+
+// importRootCA copies the certificate and key of an existing CA to the lab root CA directory.
+func importRootCA(labCARoot string, ca *types.CertificateAuthority) error {
+	pair, err := tls.LoadX509KeyPair(ca.Cert, ca.Key)
+	if err != nil {
+		return fmt.Errorf("failed to load CA certificate %s and key %s: %v", ca.Cert, ca.Key, err)
+	}
+	caCert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse CA certificate %s: %v", ca.Cert, err)
+	}
+	if !caCert.IsCA {
+		return fmt.Errorf("certificate %s is not a CA certificate", ca.Cert)
+	}
+
+	log.Debugf("importing root CA %q", caCert.Subject)
+	utils.CreateDirectory(labCARoot, 0755)
+
+	if err := utils.CopyFile(ca.Cert, filepath.Join(labCARoot, "root-ca.pem"), 0644); err != nil {
+		return fmt.Errorf("failed to import CA certificate %s: %v", ca.Cert, err)
+	}
+	if err := utils.CopyFile(ca.Key, filepath.Join(labCARoot, "root-ca-key.pem"), 0600); err != nil {
+		return fmt.Errorf("failed to import CA key %s: %v", ca.Key, err)
+	}
+
+	return nil
+}
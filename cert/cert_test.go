@@ -0,0 +1,133 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestEd25519CASignsLeafCertificates(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := GenerateRootCa(dir, template.Must(template.New("ca").Parse(rootCACSRTempl)), CaRootInput{
+		CommonName:   "test lab root ca",
+		Country:      "BE",
+		Organization: "Nokia",
+		NamePrefix:   "root-ca",
+		KeyAlgorithm: types.KeyAlgorithmEd25519,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert := readCertificate(t, filepath.Join(dir, "root-ca.pem"))
+	if _, ok := caCert.PublicKey.(ed25519.PublicKey); !ok {
+		t.Fatalf("expected ed25519 CA key, got %T", caCert.PublicKey)
+	}
+	if !caCert.IsCA || caCert.KeyUsage != x509.KeyUsageCertSign|x509.KeyUsageCRLSign {
+		t.Errorf("expected CA certificate with cert and CRL signing key usage, got CA %v, key usage %v",
+			caCert.IsCA, caCert.KeyUsage)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	tests := map[string]struct {
+		keyUsage x509.KeyUsage
+		checkKey func(interface{}) bool
+	}{
+		types.KeyAlgorithmEd25519: {
+			keyUsage: x509.KeyUsageDigitalSignature,
+			checkKey: func(k interface{}) bool { _, ok := k.(ed25519.PublicKey); return ok },
+		},
+		types.KeyAlgorithmECDSA: {
+			keyUsage: x509.KeyUsageDigitalSignature,
+			checkKey: func(k interface{}) bool { _, ok := k.(*ecdsa.PublicKey); return ok },
+		},
+		types.KeyAlgorithmRSA: {
+			keyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+			checkKey: func(k interface{}) bool { _, ok := k.(*rsa.PublicKey); return ok },
+		},
+	}
+
+	for algo, tt := range tests {
+		t.Run(algo, func(t *testing.T) {
+			targetPath := filepath.Join(dir, algo)
+			_, err := GenerateCert(filepath.Join(dir, "root-ca.pem"), filepath.Join(dir, "root-ca-key.pem"),
+				template.Must(template.New("node").Parse(NodeCSRTempl)), CertInput{
+					CommonName:   "srl1.test.io",
+					Name:         "srl1",
+					LongName:     "clab-test-srl1",
+					Fqdn:         "srl1.test.io",
+					SANs:         []string{"192.168.0.1"},
+					KeyAlgorithm: algo,
+				}, targetPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			leaf := readCertificate(t, filepath.Join(targetPath, "srl1.pem"))
+			if !tt.checkKey(leaf.PublicKey) {
+				t.Errorf("expected %s key, got %T", algo, leaf.PublicKey)
+			}
+
+			for _, name := range []string{"srl1", "clab-test-srl1", "srl1.test.io", "192.168.0.1"} {
+				_, err := leaf.Verify(x509.VerifyOptions{
+					DNSName:   name,
+					Roots:     roots,
+					KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+				})
+				if err != nil {
+					t.Errorf("failed to verify certificate for %s: %v", name, err)
+				}
+			}
+
+			if d := cmp.Diff([]string{"srl1", "clab-test-srl1", "srl1.test.io"}, leaf.DNSNames); d != "" {
+				t.Errorf("DNS SANs mismatch (-want +got):\n%s", d)
+			}
+			if len(leaf.IPAddresses) != 1 || !leaf.IPAddresses[0].Equal(net.ParseIP("192.168.0.1")) {
+				t.Errorf("expected IP SAN 192.168.0.1, got %v", leaf.IPAddresses)
+			}
+
+			if leaf.IsCA {
+				t.Error("expected leaf certificate not to be a CA")
+			}
+			if leaf.KeyUsage != tt.keyUsage {
+				t.Errorf("expected key usage %v, got %v", tt.keyUsage, leaf.KeyUsage)
+			}
+			wantExtKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+			if d := cmp.Diff(wantExtKeyUsage, leaf.ExtKeyUsage); d != "" {
+				t.Errorf("extended key usage mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func readCertificate(t *testing.T, path string) *x509.Certificate {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := helpers.ParseCertificatePEM(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
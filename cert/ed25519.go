@@ -0,0 +1,155 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cert
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
+)
+
+// cfssl can't generate ed25519 keys nor sign with them,
+// so the certificates involving ed25519 keys are generated and signed with the standard library.
+
+// newEd25519CSR generates an ed25519 key and the CSR of req signed with it.
+// The CSR and the key are returned PEM encoded along with the key itself.
+func newEd25519CSR(req *csr.CertificateRequest) (csrPEM, keyPEM []byte, key ed25519.PrivateKey, err error) {
+	_, key, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate ed25519 key: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	subject, err := req.Name()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tpl := &x509.CertificateRequest{Subject: subject}
+	for _, h := range req.Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		} else {
+			tpl.DNSNames = append(tpl.DNSNames, h)
+		}
+	}
+
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, tpl, key)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create CSR: %v", err)
+	}
+
+	csrPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return csrPEM, keyPEM, key, nil
+}
+
+// newEd25519CA generates a self-signed CA certificate of req with an ed25519 key.
+func newEd25519CA(req *csr.CertificateRequest) (cert, csrPEM, keyPEM []byte, err error) {
+	expiry := 5 * helpers.OneYear
+	if req.CA != nil && req.CA.Expiry != "" {
+		expiry, err = time.ParseDuration(req.CA.Expiry)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	csrPEM, keyPEM, key, err := newEd25519CSR(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cert, err = issueCertificate(csrPEM, nil, key, expiry, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return cert, csrPEM, keyPEM, nil
+}
+
+// signEd25519 signs the PEM encoded CSR with the CA certificate stored in caCertFile and its key caKey.
+func signEd25519(caCertFile string, caKey crypto.Signer, csrPEM []byte, expiry time.Duration) ([]byte, error) {
+	caCertPEM, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := helpers.ParseCertificatePEM(caCertPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate %s: %v", caCertFile, err)
+	}
+
+	return issueCertificate(csrPEM, caCert, caKey, expiry, false)
+}
+
+// issueCertificate issues the PEM encoded certificate of the PEM encoded CSR signed by the parent certificate
+// and its key. The certificate is self-signed when parent is nil.
+// CA certificates are issued for certificate signing, the others for TLS servers and clients.
+func issueCertificate(csrPEM []byte, parent *x509.Certificate, key crypto.Signer, expiry time.Duration,
+	isCA bool,
+) ([]byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded CSR found")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSR: %v", err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	// the certificate is backdated to tolerate the clock skew, like cfssl does
+	now := time.Now().Round(time.Minute)
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               req.Subject,
+		DNSNames:              req.DNSNames,
+		IPAddresses:           req.IPAddresses,
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(expiry),
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		tpl.IsCA = true
+		tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	} else {
+		tpl.KeyUsage = x509.KeyUsageDigitalSignature
+		// only RSA keys are used for the key encipherment in the TLS key exchange
+		if _, ok := req.PublicKey.(*rsa.PublicKey); ok {
+			tpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+		tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	if parent == nil {
+		parent = tpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parent, req.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
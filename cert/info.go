@@ -0,0 +1,77 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+// Info is the summary of a certificate.
+type Info struct {
+	Subject string
+	Issuer  string
+	// SANs are the DNS names and the IP addresses of the certificate
+	SANs      []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// Key is the algorithm and the size of the public key, e.g. RSA 2048
+	Key  string
+	IsCA bool
+}
+
+// ParseInfo returns the summary of the first certificate found in the PEM encoded data.
+func ParseInfo(data []byte) (*Info, error) {
+	var block *pem.Block
+	for {
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no PEM encoded certificate found")
+		}
+		if block.Type == "CERTIFICATE" {
+			break
+		}
+	}
+
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+
+	sans := make([]string, 0, len(c.DNSNames)+len(c.IPAddresses))
+	sans = append(sans, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return &Info{
+		Subject:   c.Subject.String(),
+		Issuer:    c.Issuer.String(),
+		SANs:      sans,
+		NotBefore: c.NotBefore,
+		NotAfter:  c.NotAfter,
+		Key:       publicKeyDescription(c.PublicKey),
+		IsCA:      c.IsCA,
+	}, nil
+}
+
+// publicKeyDescription returns the algorithm and the size of the public key.
+func publicKeyDescription(k crypto.PublicKey) string {
+	switch k := k.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + k.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return fmt.Sprintf("%T", k)
+}
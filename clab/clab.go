@@ -14,6 +14,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/cert"
 	"github.com/srl-labs/containerlab/nodes"
 	allNodes "github.com/srl-labs/containerlab/nodes/all"
 	"github.com/srl-labs/containerlab/runtime"
@@ -36,6 +37,19 @@ type CLab struct {
 	Dir           *Directory `json:"dir,omitempty"`
 
 	timeout time.Duration
+	// state of a deployed lab, when set the lab is loaded from it instead of the topology file
+	state *LabState
+	// host of a distributed lab the lab is deployed on, all nodes are deployed when it is not set
+	host string
+	// ipamAllocations are the subnets allocated from the IPAM pools, keyed by the pool name and the link or node key
+	ipamAllocations map[string]map[string]string
+	// skipPostDeploy skips the post-deploy actions of the nodes
+	skipPostDeploy bool
+	// postDeployed are the nodes which ran their post-deploy actions right after their creation,
+	// since other nodes wait for them to finish these actions
+	postDeployed map[string]struct{}
+	// rootless is set when the lab is deployed with a rootless runtime
+	rootless bool
 }
 
 type Directory struct {
@@ -65,6 +79,9 @@ func WithRuntime(name string, rtconfig *runtime.RuntimeConfig) ClabOption {
 		log.Debugf("env runtime var value is %v", envN)
 		switch {
 		case name != "":
+		// runtime of a lab loaded from the lab state
+		case c.globalRuntime != "":
+			name = c.globalRuntime
 		case envN != "":
 			name = envN
 		default:
@@ -98,6 +115,15 @@ func WithKeepMgmtNet() ClabOption {
 	}
 }
 
+// WithSkipPostDeploy skips the post-deploy actions of the nodes,
+// the nodes waiting for the post-deploy stage of other nodes only wait for their creation.
+func WithSkipPostDeploy(skip bool) ClabOption {
+	return func(c *CLab) error {
+		c.skipPostDeploy = skip
+		return nil
+	}
+}
+
 func WithTopoFile(file, varsFile string) ClabOption {
 	return func(c *CLab) error {
 		if file == "" {
@@ -135,8 +161,19 @@ func NewContainerLab(opts ...ClabOption) (*CLab, error) {
 		}
 	}
 
+	// by default external access is enabled if not set by a user,
+	// the labs deployed with a rootless runtime opt in to it, since the rules are programmed in the runtime netns.
+	// It is set once all the options are applied, so that it doesn't depend on the order of the options.
+	if c.Config.Mgmt.ExternalAccess == nil {
+		c.Config.Mgmt.ExternalAccess = new(bool)
+		*c.Config.Mgmt.ExternalAccess = !c.rootless
+	}
+
 	var err error
-	if c.TopoFile.path != "" {
+	switch {
+	case c.state != nil:
+		err = c.loadLabState()
+	case c.TopoFile.path != "":
 		err = c.parseTopology()
 	}
 
@@ -155,24 +192,37 @@ func (c *CLab) initMgmtNetwork() error {
 		c.Config.Mgmt.IPv6Subnet = dockerNetIPv6Addr
 	}
 
-	// by default external access is enabled if not set by a user
-	if c.Config.Mgmt.ExternalAccess == nil {
-		c.Config.Mgmt.ExternalAccess = new(bool)
-		*c.Config.Mgmt.ExternalAccess = true
-	}
-
 	log.Debugf("New mgmt params are %+v", c.Config.Mgmt)
 
 	return nil
 }
 
+// updateRuntimeInfo updates the node config with the runtime information under the lab lock,
+// since the configs of the nodes are read by the other deployment workers.
+func (c *CLab) updateRuntimeInfo(ctx context.Context, node nodes.Node) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return node.UpdateConfigWithRuntimeInfo(ctx)
+}
+
 func (c *CLab) GlobalRuntime() runtime.ContainerRuntime {
 	return c.Runtimes[c.globalRuntime]
 }
 
 // CreateNodes schedules nodes creation and returns a waitgroup for all nodes.
 // Nodes interdependencies are created in this function.
-func (c *CLab) CreateNodes(ctx context.Context, maxWorkers uint) (*sync.WaitGroup, error) {
+func (c *CLab) CreateNodes(ctx context.Context, maxWorkers uint, res *DeployResult) (*sync.WaitGroup, error) {
+	return c.createNodes(ctx, maxWorkers, c.Nodes, res)
+}
+
+// createNodes schedules the creation of scheduledNodes, which is a subset of the lab nodes.
+// Dependencies are resolved against all lab nodes, with the nodes which are not scheduled
+// considered to be already created.
+// The errors of the nodes failed to be created are collected in res once the returned wait group is done.
+func (c *CLab) createNodes(ctx context.Context, maxWorkers uint,
+	scheduledNodes map[string]nodes.Node, res *DeployResult,
+) (*sync.WaitGroup, error) {
 	dm := NewDependencyManager()
 
 	for nodeName := range c.Nodes {
@@ -208,8 +258,17 @@ func (c *CLab) CreateNodes(ctx context.Context, maxWorkers uint) (*sync.WaitGrou
 		return nil, err
 	}
 
+	// nodes that are not scheduled for creation do not hold back their dependers at any stage
+	for nodeName := range c.Nodes {
+		if _, ok := scheduledNodes[nodeName]; !ok {
+			for _, stage := range types.WaitForStages {
+				dm.SignalDone(nodeName, stage)
+			}
+		}
+	}
+
 	// start scheduling
-	NodesWg := c.scheduleNodes(ctx, int(maxWorkers), c.Nodes, dm)
+	NodesWg := c.scheduleNodes(ctx, int(maxWorkers), scheduledNodes, dm, res)
 
 	return NodesWg, nil
 }
@@ -290,11 +349,12 @@ func createStaticDynamicDependency(n map[string]nodes.Node, dm DependencyManager
 }
 
 // createWaitForDependency reflects the dependencies defined in the configuration via the wait-for field.
+// The creation of the waiter node depends on the stage of the node it waits for.
 func createWaitForDependency(n map[string]nodes.Node, dm DependencyManager) error {
 	for waiterNode, node := range n {
 		// add node's waitFor nodes to the dependency manager
-		for _, waitForNode := range node.Config().WaitFor {
-			err := dm.AddDependency(waitForNode, waiterNode)
+		for _, w := range node.Config().WaitFor {
+			err := dm.AddStageDependency(w.Node, w.GetStage(), waiterNode, types.WaitForStageCreate, w.GetTimeout())
 			if err != nil {
 				return err
 			}
@@ -305,10 +365,22 @@ func createWaitForDependency(n map[string]nodes.Node, dm DependencyManager) erro
 }
 
 func (c *CLab) scheduleNodes(ctx context.Context, maxWorkers int,
-	scheduledNodes map[string]nodes.Node, dm DependencyManager,
+	scheduledNodes map[string]nodes.Node, dm DependencyManager, res *DeployResult,
 ) *sync.WaitGroup {
 	concurrentChan := make(chan nodes.Node)
 
+	// failNode records the error of the node and releases its dependers, which then fail as well
+	failNode := func(node nodes.Node, stage string, err error) {
+		res.AddNodeError(node, stage, err)
+		log.Errorf("failed %s stage for node %q: %v", stage, node.Config().ShortName, err)
+
+		c.m.Lock()
+		node.Config().DeploymentStatus = deploymentStatusFailed
+		c.m.Unlock()
+
+		dm.SignalFailed(node.Config().ShortName, types.WaitForStageCreate)
+	}
+
 	workerFunc := func(i int, input chan nodes.Node, wg *sync.WaitGroup, dm DependencyManager) {
 		defer wg.Done()
 		for {
@@ -320,6 +392,12 @@ func (c *CLab) scheduleNodes(ctx context.Context, maxWorkers int,
 				}
 				log.Debugf("Worker %d received node: %+v", i, node.Config())
 
+				// the nodes are not deployed once a node failed with the abort or rollback failure policy
+				if res.Aborted() {
+					failNode(node, StageAborted, errDeployAborted)
+					continue
+				}
+
 				// Apply any startup delay
 				delay := node.Config().StartupDelay
 				if delay > 0 {
@@ -327,19 +405,44 @@ func (c *CLab) scheduleNodes(ctx context.Context, maxWorkers int,
 					time.Sleep(time.Duration(delay) * time.Second)
 				}
 
+				// issue the certificate of the node, which is mounted to the node
+				if node.Config().Certificate.IsIssued() {
+					_, err := cert.NodeCertificate(node.Config(), c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot)
+					if err != nil {
+						failNode(node, StageCertificate, err)
+						continue
+					}
+				}
+
+				if err := c.RunNodeHooks(ctx, node, types.HookPreDeploy); err != nil {
+					failNode(node, StagePreDeployHook, err)
+					continue
+				}
+
 				// PreDeploy
 				err := node.PreDeploy(ctx, c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot)
 				if err != nil {
-					log.Errorf("failed pre-deploy phase for node %q: %v", node.Config().ShortName, err)
+					failNode(node, StagePreDeploy, err)
 					continue
 				}
 				// Deploy
 				err = node.Deploy(ctx)
 				if err != nil {
-					log.Errorf("failed deploy phase for node %q: %v", node.Config().ShortName, err)
+					failNode(node, StageDeploy, err)
 					continue
 				}
 
+				if len(node.Config().Hooks.Get(types.HookPostCreate)) != 0 {
+					// the management addresses assigned by the runtime are exported to the hooks
+					if err := c.updateRuntimeInfo(ctx, node); err != nil {
+						log.Debugf("failed to update runtime information of node %q: %v", node.Config().ShortName, err)
+					}
+					if err := c.RunNodeHooks(ctx, node, types.HookPostCreate); err != nil {
+						failNode(node, StagePostCreate, err)
+						continue
+					}
+				}
+
 				// set deployment status of a node to created to indicate that it finished creating
 				// this status is checked during link creation to only schedule link creation if both nodes are ready
 				c.m.Lock()
@@ -348,7 +451,10 @@ func (c *CLab) scheduleNodes(ctx context.Context, maxWorkers int,
 
 				// signal to dependency manager that this node is done
 
-				dm.SignalDone(node.Config().ShortName)
+				dm.SignalDone(node.Config().ShortName, types.WaitForStageCreate)
+
+				// the later stages of the node are reached in the background, so that the worker is free
+				c.reachWaitedStages(ctx, node, dm, res, wg)
 			case <-ctx.Done():
 				return
 			}
@@ -377,24 +483,33 @@ func (c *CLab) scheduleNodes(ctx context.Context, maxWorkers int,
 		// start a func for all the containers, then will wait for their own waitgroups
 		// to be set to zero by their depending containers, then enqueue to the creation channel
 		go func(node nodes.Node, dm DependencyManager, workerChan chan<- nodes.Node, wfcwg *sync.WaitGroup) {
+			// indicate we are done, such that only when all of these functions are done, the workerChan is being closed
+			defer wfcwg.Done()
 			// wait for all the nodes that node depends on
-			err := dm.WaitForNodeDependencies(node.Config().ShortName)
+			err := dm.WaitForNodeDependencies(ctx, node.Config().ShortName, types.WaitForStageCreate)
 			if err != nil {
-				log.Error(err)
+				// the node is not created when the nodes it depends on failed
+				failNode(node, StageDependency, err)
+				return
 			}
 			// wait for possible external dependencies
 			c.WaitForExternalNodeDependencies(ctx, node.Config().ShortName)
 			// when all nodes that this node depends on are created, push it into the channel
-			workerChan <- node
-			// indicate we are done, such that only when all of these functions are done, the workerChan is being closed
-			wfcwg.Done()
+			select {
+			case workerChan <- node:
+			case <-ctx.Done():
+			}
 		}(n, dm, concurrentChan, workerFuncChWG) // execute this function straight away
 	}
 
-	// Gate to make sure the channel is not closed before all the nodes made it though the channel
-	workerFuncChWG.Wait()
-	// close the channel and thereby terminate the workerFuncs
-	close(concurrentChan)
+	// Gate to make sure the channel is not closed before all the nodes made it though the channel.
+	// The gate is waited for in the background, so that the links are created while the nodes
+	// wait for other nodes to become healthy, which for some kinds requires their links to exist.
+	go func() {
+		workerFuncChWG.Wait()
+		// close the channel and thereby terminate the workerFuncs
+		close(concurrentChan)
+	}()
 
 	return wg
 }
@@ -426,7 +541,13 @@ func (c *CLab) WaitForExternalNodeDependencies(ctx context.Context, nodeName str
 }
 
 // CreateLinks creates links using the specified number of workers.
-func (c *CLab) CreateLinks(ctx context.Context, workers uint) {
+// The errors of the links failed to be created are collected in res.
+func (c *CLab) CreateLinks(ctx context.Context, workers uint, res *DeployResult) {
+	c.createLinks(ctx, workers, c.Links, res)
+}
+
+// createLinks creates the given links once both of their nodes are created.
+func (c *CLab) createLinks(ctx context.Context, workers uint, links map[int]*types.Link, res *DeployResult) {
 	wg := new(sync.WaitGroup)
 	wg.Add(int(workers))
 	linksChan := make(chan *types.Link)
@@ -446,6 +567,16 @@ func (c *CLab) CreateLinks(ctx context.Context, workers uint) {
 					log.Debugf("Link worker %d received link: %+v", i, link)
 					if err := c.CreateVirtualWiring(link); err != nil {
 						log.Error(err)
+						res.AddLinkError(link, err)
+						continue
+					}
+					if err := SetEndpointsConfig(link); err != nil {
+						log.Error(err)
+						res.AddLinkError(link, err)
+					}
+					if err := SetLinkImpairment(link); err != nil {
+						log.Error(err)
+						res.AddLinkError(link, err)
 					}
 				case <-ctx.Done():
 					return
@@ -457,7 +588,7 @@ func (c *CLab) CreateLinks(ctx context.Context, workers uint) {
 	// create a copy of links map to loop over
 	// so that we can wait till all the nodes are ready before scheduling a link
 	linksCopy := map[int]*types.Link{}
-	for k, v := range c.Links {
+	for k, v := range links {
 		linksCopy[k] = v
 	}
 	for {
@@ -466,8 +597,17 @@ func (c *CLab) CreateLinks(ctx context.Context, workers uint) {
 		}
 		for k, link := range linksCopy {
 			c.m.Lock()
-			if link.A.Node.DeploymentStatus == "created" &&
-				link.B.Node.DeploymentStatus == "created" {
+			created, failed := true, false
+			for _, e := range link.Endpoints() {
+				created = created && e.Node.DeploymentStatus == "created"
+				failed = failed || e.Node.DeploymentStatus == deploymentStatusFailed
+			}
+			switch {
+			case failed:
+				// the links of the nodes failed to deploy are never created
+				log.Warnf("skipping creation of link %s as its node failed to deploy", link.String())
+				delete(linksCopy, k)
+			case created:
 				linksChan <- link
 				delete(linksCopy, k)
 			}
@@ -7,6 +7,7 @@ package clab
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/srl-labs/containerlab/mocks"
@@ -68,7 +69,7 @@ func getNodeMap(mockCtrl *gomock.Controller) map[string]nodes.Node {
 		&types.NodeConfig{
 			Image:     "alpine:3",
 			ShortName: "node2",
-			WaitFor:   []string{"node1"},
+			WaitFor:   []*types.WaitFor{{Node: "node1"}},
 		},
 	).AnyTimes()
 
@@ -79,7 +80,7 @@ func getNodeMap(mockCtrl *gomock.Controller) map[string]nodes.Node {
 			Image:       "alpine:3",
 			NetworkMode: "container:node2",
 			ShortName:   "node3",
-			WaitFor:     []string{"node1", "node2"},
+			WaitFor:     []*types.WaitFor{{Node: "node1"}, {Node: "node2"}},
 		},
 	).AnyTimes()
 
@@ -101,7 +102,7 @@ func getNodeMap(mockCtrl *gomock.Controller) map[string]nodes.Node {
 			Image:           "alpine:3",
 			MgmtIPv4Address: "172.10.10.2",
 			ShortName:       "node5",
-			WaitFor:         []string{"node3", "node4"},
+			WaitFor:         []*types.WaitFor{{Node: "node3"}, {Node: "node4"}},
 		},
 	).AnyTimes()
 
@@ -118,25 +119,105 @@ func getNodeMap(mockCtrl *gomock.Controller) map[string]nodes.Node {
 	return nodeMap
 }
 
+// waitForNodeMap returns a map of mock nodes with the wait-for dependencies.
+func waitForNodeMap(mockCtrl *gomock.Controller, waitFor map[string][]*types.WaitFor) map[string]nodes.Node {
+	nodeMap := map[string]nodes.Node{}
+	for name, wf := range waitFor {
+		mockNode := mocks.NewMockNode(mockCtrl)
+		mockNode.EXPECT().Config().Return(
+			&types.NodeConfig{
+				Image:     "alpine:3",
+				ShortName: name,
+				WaitFor:   wf,
+			},
+		).AnyTimes()
+		nodeMap[name] = mockNode
+	}
+
+	return nodeMap
+}
+
 func Test_createWaitForDependency(t *testing.T) {
-	mockCtrl := gomock.NewController(t)
-	defer mockCtrl.Finish()
+	type dependency struct {
+		dependee string
+		stage    string
+		depender string
+		timeout  time.Duration
+	}
 
-	// instantiate a dependencyManager mock
-	dm := mocks.NewMockDependencyManager(mockCtrl)
+	create := types.WaitForStageCreate
+
+	tests := []struct {
+		name    string
+		nodeMap func(mockCtrl *gomock.Controller) map[string]nodes.Node
+		want    []dependency
+	}{
+		{
+			name:    "create stage",
+			nodeMap: getNodeMap,
+			want: []dependency{
+				{"node1", create, "node2", 0},
+				{"node1", create, "node3", 0},
+				{"node2", create, "node3", 0},
+				{"node3", create, "node5", 0},
+				{"node4", create, "node5", 0},
+			},
+		},
+		{
+			name: "stages with default timeouts",
+			nodeMap: func(mockCtrl *gomock.Controller) map[string]nodes.Node {
+				return waitForNodeMap(mockCtrl, map[string][]*types.WaitFor{
+					"node1": nil,
+					"node2": {{Node: "node1", Stage: types.WaitForStageHealthy}},
+					"node3": {
+						{Node: "node1", Stage: types.WaitForStageCreate},
+						{Node: "node2", Stage: types.WaitForStagePostDeploy},
+					},
+				})
+			},
+			want: []dependency{
+				{"node1", types.WaitForStageHealthy, "node2", types.DefaultWaitForHealthyTimeout},
+				{"node1", create, "node3", 0},
+				{"node2", types.WaitForStagePostDeploy, "node3", 0},
+			},
+		},
+		{
+			name: "stages with timeouts",
+			nodeMap: func(mockCtrl *gomock.Controller) map[string]nodes.Node {
+				return waitForNodeMap(mockCtrl, map[string][]*types.WaitFor{
+					"node1": nil,
+					"node2": {{Node: "node1", Timeout: "30s"}},
+					"node3": {
+						{Node: "node1", Stage: types.WaitForStageHealthy, Timeout: "1m"},
+						{Node: "node2", Stage: types.WaitForStagePostDeploy, Timeout: "5m"},
+					},
+				})
+			},
+			want: []dependency{
+				{"node1", create, "node2", 30 * time.Second},
+				{"node1", types.WaitForStageHealthy, "node3", time.Minute},
+				{"node2", types.WaitForStagePostDeploy, "node3", 5 * time.Minute},
+			},
+		},
+	}
 
-	// retrieve a map of nodes
-	nodeMap := getNodeMap(mockCtrl)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
 
-	dm.EXPECT().AddDependency("node1", "node2")
-	dm.EXPECT().AddDependency("node1", "node3")
-	dm.EXPECT().AddDependency("node2", "node3")
-	dm.EXPECT().AddDependency("node3", "node5")
-	dm.EXPECT().AddDependency("node4", "node5")
+			// instantiate a dependencyManager mock
+			dm := mocks.NewMockDependencyManager(mockCtrl)
 
-	err := createWaitForDependency(nodeMap, dm)
-	if err != nil {
-		t.Error(err)
+			for _, d := range tt.want {
+				dm.EXPECT().AddStageDependency(d.dependee, d.stage, d.depender, create, d.timeout)
+			}
+
+			err := createWaitForDependency(tt.nodeMap(mockCtrl), dm)
+			if err != nil {
+				t.Error(err)
+			}
+		})
 	}
 }
 
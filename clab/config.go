@@ -38,14 +38,15 @@ const (
 	ClabOUI = "aa:c1:ab"
 
 	// label names.
-	ContainerlabLabel = "containerlab"
-	NodeNameLabel     = "clab-node-name"
-	NodeKindLabel     = "clab-node-kind"
-	NodeTypeLabel     = "clab-node-type"
-	NodeGroupLabel    = "clab-node-group"
-	NodeLabDirLabel   = "clab-node-lab-dir"
-	TopoFileLabel     = "clab-topo-file"
-	NodeMgmtNetBr     = "clab-mgmt-net-bridge"
+	ContainerlabLabel   = "containerlab"
+	NodeNameLabel       = "clab-node-name"
+	NodeKindLabel       = "clab-node-kind"
+	NodeTypeLabel       = "clab-node-type"
+	NodeGroupLabel      = "clab-node-group"
+	NodeLabDirLabel     = "clab-node-lab-dir"
+	TopoFileLabel       = "clab-topo-file"
+	NodeMgmtNetBr       = "clab-mgmt-net-bridge"
+	NodeConfigHashLabel = "clab-node-config-hash"
 
 	// clab specific topology variables.
 	clabDirVar = "__clabDir__"
@@ -57,28 +58,40 @@ type Config struct {
 	Name     string          `json:"name,omitempty"`
 	Prefix   *string         `json:"prefix,omitempty"`
 	Mgmt     *types.MgmtNet  `json:"mgmt,omitempty"`
+	IPAM     *types.IPAM     `json:"ipam,omitempty"`
 	Topology *types.Topology `json:"topology,omitempty"`
+	// CertificateAuthority is the lab root CA, which signs the certificates of the nodes
+	CertificateAuthority *types.CertificateAuthority `yaml:"certificate-authority,omitempty" json:"certificate-authority,omitempty"`
+	// Hooks run on the containerlab host at the stages of the lab lifecycle
+	Hooks *types.Hooks `json:"hooks,omitempty"`
 }
 
 // ParseTopology parses the lab topology.
 func (c *CLab) parseTopology() error {
 	log.Infof("Parsing & checking topology file: %s", c.TopoFile.fullName)
 
-	cwd, _ := filepath.Abs(os.Getenv("PWD"))
-
 	if c.Config.Prefix == nil {
 		c.Config.Prefix = new(string)
 		*c.Config.Prefix = defaultPrefix
 	}
 
-	c.Dir = &Directory{}
-	// labDir is always named clab-$labName, regardless of the prefix
-	labDir := strings.Join([]string{"clab", c.Config.Name}, "-")
-	c.Dir.Lab = filepath.Join(cwd, labDir)
+	c.Dir = newDirectory(LabDir(c.Config.Name))
 
-	c.Dir.LabCA = filepath.Join(c.Dir.Lab, "ca")
-	c.Dir.LabCARoot = filepath.Join(c.Dir.LabCA, "root")
-	c.Dir.LabGraph = filepath.Join(c.Dir.Lab, "graph")
+	if ca := c.Config.CertificateAuthority; ca != nil {
+		if err := ca.Validate(); err != nil {
+			return fmt.Errorf("%s: certificate-authority: %v",
+				c.TopoFile.position("certificate-authority"), err)
+		}
+		// paths of the imported CA are relative to the topology file
+		if ca.IsImported() {
+			ca.Cert = utils.ResolvePath(ca.Cert, c.TopoFile.dir)
+			ca.Key = utils.ResolvePath(ca.Key, c.TopoFile.dir)
+		}
+	}
+
+	if err := c.Config.Hooks.Validate(); err != nil {
+		return fmt.Errorf("%s: hooks: %v", c.TopoFile.position("hooks"), err)
+	}
 
 	// initialize Nodes and Links variable
 	c.Nodes = make(map[string]nodes.Node)
@@ -94,7 +107,15 @@ func (c *CLab) parseTopology() error {
 	// collect node runtimes in a map[NodeName] -> RuntimeName
 	nodeRuntimes := make(map[string]string)
 
+	if err := c.verifyHosts(nodeNames); err != nil {
+		return err
+	}
+
 	for nodeName, topologyNode := range c.Config.Topology.Nodes {
+		// nodes of other hosts of a distributed lab are not deployed
+		if !c.isLocalNode(nodeName) {
+			continue
+		}
 		// this case is when runtime was overridden at the node level
 		if r := c.Config.Topology.GetNodeRuntime(nodeName); r != "" {
 			nodeRuntimes[nodeName] = r
@@ -112,6 +133,71 @@ func (c *CLab) parseTopology() error {
 	}
 
 	// initialize any extra runtimes
+	err := c.initRuntimes(nodeRuntimes)
+	if err != nil {
+		return err
+	}
+
+	for idx, nodeName := range nodeNames {
+		if !c.isLocalNode(nodeName) {
+			continue
+		}
+		err = c.NewNode(nodeName, nodeRuntimes[nodeName], c.Config.Topology.Nodes[nodeName], idx)
+		if err != nil {
+			return err
+		}
+	}
+	vnis := c.crossHostVNIs()
+	for i, l := range c.Config.Topology.Links {
+		// links of a distributed lab are limited to the ones of the local nodes
+		l = c.localLinkConfig(l, vnis[i])
+		if l == nil {
+			continue
+		}
+		// i represents the endpoint integer and l provide the link struct
+		c.Links[i], err = c.NewLink(l)
+		if err != nil {
+			// errors are reported with the position of the link or its endpoint in the topology file
+			path := []interface{}{"topology", "links", i}
+			var epErr *endpointError
+			if errors.As(err, &epErr) {
+				path = append(path, "endpoints", epErr.idx)
+			}
+			return fmt.Errorf("%s: %v", c.TopoFile.position(path...), err)
+		}
+	}
+
+	if err := c.allocateVNIs(); err != nil {
+		return err
+	}
+
+	// set any containerlab defaults after we've parsed the input
+	c.setDefaults()
+
+	return c.allocateAddresses()
+}
+
+// LabDir returns the path of the directory of a lab named labName.
+// Lab directory is created in the current working directory and
+// is always named clab-$labName, regardless of the prefix.
+func LabDir(labName string) string {
+	cwd, _ := filepath.Abs(os.Getenv("PWD"))
+	return filepath.Join(cwd, strings.Join([]string{"clab", labName}, "-"))
+}
+
+// newDirectory returns the lab directories layout for a given lab directory.
+func newDirectory(labDir string) *Directory {
+	d := &Directory{Lab: labDir}
+	d.LabCA = filepath.Join(d.Lab, "ca")
+	d.LabCARoot = filepath.Join(d.LabCA, "root")
+	d.LabGraph = filepath.Join(d.Lab, "graph")
+
+	return d
+}
+
+// initRuntimes initializes the runtimes referenced by nodes that are not yet initialized.
+// nodeRuntimes is a map of node names to runtime names.
+func (c *CLab) initRuntimes(nodeRuntimes map[string]string) error {
 	for _, r := range nodeRuntimes {
 		// this is the case for already init'ed runtimes
 		if _, ok := c.Runtimes[r]; ok {
@@ -133,21 +219,6 @@ func (c *CLab) parseTopology() error {
 		}
 	}
 
-	var err error
-	for idx, nodeName := range nodeNames {
-		err = c.NewNode(nodeName, nodeRuntimes[nodeName], c.Config.Topology.Nodes[nodeName], idx)
-		if err != nil {
-			return err
-		}
-	}
-	for i, l := range c.Config.Topology.Links {
-		// i represents the endpoint integer and l provide the link struct
-		c.Links[i] = c.NewLink(l)
-	}
-
-	// set any containerlab defaults after we've parsed the input
-	c.setDefaults()
-
 	return nil
 }
 
@@ -228,13 +299,36 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		Memory:          c.Config.Topology.GetNodeMemory(nodeName),
 		StartupDelay:    c.Config.Topology.GetNodeStartupDelay(nodeName),
 		AutoRemove:      c.Config.Topology.GetNodeAutoRemove(nodeName),
-		SANs:            c.Config.Topology.GetSANs(nodeName),
+		Certificate:     c.Config.Topology.GetNodeCertificate(nodeName),
+		Healthcheck:     c.Config.Topology.GetNodeHealthcheck(nodeName),
+		Hooks:           c.Config.Topology.GetNodeHooks(nodeName),
 
 		// Extras
 		Extras:  c.Config.Topology.GetNodeExtras(nodeName),
-		WaitFor: c.Config.Topology.GetWaitFor(nodeName),
+		WaitFor: c.localWaitFor(c.Config.Topology.GetWaitFor(nodeName)),
 	}
 
+	if err := nodeCfg.Certificate.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: node %q certificate: %v",
+			c.TopoFile.position("topology", "nodes", nodeName, "certificate"), err)
+	}
+	if err := nodeCfg.Healthcheck.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: node %q healthcheck: %v",
+			c.TopoFile.position("topology", "nodes", nodeName, "healthcheck"), err)
+	}
+	if err := nodeCfg.Hooks.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: node %q hooks: %v",
+			c.TopoFile.position("topology", "nodes", nodeName, "hooks"), err)
+	}
+	for _, w := range nodeCfg.WaitFor {
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: node %q wait-for: %v",
+				c.TopoFile.position("topology", "nodes", nodeName, "wait-for"), err)
+		}
+	}
+	// SANs of the node certificate are merged with the SANs set on the node
+	nodeCfg.SANs = utils.MergeStringSlices(c.Config.Topology.GetSANs(nodeName), nodeCfg.Certificate.GetSANs())
+
 	var err error
 
 	// Load content of the EnvVarFiles
@@ -272,6 +366,11 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 		return nil, err
 	}
 	nodeCfg.Binds = binds
+	// the directory with the issued certificate and key of the node is mounted to the node
+	if nodeCfg.Certificate.IsIssued() {
+		nodeCfg.Binds = append(nodeCfg.Binds,
+			filepath.Join(c.Dir.LabCA, nodeName)+":"+nodeCfg.Certificate.GetPath()+":ro")
+	}
 	nodeCfg.PortSet, nodeCfg.PortBindings, err = c.Config.Topology.GetNodePorts(nodeName)
 	if err != nil {
 		return nil, err
@@ -284,48 +383,140 @@ func (c *CLab) createNodeCfg(nodeName string, nodeDef *types.NodeDefinition, idx
 	return nodeCfg, nil
 }
 
+// endpointError is an error of the endpoint with the index idx in the link definition.
+type endpointError struct {
+	idx int
+	err error
+}
+
+func (e *endpointError) Error() string {
+	return e.err.Error()
+}
+
 // NewLink initializes a new link object.
-func (c *CLab) NewLink(l *types.LinkConfig) *types.Link {
-	if len(l.Endpoints) != 2 {
-		log.Fatalf("endpoint %q has wrong syntax, unexpected number of items", l.Endpoints) // skipcq: RVV-A0003
+// Errors of the link endpoints are returned as *endpointError.
+func (c *CLab) NewLink(l *types.LinkConfig) (*types.Link, error) {
+	if err := l.ValidateType(); err != nil {
+		return nil, fmt.Errorf("link %q has wrong syntax, %v", l.Endpoints, err)
+	}
+	if err := l.ValidateEndpointAttrs(); err != nil {
+		return nil, fmt.Errorf("link %q: %v", l.Endpoints, err)
 	}
 
-	return &types.Link{
-		A:      c.NewEndpoint(l.Endpoints[0]),
-		B:      c.NewEndpoint(l.Endpoints[1]),
+	endpoints := make([]*types.Endpoint, 0, len(l.Endpoints))
+	for i, ec := range l.Endpoints {
+		e, err := c.NewEndpoint(ec, l.EndpointAttrs(i))
+		if err != nil {
+			return nil, &endpointError{idx: i, err: err}
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	link := &types.Link{
+		Type:   l.LinkType(),
+		A:      endpoints[0],
 		MTU:    DefaultVethLinkMTU,
 		Labels: l.Labels,
 		Vars:   l.Vars,
 	}
+
+	switch link.Type {
+	case types.LinkTypeVeth:
+		link.B = endpoints[1]
+	case types.LinkTypeMacvlan, types.LinkTypeHostDevice:
+		// the node endpoint is always the A side of the link, and the host interface is the B side
+		hostIdx := l.HostEndpointIdx()
+		link.A, link.B = endpoints[1-hostIdx], endpoints[hostIdx]
+		// these interfaces inherit the mtu of the host interface unless it is set on the endpoint
+		link.MTU = 0
+		link.MacvlanMode = l.Mode
+		if link.Type == types.LinkTypeMacvlan && link.MacvlanMode == "" {
+			link.MacvlanMode = types.MacvlanModeBridge
+		}
+		// the host device keeps its mac address unless it is set on the endpoint
+		if link.Type == types.LinkTypeHostDevice && l.EndpointAttrs(1-hostIdx).MAC == "" {
+			link.A.MAC = ""
+			updateNodeEndpoint(link.A)
+		}
+	case types.LinkTypeVxlan:
+		vxlanIdx := l.VxlanEndpointIdx()
+		link.A, link.B = endpoints[1-vxlanIdx], endpoints[vxlanIdx]
+		// the parameters are validated along with the endpoint
+		link.Vxlan, _ = types.ParseVxlanParams(link.B.EndpointName)
+		// the veth inherits the mtu of the vxlan interface unless it is set on the endpoint
+		link.MTU = 0
+	}
+
+	if !l.LinkImpairment.IsEmpty() {
+		impairment := l.LinkImpairment
+		link.Impairment = &impairment
+	}
+
+	return link, nil
 }
 
-// NewEndpoint initializes a new endpoint object.
-func (c *CLab) NewEndpoint(e string) *types.Endpoint {
-	// initialize a new endpoint
-	endpoint := new(types.Endpoint)
+// updateNodeEndpoint updates the copy of the endpoint e kept in the endpoints of its node.
+func updateNodeEndpoint(e *types.Endpoint) {
+	for i := range e.Node.Endpoints {
+		if e.Node.Endpoints[i].EndpointName == e.EndpointName {
+			e.Node.Endpoints[i] = *e
+		}
+	}
+}
 
-	// split the string to get node name and endpoint name
-	split := strings.Split(e, ":")
-	if len(split) != 2 {
-		log.Fatalf("endpoint %s has wrong syntax", e) // skipcq: GO-S0904, RVV-A0003
+// NewEndpoint initializes a new endpoint object with the attributes attrs.
+func (c *CLab) NewEndpoint(ec *types.EndpointConfig, attrs types.EndpointAttrs) (*types.Endpoint, error) {
+	if ec == nil {
+		return nil, fmt.Errorf("endpoint is empty")
+	}
+	if err := ec.Validate(); err != nil {
+		return nil, err
+	}
+	if err := attrs.Validate(); err != nil {
+		return nil, fmt.Errorf("endpoint %q: %v", ec, err)
 	}
-	nName := split[0] // node name
 
-	// initialize the endpoint name based on the split function
-	endpoint.EndpointName = split[1] // endpoint name
-	if len(endpoint.EndpointName) > 15 {
-		log.Fatalf("interface '%s' name exceeds maximum length of 15 characters",
-			endpoint.EndpointName) // skipcq: RVV-A0003
+	// initialize a new endpoint
+	endpoint := &types.Endpoint{
+		EndpointName: ec.Interface,
+		MAC:          attrs.MAC,
+		IPv4:         attrs.IPv4,
+		IPv6:         attrs.IPv6,
+		MTU:          attrs.MTU,
 	}
 	// generate unique MAC
-	endpoint.MAC = utils.GenMac(ClabOUI)
+	if endpoint.MAC == "" {
+		endpoint.MAC = utils.GenMac(ClabOUI)
+	}
 
 	// search the node pointer for a node name referenced in endpoint section
+	endpoint.Node = specialEndpointNode(ec.Node)
+	if endpoint.Node == nil {
+		c.m.Lock()
+		if n, ok := c.Nodes[ec.Node]; ok {
+			endpoint.Node = n.Config()
+			n.Config().Endpoints = append(n.Config().Endpoints, *endpoint)
+		}
+		c.m.Unlock()
+	}
+
+	// "host" node name is an exception, it may exist without a matching node
+	if endpoint.Node == nil {
+		return nil, fmt.Errorf("endpoint %q refers to node %q which is not specified in the 'topology.nodes' section", ec, ec.Node)
+	}
+
+	return endpoint, nil
+}
+
+// specialEndpointNode returns a node config for the reserved node names
+// that can be referenced in links without being defined in the topology nodes.
+// Nil is returned for other node names.
+func specialEndpointNode(nName string) *types.NodeConfig {
 	switch nName {
 	// "host" is a special reference to host namespace
 	// for which we create an special Node with kind "host"
 	case "host":
-		endpoint.Node = &types.NodeConfig{
+		return &types.NodeConfig{
 			Kind:             "host",
 			ShortName:        "host",
 			NSPath:           hostNSPath,
@@ -334,27 +525,21 @@ func (c *CLab) NewEndpoint(e string) *types.Endpoint {
 	// mgmt-net is a special reference to a bridge of the docker network
 	// that is used as the management network
 	case "mgmt-net":
-		endpoint.Node = &types.NodeConfig{
+		return &types.NodeConfig{
 			Kind:             "bridge",
 			ShortName:        "mgmt-net",
 			DeploymentStatus: "created",
 		}
-	default:
-		c.m.Lock()
-		if n, ok := c.Nodes[nName]; ok {
-			endpoint.Node = n.Config()
-			n.Config().Endpoints = append(n.Config().Endpoints, *endpoint)
+	// vxlan is a special reference to the remote end of a vxlan tunnel
+	case types.VxlanNodeName:
+		return &types.NodeConfig{
+			Kind:             types.VxlanNodeName,
+			ShortName:        types.VxlanNodeName,
+			DeploymentStatus: "created",
 		}
-		c.m.Unlock()
-	}
-
-	// stop the deployment if the matching node element was not found
-	// "host" node name is an exception, it may exist without a matching node
-	if endpoint.Node == nil {
-		log.Fatalf("not all nodes are specified in the 'topology.nodes' section or the names don't match in the 'links.endpoints' section: %s", nName) // skipcq: GO-S0904, RVV-A0003
 	}
 
-	return endpoint
+	return nil
 }
 
 // CheckTopologyDefinition runs topology checks and returns any errors found.
@@ -371,6 +556,9 @@ func (c *CLab) CheckTopologyDefinition(ctx context.Context) error {
 	if err = c.verifyLinks(); err != nil {
 		return err
 	}
+	if err = c.verifyRootlessLinks(); err != nil {
+		return err
+	}
 	if err = c.verifyDuplicateAddresses(); err != nil {
 		return err
 	}
@@ -394,10 +582,29 @@ func (c *CLab) verifyLinks() error {
 	// dups accumulates duplicate links
 	dups := []string{}
 	for _, lc := range c.Config.Topology.Links {
-		for _, e := range lc.Endpoints {
-			if err := checkEndpoint(e); err != nil {
-				return err
+		if err := lc.LinkImpairment.Validate(); err != nil {
+			return fmt.Errorf("link %q has invalid impairment: %v", lc.Endpoints, err)
+		}
+		hostIdx := -1
+		if lc.Type == types.LinkTypeMacvlan || lc.Type == types.LinkTypeHostDevice {
+			hostIdx = lc.HostEndpointIdx()
+		}
+		for i, ec := range lc.Endpoints {
+			switch {
+			// the host interface of macvlan links can be shared by many links
+			case i == hostIdx && lc.Type == types.LinkTypeMacvlan:
+				continue
+			// the tunnels of vxlan links are checked for uniqueness once their vnis are allocated
+			case ec.Node == types.VxlanNodeName:
+				continue
+			// the host interface of host-device links is an existing interface, which may well be eth0
+			case i == hostIdx:
+			default:
+				if err := checkEndpoint(ec); err != nil {
+					return err
+				}
 			}
+			e := ec.String()
 			if _, ok := endpoints[e]; ok {
 				dups = append(dups, e)
 			}
@@ -495,6 +702,12 @@ func (c *CLab) VerifyContainersUniqueness(ctx context.Context) error {
 // and ensure that nodes that are configured with host networking mode do not have any interfaces defined.
 func (c *CLab) verifyHostIfaces() error {
 	for _, l := range c.Links {
+		if !l.IsVeth() {
+			if err := verifyNonVethLink(l); err != nil {
+				return err
+			}
+			continue
+		}
 		for _, ep := range []*types.Endpoint{l.A, l.B} {
 			if ep.Node.ShortName == "host" {
 				if nl, _ := netlink.LinkByName(ep.EndpointName); nl != nil {
@@ -510,19 +723,52 @@ func (c *CLab) verifyHostIfaces() error {
 	return nil
 }
 
+// verifyNonVethLink ensures that the node of a link of other type than veth is not in the host network mode
+// and that the host interface of macvlan, host-device and vxlan links exists.
+func verifyNonVethLink(l *types.Link) error {
+	if l.A.Node.NetworkMode == "host" {
+		return fmt.Errorf("node '%s' is defined with host network mode, it can't have any links. Remove '%s' node links from the topology definition",
+			l.A.Node.ShortName, l.A.Node.ShortName)
+	}
+	if inRootNetns(l.A.Node) {
+		return fmt.Errorf("%s link %s can't connect node '%s' of kind %s", l.Type, l, l.A.Node.ShortName, l.A.Node.Kind)
+	}
+
+	hostIf := ""
+	switch {
+	case l.Type == types.LinkTypeMacvlan || l.Type == types.LinkTypeHostDevice:
+		hostIf = l.B.EndpointName
+	case l.Type == types.LinkTypeVxlan && l.Vxlan.Dev != "":
+		hostIf = l.Vxlan.Dev
+	}
+	if hostIf == "" {
+		return nil
+	}
+	if _, err := netlink.LinkByName(hostIf); err != nil {
+		return fmt.Errorf("host interface %s referenced in the %s link %s doesn't exist", hostIf, l.Type, l)
+	}
+	return nil
+}
+
 // verifyRootNetnsInterfaceUniqueness ensures that interafaces that appear in the root ns (bridge, ovs-bridge and host)
-// are uniquely defined in the topology file.
+// are uniquely defined in the topology file. The host interfaces of macvlan links are the parents of the macvlan
+// interfaces, thus they can be shared by macvlan links, but not used by other links.
 func (c *CLab) verifyRootNetnsInterfaceUniqueness() error {
-	rootNsIfaces := map[string]struct{}{}
+	// rootNsIfaces maps the root ns interfaces to whether they are the parents of macvlan interfaces
+	rootNsIfaces := map[string]bool{}
 	for _, l := range c.Links {
-		endpoints := [2]*types.Endpoint{l.A, l.B}
-		for _, e := range endpoints {
+		// dummy links have a single endpoint
+		for _, e := range []*types.Endpoint{l.A, l.B} {
+			if e == nil {
+				continue
+			}
 			if e.Node.Kind == nodes.NodeKindBridge || e.Node.Kind == nodes.NodeKindOVS || e.Node.Kind == nodes.NodeKindHOST {
-				if _, ok := rootNsIfaces[e.EndpointName]; ok {
+				macvlanParent := l.Type == types.LinkTypeMacvlan && e == l.B
+				if parent, ok := rootNsIfaces[e.EndpointName]; ok && !(parent && macvlanParent) {
 					return fmt.Errorf(`interface %s defined for node %s has already been used in other bridges, ovs-bridges or host interfaces.
 					Make sure that nodes of these kinds use unique interface names`, e.EndpointName, e.Node.ShortName)
 				}
-				rootNsIfaces[e.EndpointName] = struct{}{}
+				rootNsIfaces[e.EndpointName] = macvlanParent
 			}
 		}
 	}
@@ -530,12 +776,11 @@ func (c *CLab) verifyRootNetnsInterfaceUniqueness() error {
 }
 
 // checkEndpoint runs checks on the endpoint syntax.
-func checkEndpoint(e string) error {
-	split := strings.Split(e, ":")
-	if len(split) != 2 {
-		return fmt.Errorf("malformed endpoint definition: %s", e)
+func checkEndpoint(e *types.EndpointConfig) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("malformed endpoint definition: %v", err)
 	}
-	if split[1] == "eth0" {
+	if e.Interface == "eth0" {
 		return fmt.Errorf("eth0 interface can't be used in the endpoint definition as it is added by docker automatically: '%s'", e)
 	}
 	return nil
@@ -648,6 +893,10 @@ func (c *CLab) addDefaultLabels(n nodes.Node) {
 		cfg.Labels = map[string]string{}
 	}
 
+	// the digest is calculated before the default labels are added,
+	// since some of them, like the topology file path, don't affect the container
+	cfg.Labels[NodeConfigHashLabel] = nodeConfigHash(cfg)
+
 	cfg.Labels[ContainerlabLabel] = c.Config.Name
 	cfg.Labels[NodeNameLabel] = cfg.ShortName
 	cfg.Labels[NodeKindLabel] = cfg.Kind
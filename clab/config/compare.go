@@ -0,0 +1,339 @@
+package config
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/srl-labs/containerlab/clab/config/transport"
+	"github.com/srl-labs/containerlab/clab/exec"
+	"github.com/srl-labs/containerlab/nodes"
+)
+
+// runningConfigCmds are the commands showing the running configuration of a node
+// that are executed in the node's container.
+var runningConfigCmds = map[string]string{
+	"srl":  `sr_cli -d "info from running"`,
+	"ceos": `Cli -p 15 -c "show running-config"`,
+	"crpd": `cli show configuration`,
+}
+
+// NodeDiff is the difference between the rendered and the running configuration of a node.
+type NodeDiff struct {
+	Node string `json:"node"`
+	// Drift is true when the running configuration differs from the rendered one
+	Drift bool `json:"drift"`
+	// Diff is the unified diff from the rendered to the running configuration
+	Diff  string `json:"diff,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Compare compares the rendered configuration of a node to its running configuration.
+func Compare(ctx context.Context, cs *NodeConfig, node nodes.Node) (*NodeDiff, error) {
+	running, err := GetRunningConfig(ctx, cs, node)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := strings.Join(cs.Data, "\n")
+	// the XML configurations are converted to the indented form, which is flattened the same way as the CLI configurations
+	if runningConfigTransport(cs) == "netconf" {
+		rendered, err = xmlConfig(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the rendered configuration: %v", err)
+		}
+		running, err = xmlConfig(running)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the running configuration: %v", err)
+		}
+	}
+
+	diff, err := diffConfig(cs.TargetNode.ShortName, rendered, running)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodeDiff{
+		Node:  cs.TargetNode.ShortName,
+		Drift: diff != "",
+		Diff:  diff,
+	}, nil
+}
+
+// GetRunningConfig fetches the running configuration of a node.
+// The transport is selected with the config.transport label:
+// "exec" runs a kind specific show command in the node's container, "ssh" and "netconf" use the respective transports.
+// When the label is not set, exec is used for the kinds that support it and SSH for the rest.
+func GetRunningConfig(ctx context.Context, cs *NodeConfig, node nodes.Node) (string, error) {
+	ct := runningConfigTransport(cs)
+	switch ct {
+	case "exec":
+		c, ok := runningConfigCmds[cs.TargetNode.Kind]
+		if !ok {
+			return "", fmt.Errorf("reading the running configuration with exec is not supported for kind %s", cs.TargetNode.Kind)
+		}
+
+		cmd, err := exec.NewExecCmdFromString(c)
+		if err != nil {
+			return "", err
+		}
+
+		execResult, err := node.RunExec(ctx, cmd)
+		if err != nil {
+			return "", fmt.Errorf("failed to execute cmd: %v", err)
+		}
+
+		if execResult.GetReturnCode() != 0 || len(execResult.GetStdErrString()) > 0 {
+			return "", fmt.Errorf("failed to read the running configuration: %s", execResult.GetStdErrString())
+		}
+
+		return execResult.GetStdOutString(), nil
+	case "ssh":
+		tx, err := newSSHTransport(cs.TargetNode)
+		if err != nil {
+			return "", err
+		}
+
+		return transport.Read(tx, cs.TargetNode.LongName)
+	case "netconf":
+		tx, err := newNetconfTransport(cs.TargetNode)
+		if err != nil {
+			return "", err
+		}
+
+		return transport.Read(tx, cs.TargetNode.LongName)
+	}
+
+	return "", fmt.Errorf("unknown transport: %s", ct)
+}
+
+// runningConfigTransport returns the transport the running configuration of a node is fetched with.
+func runningConfigTransport(cs *NodeConfig) string {
+	if ct, ok := cs.TargetNode.Labels["config.transport"]; ok {
+		return ct
+	}
+	if _, ok := runningConfigCmds[cs.TargetNode.Kind]; ok {
+		return "exec"
+	}
+	return "ssh"
+}
+
+// diffConfig returns the unified diff of the rendered and the running configuration of a node,
+// which is empty when they are the same.
+// Both configurations are flattened to the sorted paths of their leaves and only the running configuration leaves
+// in the scope of the rendered ones are compared, so that the rendered snippets can cover a part of the node configuration.
+func diffConfig(node, rendered, running string) (string, error) {
+	renderedLeaves := configLeaves(rendered)
+
+	scope := map[string]struct{}{}
+	for _, l := range renderedLeaves {
+		scope[leafKey(l)] = struct{}{}
+	}
+
+	var runningLeaves [][]string
+	for _, l := range configLeaves(running) {
+		if _, ok := scope[leafKey(l)]; ok {
+			runningLeaves = append(runningLeaves, l)
+		}
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        leafLines(renderedLeaves),
+		B:        leafLines(runningLeaves),
+		FromFile: "rendered/" + node,
+		ToFile:   "running/" + node,
+		Context:  3,
+	})
+}
+
+// configLeaves flattens the configuration cfg to the paths of its leaves.
+// The hierarchy of the configuration is defined by the indentation of its lines,
+// the braces of the blocks and the semicolons of the leaves are ignored.
+// The lines in the set form, e.g. "set / interface ethernet-1/1 admin-state enable",
+// are flattened to the same paths as their hierarchical form.
+func configLeaves(cfg string) [][]string {
+	type line struct {
+		indent int
+		words  []string
+	}
+
+	var lines []line
+	for _, l := range strings.Split(normalizeConfig(cfg), "\n") {
+		t := strings.TrimSpace(l)
+		// closing braces end the blocks, which are already tracked by the indentation
+		if strings.HasPrefix(t, "}") {
+			continue
+		}
+
+		words := strings.Fields(strings.TrimSuffix(strings.TrimSuffix(t, "{"), ";"))
+		if len(words) == 0 {
+			continue
+		}
+
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent == 0 {
+			words = trimConfigRoot(words)
+		}
+		if len(words) != 0 {
+			lines = append(lines, line{indent: indent, words: words})
+		}
+	}
+
+	var leaves [][]string
+	var parents []line
+	for i, l := range lines {
+		for len(parents) != 0 && parents[len(parents)-1].indent >= l.indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		// a line is a leaf unless the next line is indented under it
+		if i == len(lines)-1 || lines[i+1].indent <= l.indent {
+			var path []string
+			for _, p := range parents {
+				path = append(path, p.words...)
+			}
+			leaves = append(leaves, append(path, l.words...))
+		}
+
+		parents = append(parents, l)
+	}
+
+	return leaves
+}
+
+// trimConfigRoot removes the set keyword and the leading slash of the root path from the words of a top level line.
+func trimConfigRoot(words []string) []string {
+	if words[0] == "set" {
+		words = words[1:]
+	}
+	if len(words) != 0 && strings.HasPrefix(words[0], "/") {
+		if words[0] = strings.TrimPrefix(words[0], "/"); words[0] == "" {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// leafKey returns the key of the leaf path, which is the path without the leaf value,
+// so that a running configuration leaf with the changed value is in the scope of the rendered one.
+func leafKey(path []string) string {
+	if len(path) > 1 {
+		path = path[:len(path)-1]
+	}
+	return strings.Join(path, " ")
+}
+
+// leafLines returns the sorted lines of the leaf paths.
+func leafLines(leaves [][]string) []string {
+	lines := make([]string, 0, len(leaves))
+	for _, l := range leaves {
+		lines = append(lines, strings.Join(l, " ")+"\n")
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// normalizeConfig removes the trailing whitespace, empty lines and comments from the configuration,
+// so that only the meaningful lines are compared.
+func normalizeConfig(cfg string) string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(cfg, "\r", ""), "\n") {
+		l = strings.TrimRight(l, " \t")
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "!") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// xmlEnvelopes are the elements wrapping the configuration in the NETCONF messages.
+var xmlEnvelopes = map[string]struct{}{
+	"rpc-reply": {},
+	"data":      {},
+	"config":    {},
+}
+
+// xmlElement is an element of an XML configuration.
+type xmlElement struct {
+	name     string
+	text     string
+	children []*xmlElement
+}
+
+// xmlConfig converts the XML configuration cfg to the indented form, in which an element is a line of its name
+// followed by its value, or by the indented lines of its child elements.
+// The rpc-reply, data and config elements wrapping the configuration, the namespaces and the attributes are removed.
+// The first leaf of an element, which is the key of a list entry by the YANG rules, is added to the element name
+// in the XPath form, e.g. port[port-id=1/1/c1], so that the leaves of the list entries are told apart.
+func xmlConfig(cfg string) (string, error) {
+	root := &xmlElement{}
+	stack := []*xmlElement{root}
+
+	d := xml.NewDecoder(strings.NewReader(cfg))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		cur := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e := &xmlElement{name: t.Name.Local}
+			cur.children = append(cur.children, e)
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			cur.text += string(t)
+		}
+	}
+
+	var b strings.Builder
+	for _, e := range unwrapXMLEnvelopes(root.children) {
+		writeXMLElement(&b, e, 0)
+	}
+
+	return b.String(), nil
+}
+
+// unwrapXMLEnvelopes replaces the envelope elements with their child elements.
+func unwrapXMLEnvelopes(elems []*xmlElement) []*xmlElement {
+	var res []*xmlElement
+	for _, e := range elems {
+		if _, ok := xmlEnvelopes[e.name]; ok {
+			res = append(res, unwrapXMLEnvelopes(e.children)...)
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// writeXMLElement writes the line of the element e and the lines of its child elements indented under it.
+func writeXMLElement(b *strings.Builder, e *xmlElement, indent int) {
+	words := []string{e.name}
+	switch {
+	case len(e.children) == 0:
+		words = append(words, strings.Fields(e.text)...)
+	case len(e.children[0].children) == 0:
+		key := e.children[0]
+		words[0] = fmt.Sprintf("%s[%s=%s]", e.name, key.name, strings.Join(strings.Fields(key.text), " "))
+	}
+
+	b.WriteString(strings.Repeat("  ", indent))
+	b.WriteString(strings.Join(words, " "))
+	b.WriteString("\n")
+
+	for _, c := range e.children {
+		writeXMLElement(b, c, indent+1)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffConfig(t *testing.T) {
+	tests := map[string]struct {
+		rendered string
+		running  string
+		want     string
+	}{
+		"same config with different whitespace and comments": {
+			rendered: "/interface lo0 {\n    admin-state enable\n}\n\n# comment\n",
+			running:  "/interface lo0 {\r\n    admin-state enable   \r\n}\r\n",
+			want:     "",
+		},
+		"drift": {
+			rendered: "/interface lo0 {\n    admin-state enable\n}\n",
+			running:  "/interface lo0 {\n    admin-state disable\n}\n",
+			want: "--- rendered/srl1\n+++ running/srl1\n@@ -1 +1 @@\n" +
+				"-interface lo0 admin-state enable\n+interface lo0 admin-state disable\n",
+		},
+		"running config with unrelated config": {
+			rendered: "/interface ethernet-1/1 {\n    admin-state enable\n    subinterface 0 {\n        ipv4 {\n" +
+				"            address 10.0.0.1/31 {\n            }\n        }\n    }\n}\n",
+			running: "interface ethernet-1/1 {\n    admin-state enable\n    mtu 9000\n    subinterface 0 {\n" +
+				"        ipv4 {\n            address 10.0.0.1/31 {\n            }\n        }\n    }\n}\n" +
+				"interface mgmt0 {\n    admin-state enable\n}\nsystem {\n    name {\n        host-name srl1\n    }\n}\n",
+			want: "",
+		},
+		"set commands": {
+			rendered: "set / interface ethernet-1/1 admin-state enable\nset / system name host-name srl1\n",
+			running:  "interface ethernet-1/1 {\n    admin-state enable\n}\nsystem {\n    name {\n        host-name srl2\n    }\n}\n",
+			want: "--- rendered/srl1\n+++ running/srl1\n@@ -1,2 +1,2 @@\n" +
+				" interface ethernet-1/1 admin-state enable\n-system name host-name srl1\n+system name host-name srl2\n",
+		},
+		"braces and semicolons": {
+			rendered: "set interfaces eth1 unit 0 family inet address 10.0.0.1/31\n",
+			running: "interfaces {\n    eth1 {\n        unit 0 {\n            family inet {\n" +
+				"                address 10.0.0.1/31;\n            }\n        }\n    }\n    lo0 {\n        unit 0;\n    }\n}\n",
+			want: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := diffConfig("srl1", tc.rendered, tc.running)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("diff mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDiffXMLConfig(t *testing.T) {
+	rendered := `<config>
+  <configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf">
+    <system>
+      <name>sr1</name>
+    </system>
+    <port>
+      <port-id>1/1/c1</port-id>
+      <admin-state>enable</admin-state>
+    </port>
+    <port>
+      <port-id>1/1/c2</port-id>
+      <admin-state>enable</admin-state>
+    </port>
+  </configure>
+</config>`
+
+	tests := map[string]struct {
+		running string
+		want    string
+	}{
+		"same config": {
+			running: `<?xml version="1.0" encoding="UTF-8"?>` +
+				`<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="101"><data>` +
+				`<configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf" xmlns:nokia-attr="urn:nokia.com:sros:ns:yang:sr:attributes">` +
+				`<system><name>sr1</name><location>lab</location></system>` +
+				`<port><port-id>1/1/c2</port-id><admin-state>enable</admin-state></port>` +
+				`<port><port-id>1/1/c1</port-id><admin-state>enable</admin-state><description>uplink</description></port>` +
+				`</configure></data></rpc-reply>`,
+			want: "",
+		},
+		"drift": {
+			running: `<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="101"><data>` +
+				`<configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf"><system><name>sr1</name></system>` +
+				`<port><port-id>1/1/c1</port-id><admin-state>enable</admin-state></port>` +
+				`<port><port-id>1/1/c2</port-id><admin-state>disable</admin-state></port>` +
+				`</configure></data></rpc-reply>`,
+			want: "--- rendered/sr1\n+++ running/sr1\n@@ -1,5 +1,5 @@\n" +
+				" configure port[port-id=1/1/c1] admin-state enable\n" +
+				" configure port[port-id=1/1/c1] port-id 1/1/c1\n" +
+				"-configure port[port-id=1/1/c2] admin-state enable\n" +
+				"+configure port[port-id=1/1/c2] admin-state disable\n" +
+				" configure port[port-id=1/1/c2] port-id 1/1/c2\n" +
+				" configure system[name=sr1] name sr1\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := xmlConfig(rendered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			running, err := xmlConfig(tc.running)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := diffConfig("sr1", r, running)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("diff mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestXMLConfigErrors(t *testing.T) {
+	if _, err := xmlConfig("<rpc-reply><data><configure></data></rpc-reply>"); err == nil {
+		t.Error("expected error for malformed XML")
+	}
+}
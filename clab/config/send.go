@@ -1,12 +1,40 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/srl-labs/containerlab/clab/config/transport"
+	"github.com/srl-labs/containerlab/clab/exec"
 	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
 )
 
+// DryRun prints the requests instead of sending them, supported by the gNMI transport.
+var DryRun bool
+
+// dryRunOut is the writer the requests are printed to in the dry run.
+var dryRunOut io.Writer = os.Stdout
+
+// LabCARoot is the directory of the lab root CA, which certificate is the trust anchor of the gNMI transport.
+var LabCARoot string
+
+// sendConfigFile is the file in the node's container the raw config snippet is written to.
+const sendConfigFile = "/tmp/clab-config"
+
+// sendConfigCmds are the commands applying the raw config snippet stored in sendConfigFile
+// that are executed in the node's container.
+var sendConfigCmds = map[string]string{
+	"srl":  `bash -c "sr_cli -ed --post 'commit now' < ` + sendConfigFile + `"`,
+	"ceos": `bash -c "(echo configure; cat ` + sendConfigFile + `; echo end) | Cli -p 15"`,
+	"crpd": `cli -c "configure; load merge ` + sendConfigFile + `; commit and-quit"`,
+}
+
 func Send(cs *NodeConfig, _ string) error {
 	var tx transport.Transport
 	var err error
@@ -17,23 +45,17 @@ func Send(cs *NodeConfig, _ string) error {
 	}
 
 	if ct == "ssh" {
-
-		ssh_cred, err := nodes.GetDefaultCredentialsForKind(cs.TargetNode.Kind)
+		tx, err = newSSHTransport(cs.TargetNode)
 		if err != nil {
 			return err
 		}
-
-		if len(ssh_cred) < 2 {
-			return fmt.Errorf("SSH credentials for node %s of type %s not found, cannot configure",
-				cs.TargetNode.ShortName, cs.TargetNode.Kind)
+	} else if ct == "gnmi" {
+		tx, err = newGNMITransport(cs.TargetNode)
+		if err != nil {
+			return err
 		}
-		tx, err = transport.NewSSHTransport(
-			cs.TargetNode,
-			transport.WithUserNamePassword(
-				ssh_cred[0],
-				ssh_cred[1]),
-			transport.HostKeyCallback(),
-		)
+	} else if ct == "netconf" {
+		tx, err = newNetconfTransport(cs.TargetNode)
 		if err != nil {
 			return err
 		}
@@ -49,3 +71,149 @@ func Send(cs *NodeConfig, _ string) error {
 	}
 	return nil
 }
+
+// newSSHTransport creates an SSH transport to the node using the default credentials of its kind.
+func newSSHTransport(node *types.NodeConfig) (*transport.SSHTransport, error) {
+	ssh_cred, err := nodes.GetDefaultCredentialsForKind(node.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ssh_cred) < 2 {
+		return nil, fmt.Errorf("SSH credentials for node %s of type %s not found, cannot configure",
+			node.ShortName, node.Kind)
+	}
+
+	return transport.NewSSHTransport(
+		node,
+		transport.WithUserNamePassword(
+			ssh_cred[0],
+			ssh_cred[1]),
+		transport.HostKeyCallback(),
+	)
+}
+
+// newGNMITransport creates a gNMI transport to the node using the default credentials of its kind
+// and the lab root CA as the trust anchor.
+// The node address and the encoding are overridden with the config.gnmi.target and config.gnmi.encoding labels.
+func newGNMITransport(node *types.NodeConfig) (*transport.GNMITransport, error) {
+	cred, err := nodes.GetDefaultCredentialsForKind(node.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cred) < 2 {
+		return nil, fmt.Errorf("gNMI credentials for node %s of type %s not found, cannot configure",
+			node.ShortName, node.Kind)
+	}
+
+	opts := []transport.GNMITransportOption{
+		transport.WithGNMICredentials(cred[0], cred[1]),
+	}
+
+	if addr, ok := node.Labels["config.gnmi.target"]; ok {
+		opts = append(opts, transport.WithGNMIAddress(addr))
+	}
+	if enc, ok := node.Labels["config.gnmi.encoding"]; ok {
+		opts = append(opts, transport.WithGNMIEncoding(enc))
+	}
+
+	if DryRun {
+		opts = append(opts, transport.WithGNMIDryRun(dryRunOut))
+	} else {
+		opts = append(opts, transport.WithGNMITrustAnchor(
+			filepath.Join(LabCARoot, "root-ca.pem"), node.LongName))
+	}
+
+	return transport.NewGNMITransport(node, opts...)
+}
+
+// newNetconfTransport creates a NETCONF transport to the node using the default credentials of its kind.
+func newNetconfTransport(node *types.NodeConfig) (*transport.NetconfTransport, error) {
+	cred, err := nodes.GetDefaultCredentialsForKind(node.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cred) < 2 {
+		return nil, fmt.Errorf("NETCONF credentials for node %s of type %s not found, cannot configure",
+			node.ShortName, node.Kind)
+	}
+
+	return transport.NewNetconfTransport(node,
+		transport.WithNetconfCredentials(cred[0], cred[1]),
+	)
+}
+
+// SendRaw sends the raw config snippet data to a node bypassing the template rendering.
+// The transport is selected with the config.transport label:
+// "ssh" commits the snippet in a transaction using the kind's SSHKind,
+// "exec" applies the snippet with the kind's CLI in the node's container.
+// When the label is not set, SSH is used for the kinds that support it and exec for the rest.
+func SendRaw(ctx context.Context, node nodes.Node, data string) (exec.ExecResultHolder, error) {
+	cfg := node.Config()
+
+	ct, ok := cfg.Labels["config.transport"]
+	if !ok {
+		ct = "exec"
+		if transport.SSHKindSupported(cfg.Kind) {
+			ct = "ssh"
+		}
+	}
+
+	switch ct {
+	case "ssh":
+		tx, err := newSSHTransport(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Connect(cfg.LongName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", cfg.LongName, err)
+		}
+		defer tx.Close()
+
+		res := &exec.ExecResult{Cmd: []string{"ssh", tx.Target}}
+
+		out, err := tx.Send(data, "send")
+		res.SetStdOut([]byte(out))
+		if err != nil {
+			// the lines the node rejected precede the error, so that the cause of the failure is seen
+			stderr := err.Error()
+			var se *transport.SendError
+			if errors.As(err, &se) && len(se.Rejected) != 0 {
+				stderr = strings.Join(se.Rejected, "\n") + "\n" + stderr
+			}
+			res.SetStdErr([]byte(stderr))
+			res.SetReturnCode(1)
+		}
+
+		return res, nil
+	case "exec":
+		c, ok := sendConfigCmds[cfg.Kind]
+		if !ok {
+			return nil, fmt.Errorf("sending config with exec is not supported for kind %s", cfg.Kind)
+		}
+
+		// the snippet is streamed to the file over stdin, so that it is kept intact regardless of its size and content
+		cmd := exec.NewExecCmdFromSlice([]string{"bash", "-c", "cat > " + sendConfigFile})
+		cmd.SetStdin([]byte(data))
+		res, err := node.RunExec(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		if res.GetReturnCode() != 0 {
+			return nil, fmt.Errorf("failed to write config file %s: %s", sendConfigFile, res.GetStdErrString())
+		}
+
+		cmd, err = exec.NewExecCmdFromString(c)
+		if err != nil {
+			return nil, err
+		}
+
+		return node.RunExec(ctx, cmd)
+	}
+
+	return nil, fmt.Errorf("unknown transport: %s", ct)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/clab/exec"
+	"github.com/srl-labs/containerlab/mocks"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestSendRawExec(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	node := mocks.NewMockNode(mockCtrl)
+	node.EXPECT().Config().Return(
+		&types.NodeConfig{
+			ShortName: "ceos1",
+			Kind:      "ceos",
+		},
+	).AnyTimes()
+
+	res := &exec.ExecResult{Stdout: "ok"}
+
+	// the snippet is larger than the limit of a command argument and has quotes, backslashes and echo options
+	data := "-e\ndescription 'uplink' \\n\n" + strings.Repeat("interface Ethernet1\n", 10000)
+
+	write := exec.NewExecCmdFromSlice([]string{"bash", "-c", "cat > /tmp/clab-config"})
+	write.SetStdin([]byte(data))
+
+	gomock.InOrder(
+		node.EXPECT().RunExec(gomock.Any(), write).Return(&exec.ExecResult{}, nil),
+		node.EXPECT().RunExec(gomock.Any(), exec.NewExecCmdFromSlice([]string{
+			"bash", "-c", "(echo configure; cat /tmp/clab-config; echo end) | Cli -p 15",
+		})).Return(res, nil),
+	)
+
+	got, err := SendRaw(context.Background(), node, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff(res, got); d != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestSendRawExecWriteFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	node := mocks.NewMockNode(mockCtrl)
+	node.EXPECT().Config().Return(
+		&types.NodeConfig{
+			ShortName: "ceos1",
+			Kind:      "ceos",
+		},
+	).AnyTimes()
+
+	// the config is not applied when the config file is not written
+	node.EXPECT().RunExec(gomock.Any(), gomock.Any()).Return(
+		&exec.ExecResult{ReturnCode: 1, Stderr: "No space left on device"}, nil,
+	).Times(1)
+
+	_, err := SendRaw(context.Background(), node, "hostname ceos1")
+	if err == nil || !strings.Contains(err.Error(), "No space left on device") {
+		t.Errorf("expected error with the write stderr, got %v", err)
+	}
+}
+
+func TestSendRawUnsupportedKind(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	node := mocks.NewMockNode(mockCtrl)
+	node.EXPECT().Config().Return(
+		&types.NodeConfig{
+			ShortName: "linux1",
+			Kind:      "linux",
+		},
+	).AnyTimes()
+
+	if _, err := SendRaw(context.Background(), node, "ip link"); err == nil {
+		t.Error("expected error sending config to a linux node")
+	}
+}
+
+func TestSendGNMIDryRun(t *testing.T) {
+	// the srl kind credentials are registered with the kind, which is not registered in this package
+	if _, err := nodes.GetDefaultCredentialsForKind("srl"); err != nil {
+		if err := nodes.SetDefaultCredentials([]string{"srl"}, "admin", "NokiaSrl1!"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	DryRun, dryRunOut = true, out
+	defer func() { DryRun, dryRunOut = false, os.Stdout }()
+
+	cs := &NodeConfig{
+		TargetNode: &types.NodeConfig{
+			ShortName: "srl1",
+			LongName:  "clab-test-srl1",
+			Kind:      "srl",
+			Labels:    map[string]string{"config.transport": "gnmi"},
+		},
+		Data: []string{"/system name host-name srl1"},
+		Info: []string{"base__srl.tmpl"},
+	}
+
+	if err := Send(cs, "commit"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"# clab-test-srl1:57400 base__srl.tmpl", `origin:`, `"cli"`, `ascii_val:`, `"/system name host-name srl1"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dry-run output %q doesn't contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+const (
+	// GNMIEncodingJSONIETF sends the config as JSON_IETF encoded update of the root path.
+	GNMIEncodingJSONIETF = "json_ietf"
+	// GNMIEncodingCLI sends the config as ASCII encoded update with the cli origin.
+	GNMIEncodingCLI = "cli"
+
+	gnmiDialTimeout = 10 * time.Second
+	gnmiSetTimeout  = 30 * time.Second
+)
+
+// gnmiPorts are the default gNMI ports per kind.
+var gnmiPorts = map[string]int{
+	"srl":  57400,
+	"ceos": 6030,
+}
+
+type GNMITransportOption func(*GNMITransport) error
+
+// GNMITransport setting needs to be set before calling Connect()
+// GNMITransport implements the Transport interface.
+type GNMITransport struct {
+	// Address overrides the host passed to Connect, the port is optional
+	Address string
+	// gNMI port used when the address doesn't include it
+	Port int
+	// Encoding of the config, GNMIEncodingJSONIETF or GNMIEncodingCLI
+	// default: GNMIEncodingCLI
+	Encoding string
+
+	Username string
+	Password string
+
+	// TLS config with the trust anchor to verify the node's certificate
+	// required!
+	TLSConfig *tls.Config
+
+	// DryRun prints the SetRequests to Out instead of sending them
+	DryRun bool
+	Out    io.Writer
+
+	// Keep the target for logging
+	Target string
+
+	conn   *grpc.ClientConn
+	client gnmi.GNMIClient
+}
+
+// WithGNMICredentials adds username & password authentication.
+func WithGNMICredentials(username, password string) GNMITransportOption {
+	return func(tx *GNMITransport) error {
+		tx.Username = username
+		tx.Password = password
+		return nil
+	}
+}
+
+// WithGNMITrustAnchor verifies the node's certificate with the CA certificate from caFile.
+// The certificate is verified for serverName, which is required when the node is reached by its address.
+func WithGNMITrustAnchor(caFile, serverName string) GNMITransportOption {
+	return func(tx *GNMITransport) error {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the trust anchor: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("no certificates found in the trust anchor %s", caFile)
+		}
+
+		tx.TLSConfig = &tls.Config{
+			RootCAs:    pool,
+			ServerName: serverName,
+			MinVersion: tls.VersionTLS12,
+		}
+		return nil
+	}
+}
+
+// WithGNMIAddress overrides the address of the node.
+func WithGNMIAddress(address string) GNMITransportOption {
+	return func(tx *GNMITransport) error {
+		tx.Address = address
+		return nil
+	}
+}
+
+// WithGNMIEncoding sets the encoding of the config.
+func WithGNMIEncoding(encoding string) GNMITransportOption {
+	return func(tx *GNMITransport) error {
+		switch encoding {
+		case GNMIEncodingJSONIETF, GNMIEncodingCLI:
+			tx.Encoding = encoding
+			return nil
+		}
+		return fmt.Errorf("unsupported gNMI encoding %q, expected one of %q",
+			encoding, []string{GNMIEncodingJSONIETF, GNMIEncodingCLI})
+	}
+}
+
+// WithGNMIDryRun prints the SetRequests to w instead of sending them.
+func WithGNMIDryRun(w io.Writer) GNMITransportOption {
+	return func(tx *GNMITransport) error {
+		tx.DryRun = true
+		tx.Out = w
+		return nil
+	}
+}
+
+func NewGNMITransport(node *types.NodeConfig, options ...GNMITransportOption) (*GNMITransport, error) {
+	c := &GNMITransport{
+		Port:     gnmiPorts[node.Kind],
+		Encoding: GNMIEncodingCLI,
+	}
+
+	// apply options
+	for _, opt := range options {
+		err := opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Port == 0 && c.Address == "" {
+		return nil, fmt.Errorf("no gNMI port known for kind %s, set the node address with the port", node.Kind)
+	}
+
+	return c, nil
+}
+
+// Connect to a host
+// Part of the Transport interface.
+func (t *GNMITransport) Connect(host string, _ ...TransportOption) error {
+	addr := host
+	if t.Address != "" {
+		addr = t.Address
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, strconv.Itoa(t.Port))
+	}
+
+	t.Target = addr
+
+	if t.DryRun {
+		return nil
+	}
+
+	if t.TLSConfig == nil {
+		return fmt.Errorf("require a trust anchor in TLSConfig")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gnmiDialTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(credentials.NewTLS(t.TLSConfig)),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %s", addr, err)
+	}
+
+	t.conn = conn
+	t.client = gnmi.NewGNMIClient(conn)
+
+	log.Infof("Connected to %s\n", addr)
+	return nil
+}
+
+// Write a config snippet as a gNMI SetRequest
+// Part of the Transport interface.
+func (t *GNMITransport) Write(data, info *string) error {
+	if *data == "" {
+		return nil
+	}
+
+	req, err := t.SetRequest(*data)
+	if err != nil {
+		return err
+	}
+
+	if t.DryRun {
+		fmt.Fprintf(t.Out, "# %s %s\n%s\n", t.Target, *info, prototext.Format(req))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gnmiSetTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "username", t.Username, "password", t.Password)
+
+	_, err = t.client.Set(ctx, req)
+	if err != nil {
+		log.Errorf("%s %s SET failed", t.Target, *info)
+		return err
+	}
+
+	log.Infof("%s %s SET - %d bytes", t.Target, *info, len(*data))
+	return nil
+}
+
+// SetRequest creates the SetRequest updating the config with data.
+func (t *GNMITransport) SetRequest(data string) (*gnmi.SetRequest, error) {
+	u := &gnmi.Update{}
+
+	switch t.Encoding {
+	case GNMIEncodingJSONIETF:
+		if !json.Valid([]byte(data)) {
+			return nil, fmt.Errorf("config is not a valid JSON document")
+		}
+		u.Path = &gnmi.Path{}
+		u.Val = &gnmi.TypedValue{
+			Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(data)},
+		}
+	case GNMIEncodingCLI:
+		u.Path = &gnmi.Path{Origin: "cli"}
+		u.Val = &gnmi.TypedValue{
+			Value: &gnmi.TypedValue_AsciiVal{AsciiVal: data},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported gNMI encoding %q", t.Encoding)
+	}
+
+	return &gnmi.SetRequest{Update: []*gnmi.Update{u}}, nil
+}
+
+// Close the connection
+// Part of the Transport interface.
+func (t *GNMITransport) Close() {
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+}
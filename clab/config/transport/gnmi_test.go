@@ -0,0 +1,224 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/srl-labs/containerlab/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// gnmiServerStub is a gNMI server recording the received SetRequests.
+type gnmiServerStub struct {
+	gnmi.UnimplementedGNMIServer
+	requests []*gnmi.SetRequest
+}
+
+func (s *gnmiServerStub) Set(ctx context.Context, req *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if u, p := md.Get("username"), md.Get("password"); len(u) != 1 || u[0] != "admin" || len(p) != 1 || p[0] != "secret" {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	s.requests = append(s.requests, req)
+	return &gnmi.SetResponse{}, nil
+}
+
+// startGNMIServerStub starts a TLS gNMI server stub with a certificate for serverName
+// signed by a CA, which certificate is written to the returned file.
+func startGNMIServerStub(t *testing.T, serverName string) (stub *gnmiServerStub, addr, caFile string) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTpl, caTpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: serverName},
+		DNSNames:     []string{serverName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caFile = filepath.Join(t.TempDir(), "root-ca.pem")
+	err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.Creds(credentials.NewServerTLSFromCert(&tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	})))
+	stub = &gnmiServerStub{}
+	gnmi.RegisterGNMIServer(srv, stub)
+
+	go srv.Serve(l) // skipcq: GO-S2307
+	t.Cleanup(srv.Stop)
+
+	return stub, l.Addr().String(), caFile
+}
+
+func TestGNMITransportWrite(t *testing.T) {
+	node := &types.NodeConfig{
+		ShortName: "srl1",
+		LongName:  "clab-test-srl1",
+		Kind:      "srl",
+	}
+
+	tests := map[string]struct {
+		encoding string
+		data     string
+		want     *gnmi.SetRequest
+	}{
+		"cli": {
+			encoding: GNMIEncodingCLI,
+			data:     "set / system information location lab",
+			want: &gnmi.SetRequest{Update: []*gnmi.Update{{
+				Path: &gnmi.Path{Origin: "cli"},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "set / system information location lab"}},
+			}}},
+		},
+		"json_ietf": {
+			encoding: GNMIEncodingJSONIETF,
+			data:     `{"srl_nokia-system:system":{"information":{"location":"lab"}}}`,
+			want: &gnmi.SetRequest{Update: []*gnmi.Update{{
+				Path: &gnmi.Path{},
+				Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{
+					JsonIetfVal: []byte(`{"srl_nokia-system:system":{"information":{"location":"lab"}}}`),
+				}},
+			}}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stub, addr, caFile := startGNMIServerStub(t, node.LongName)
+
+			tx, err := NewGNMITransport(node,
+				WithGNMICredentials("admin", "secret"),
+				WithGNMITrustAnchor(caFile, node.LongName),
+				WithGNMIAddress(addr),
+				WithGNMIEncoding(tc.encoding),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info := "base__srl.tmpl"
+			if err := Write(tx, node.LongName, []string{tc.data}, []string{info}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(stub.requests) != 1 {
+				t.Fatalf("expected 1 SetRequest, got %d", len(stub.requests))
+			}
+			if d := cmp.Diff(tc.want, stub.requests[0], protocmp.Transform()); d != "" {
+				t.Errorf("SetRequest mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestGNMITransportUntrustedServer(t *testing.T) {
+	node := &types.NodeConfig{
+		ShortName: "srl1",
+		LongName:  "clab-test-srl1",
+		Kind:      "srl",
+	}
+
+	_, addr, _ := startGNMIServerStub(t, node.LongName)
+	// trust anchor of another CA
+	_, _, caFile := startGNMIServerStub(t, node.LongName)
+
+	tx, err := NewGNMITransport(node,
+		WithGNMICredentials("admin", "secret"),
+		WithGNMITrustAnchor(caFile, node.LongName),
+		WithGNMIAddress(addr),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Connect(node.LongName); err == nil {
+		tx.Close()
+		t.Error("expected error connecting to a server with a certificate of untrusted CA")
+	}
+}
+
+func TestGNMITransportDryRun(t *testing.T) {
+	node := &types.NodeConfig{
+		ShortName: "ceos1",
+		LongName:  "clab-test-ceos1",
+		Kind:      "ceos",
+	}
+
+	out := &bytes.Buffer{}
+	tx, err := NewGNMITransport(node, WithGNMIDryRun(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Write(tx, node.LongName, []string{"hostname ceos1"}, []string{"base__ceos.tmpl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"# clab-test-ceos1:6030 base__ceos.tmpl", `origin:`, `"cli"`, `ascii_val:`, `"hostname ceos1"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dry-run output %q doesn't contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package transport
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/scrapli/scrapligo/driver/netconf"
+	"github.com/scrapli/scrapligo/driver/options"
+	"github.com/scrapli/scrapligo/response"
+	"github.com/scrapli/scrapligo/transport"
+	"github.com/srl-labs/containerlab/types"
+)
+
+const (
+	netconfPort = 830
+)
+
+// netconfValidateCaps are the capabilities of the validate operation.
+var netconfValidateCaps = []string{
+	"urn:ietf:params:netconf:capability:validate:1.0",
+	"urn:ietf:params:netconf:capability:validate:1.1",
+}
+
+// netconfKinds are the kinds supporting the NETCONF transport.
+var netconfKinds = map[string]struct{}{
+	"vr-sros":  {},
+	"vr-vmx":   {},
+	"vr-vqfx":  {},
+	"vr-xrv":   {},
+	"vr-xrv9k": {},
+}
+
+type NetconfTransportOption func(*NetconfTransport) error
+
+// NetconfTransport setting needs to be set before calling Connect()
+// NetconfTransport implements the Transport and ConfigReader interfaces.
+//
+// The config is written with edit-config to the candidate datastore, validated and committed.
+// The candidate datastore is locked while the config is written
+// and the changes are discarded when any of the operations fail.
+type NetconfTransport struct {
+	// NETCONF port
+	// default: 830
+	Port int
+
+	Username string
+	Password string
+
+	// Keep the target for logging
+	Target string
+
+	d *netconf.Driver
+}
+
+// WithNetconfCredentials adds username & password authentication.
+func WithNetconfCredentials(username, password string) NetconfTransportOption {
+	return func(tx *NetconfTransport) error {
+		tx.Username = username
+		tx.Password = password
+		return nil
+	}
+}
+
+// WithNetconfPort sets the NETCONF port.
+func WithNetconfPort(port int) NetconfTransportOption {
+	return func(tx *NetconfTransport) error {
+		tx.Port = port
+		return nil
+	}
+}
+
+func NewNetconfTransport(node *types.NodeConfig, opts ...NetconfTransportOption) (*NetconfTransport, error) {
+	if _, ok := netconfKinds[node.Kind]; !ok {
+		return nil, fmt.Errorf("no netconf transport implemented for kind: %s", node.Kind)
+	}
+
+	c := &NetconfTransport{
+		Port: netconfPort,
+	}
+
+	// apply options
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Connect to a host
+// Part of the Transport interface.
+func (t *NetconfTransport) Connect(host string, _ ...TransportOption) error {
+	t.Target = fmt.Sprintf("%s:%d", host, t.Port)
+
+	d, err := netconf.NewDriver(
+		host,
+		options.WithAuthNoStrictKey(),
+		options.WithAuthUsername(t.Username),
+		options.WithAuthPassword(t.Password),
+		options.WithTransportType(transport.StandardTransport),
+		options.WithPort(t.Port),
+	)
+	if err != nil {
+		return fmt.Errorf("could not create netconf driver for %s: %v", t.Target, err)
+	}
+
+	err = d.Open()
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %v", t.Target, err)
+	}
+	t.d = d
+
+	log.Infof("Connected to %s\n", t.Target)
+	return nil
+}
+
+// Write a config snippet with edit-config to the candidate datastore, validate and commit it
+// Part of the Transport interface.
+func (t *NetconfTransport) Write(data, info *string) error {
+	if *data == "" {
+		return nil
+	}
+
+	err := netconfCheck(t.d.Lock("candidate"))
+	if err != nil {
+		return fmt.Errorf("could not lock the candidate datastore: %v", err)
+	}
+	defer func() {
+		if err := netconfCheck(t.d.Unlock("candidate")); err != nil {
+			log.Warnf("%s could not unlock the candidate datastore: %v", t.Target, err)
+		}
+	}()
+
+	err = t.commit(*data)
+	if err != nil {
+		log.Errorf("%s %s COMMIT failed, discarding the changes", t.Target, *info)
+		if derr := netconfCheck(t.d.Discard()); derr != nil {
+			log.Errorf("%s could not discard the changes: %v", t.Target, derr)
+		}
+		return err
+	}
+
+	log.Infof("%s %s COMMIT - %d bytes", t.Target, *info, len(*data))
+	return nil
+}
+
+// commit edits the candidate datastore with data, validates and commits it.
+func (t *NetconfTransport) commit(data string) error {
+	err := netconfCheck(t.d.EditConfig("candidate", data))
+	if err != nil {
+		return fmt.Errorf("edit-config failed: %v", err)
+	}
+
+	for _, c := range netconfValidateCaps {
+		if !t.d.ServerHasCapability(c) {
+			continue
+		}
+		err = netconfCheck(t.d.Validate("candidate"))
+		if err != nil {
+			return fmt.Errorf("validate failed: %v", err)
+		}
+		break
+	}
+
+	err = netconfCheck(t.d.Commit())
+	if err != nil {
+		return fmt.Errorf("commit failed: %v", err)
+	}
+
+	return nil
+}
+
+// RunningConfig reads the running configuration with get-config
+// Part of the ConfigReader interface.
+func (t *NetconfTransport) RunningConfig() (string, error) {
+	r, err := t.d.GetConfig("running")
+	if err := netconfCheck(r, err); err != nil {
+		return "", fmt.Errorf("get-config failed: %v", err)
+	}
+
+	return r.Result, nil
+}
+
+// Close the NETCONF session
+// Part of the Transport interface.
+func (t *NetconfTransport) Close() {
+	if t.d == nil {
+		return
+	}
+	if err := t.d.Close(); err != nil {
+		log.Debugf("%s: %v", t.Target, err)
+	}
+	t.d = nil
+}
+
+// netconfCheck returns the error of a NETCONF operation, including the rpc-error in its response.
+func netconfCheck(r *response.NetconfResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if r.Failed != nil {
+		return r.Failed
+	}
+	return nil
+}
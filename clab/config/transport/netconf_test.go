@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
+	"golang.org/x/crypto/ssh"
+)
+
+const netconfDelim = "]]>]]>"
+
+var (
+	netconfMsgIDRe = regexp.MustCompile(`message-id="(\d+)"`)
+	netconfOpRe    = regexp.MustCompile(`<rpc[^>]*>\s*<([\w-]+)`)
+)
+
+// netconfServerStub is a NETCONF 1.0 over SSH server recording the received operations.
+type netconfServerStub struct {
+	// failOp is the operation replied with an rpc-error
+	failOp string
+	// running is the running configuration returned by get-config
+	running string
+
+	m   sync.Mutex
+	ops []string
+	// configs are the configs received with edit-config
+	configs []string
+}
+
+func (s *netconfServerStub) operations() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string{}, s.ops...)
+}
+
+// start starts the stub server and returns its port.
+func (s *netconfServerStub) start(t *testing.T) int {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "admin" && string(pass) == "admin" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("invalid credentials")
+		},
+	}
+	cfg.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn, cfg)
+		}
+	}()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func (s *netconfServerStub) serveConn(conn net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		ch, reqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range reqs {
+				// the payload of the subsystem request is the ssh string of the subsystem name
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "netconf"
+				_ = req.Reply(ok, nil)
+				if ok {
+					go s.serveNetconf(ch)
+				}
+			}
+		}()
+	}
+}
+
+func (s *netconfServerStub) serveNetconf(ch ssh.Channel) {
+	defer ch.Close()
+
+	fmt.Fprint(ch, `<?xml version="1.0" encoding="UTF-8"?>
+<hello xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+  <capabilities>
+    <capability>urn:ietf:params:netconf:base:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:candidate:1.0</capability>
+    <capability>urn:ietf:params:netconf:capability:validate:1.0</capability>
+  </capabilities>
+  <session-id>1</session-id>
+</hello>`+netconfDelim)
+
+	r := bufio.NewReader(ch)
+	hello := true
+	for {
+		msg, err := readNetconfMsg(r)
+		if err != nil {
+			return
+		}
+		// skip the client hello
+		if hello {
+			hello = false
+			continue
+		}
+
+		id := ""
+		if m := netconfMsgIDRe.FindStringSubmatch(msg); m != nil {
+			id = m[1]
+		}
+		op := ""
+		if m := netconfOpRe.FindStringSubmatch(msg); m != nil {
+			op = m[1]
+		}
+
+		s.m.Lock()
+		s.ops = append(s.ops, op)
+		if op == "edit-config" {
+			s.configs = append(s.configs, msg)
+		}
+		s.m.Unlock()
+
+		reply := "<ok/>"
+		switch {
+		case op == s.failOp:
+			reply = `<rpc-error><error-type>application</error-type><error-tag>operation-failed</error-tag>` +
+				`<error-severity>error</error-severity><error-message>stub failure</error-message></rpc-error>`
+		case op == "get-config":
+			reply = "<data>" + s.running + "</data>"
+		}
+
+		fmt.Fprintf(ch, `<?xml version="1.0" encoding="UTF-8"?>
+<rpc-reply xmlns="urn:ietf:params:xml:ns:netconf:base:1.0" message-id="%s">%s</rpc-reply>%s`, id, reply, netconfDelim)
+
+		if op == "close-session" {
+			return
+		}
+	}
+}
+
+// readNetconfMsg reads a NETCONF 1.0 message terminated by the delimiter.
+func readNetconfMsg(r *bufio.Reader) (string, error) {
+	var b bytes.Buffer
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(c)
+		if bytes.HasSuffix(b.Bytes(), []byte(netconfDelim)) {
+			return strings.TrimSuffix(b.String(), netconfDelim), nil
+		}
+	}
+}
+
+func newTestNetconfTransport(t *testing.T, port int) *NetconfTransport {
+	t.Helper()
+
+	tx, err := NewNetconfTransport(&types.NodeConfig{Kind: "vr-sros"},
+		WithNetconfCredentials("admin", "admin"),
+		WithNetconfPort(port),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+func TestNetconfTransportWrite(t *testing.T) {
+	cfg := `<config><configure xmlns="urn:nokia.com:sros:ns:yang:sr:conf"><system><name>sros1</name></system></configure></config>`
+
+	tests := map[string]struct {
+		failOp  string
+		wantErr bool
+		wantOps []string
+	}{
+		"commit": {
+			wantOps: []string{"lock", "edit-config", "validate", "commit", "unlock"},
+		},
+		"rollback on validate error": {
+			failOp:  "validate",
+			wantErr: true,
+			wantOps: []string{"lock", "edit-config", "validate", "discard-changes", "unlock"},
+		},
+		"rollback on commit error": {
+			failOp:  "commit",
+			wantErr: true,
+			wantOps: []string{"lock", "edit-config", "validate", "commit", "discard-changes", "unlock"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stub := &netconfServerStub{failOp: tc.failOp}
+			tx := newTestNetconfTransport(t, stub.start(t))
+
+			err := Write(tx, "127.0.0.1", []string{cfg}, []string{"base__vr-sros.tmpl"})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			ops := stub.operations()
+			// the session may be closed before the server registers close-session
+			if n := len(ops); n > 0 && ops[n-1] == "close-session" {
+				ops = ops[:n-1]
+			}
+			if d := cmp.Diff(tc.wantOps, ops); d != "" {
+				t.Errorf("operations mismatch (-want +got):\n%s", d)
+			}
+
+			if len(stub.configs) != 1 || !strings.Contains(stub.configs[0], "<name>sros1</name>") {
+				t.Errorf("expected edit-config with the config, got %v", stub.configs)
+			}
+		})
+	}
+}
+
+func TestNetconfTransportRunningConfig(t *testing.T) {
+	stub := &netconfServerStub{running: "<configure><system><name>sros1</name></system></configure>"}
+	tx := newTestNetconfTransport(t, stub.start(t))
+
+	got, err := Read(tx, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(got, "<name>sros1</name>") {
+		t.Errorf("expected running config to contain the system name, got %q", got)
+	}
+}
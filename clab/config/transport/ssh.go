@@ -84,29 +84,37 @@ func HostKeyCallback(callback ...ssh.HostKeyCallback) SSHTransportOption {
 	}
 }
 
+// sshKinds are the kind specific SSH transactions & prompt checking implementations.
+var sshKinds = map[string]func() SSHKind{
+	"vr-sros": func() SSHKind { return &VrSrosSSHKind{} },
+	"srl":     func() SSHKind { return &SrlSSHKind{} },
+}
+
+// SSHKindSupported returns true when the SSH transport is implemented for the kind.
+func SSHKindSupported(kind string) bool {
+	_, ok := sshKinds[kind]
+	return ok
+}
+
 func NewSSHTransport(node *types.NodeConfig, options ...SSHTransportOption) (*SSHTransport, error) {
-	switch node.Kind {
-	case "vr-sros", "srl":
-		c := &SSHTransport{}
-		c.SSHConfig = &ssh.ClientConfig{}
-
-		// apply options
-		for _, opt := range options {
-			err := opt(c)
-			if err != nil {
-				return nil, err
-			}
-		}
+	newK, ok := sshKinds[node.Kind]
+	if !ok {
+		return nil, fmt.Errorf("no transport implemented for kind: %s", node.Kind)
+	}
+
+	c := &SSHTransport{}
+	c.SSHConfig = &ssh.ClientConfig{}
 
-		switch node.Kind {
-		case "vr-sros":
-			c.K = &VrSrosSSHKind{}
-		case "srl":
-			c.K = &SrlSSHKind{}
+	// apply options
+	for _, opt := range options {
+		err := opt(c)
+		if err != nil {
+			return nil, err
 		}
-		return c, nil
 	}
-	return nil, fmt.Errorf("no transport implemented for kind: %s", node.Kind)
+
+	c.K = newK()
+	return c, nil
 }
 
 // InChannel creates the channel reading the SSH connection.
@@ -232,42 +240,88 @@ func (t *SSHTransport) Run(command string, timeout int) *SSHReply {
 // Session NEEDS to be configurable for other kinds
 // Part of the Transport interface.
 func (t *SSHTransport) Write(data, info *string) error {
-	if *data == "" {
-		return nil
+	_, err := t.Send(*data, *info)
+	return err
+}
+
+// SendError is the error of a config snippet failed to be committed.
+type SendError struct {
+	// Rejected are the lines of the snippet the node responded to with an output, followed by the output
+	Rejected []string
+	Err      error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Send a config snippet (a set of commands) and return the output of the commands
+// The snippet is committed as a transaction, unless info starts with "show-".
+func (t *SSHTransport) Send(data, info string) (string, error) {
+	if data == "" {
+		return "", nil
 	}
 
-	transaction := !strings.HasPrefix(*info, "show-")
+	transaction := !strings.HasPrefix(info, "show-")
 
 	err := t.K.ConfigStart(t, transaction)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	c := 0
+	var out strings.Builder
+	var rejected []string
 
-	for _, l := range strings.Split(*data, "\n") {
+	for _, l := range strings.Split(data, "\n") {
 		l = strings.TrimSpace(l)
 		if l == "" || strings.HasPrefix(l, "#") {
 			continue
 		}
 		c += 1
-		t.Run(l, 5).Info(t.Target)
+		r := t.Run(l, 5).Info(t.Target)
+		r.write(&out)
+		// the configuration lines are accepted silently, the output is an error or a warning
+		if r.result != "" {
+			rejected = append(rejected, fmt.Sprintf("%s: %s", l, strings.TrimSpace(r.result)))
+		}
 	}
 
 	if transaction {
 		commit, err := t.K.ConfigCommit(t)
-		msg := fmt.Sprintf("%s COMMIT - %d lines", *info, c)
+		commit.write(&out)
+		msg := fmt.Sprintf("%s COMMIT - %d lines", info, c)
 		if commit.result != "" {
 			msg += commit.LogString(t.Target, true, false)
 		}
 		if err != nil {
 			log.Error(msg)
-			return err
+			return out.String(), &SendError{Rejected: rejected, Err: err}
 		}
 		log.Info(msg)
 	}
 
-	return nil
+	return out.String(), nil
+}
+
+// RunningConfig reads the running configuration with the kind specific show command
+// Part of the ConfigReader interface.
+func (t *SSHTransport) RunningConfig() (string, error) {
+	err := t.K.ConfigStart(t, false)
+	if err != nil {
+		return "", err
+	}
+
+	r := t.Run(t.K.RunningConfigCmd(), 30)
+	if r.prompt == "" {
+		return "", fmt.Errorf("timeout reading the running configuration")
+	}
+
+	return r.result, nil
 }
 
 // Connect to a host
@@ -396,6 +450,14 @@ func (r *SSHReply) LogString(node string, linefeed, debug bool) string { // skip
 	return s
 }
 
+// write the command and its result.
+func (r *SSHReply) write(w io.Writer) {
+	fmt.Fprintln(w, r.command)
+	if r.result != "" {
+		fmt.Fprintln(w, r.result)
+	}
+}
+
 func (r *SSHReply) Info(node string) *SSHReply {
 	if r.result == "" {
 		return r
@@ -21,6 +21,8 @@ type SSHKind interface {
 	// A default implementation is promptParseNoSpaces, which simply ensures there are
 	// no spaces between the start of the line and the #
 	PromptParse(s *SSHTransport, in *string) *SSHReply
+	// Command to show the running configuration
+	RunningConfigCmd() string
 }
 
 // VrSrosSSHKind implements SShKind.
@@ -61,6 +63,10 @@ func (*VrSrosSSHKind) PromptParse(s *SSHTransport, in *string) *SSHReply {
 	return nil
 }
 
+func (*VrSrosSSHKind) RunningConfigCmd() string {
+	return "admin show configuration"
+}
+
 // SrlSSHKind implements SShKind.
 type SrlSSHKind struct{}
 
@@ -92,6 +98,10 @@ func (*SrlSSHKind) PromptParse(s *SSHTransport, in *string) *SSHReply {
 	return promptParseNoSpaces(in, s.PromptChar, 2)
 }
 
+func (*SrlSSHKind) RunningConfigCmd() string {
+	return "info from running"
+}
+
 // This is a helper function to parse the prompt, and can be used by SSHKind's ParsePrompt
 // Used in SRL today.
 func promptParseNoSpaces(in *string, promptChar string, lines int) *SSHReply {
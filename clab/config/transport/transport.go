@@ -17,6 +17,13 @@ type Transport interface {
 	Close()
 }
 
+// ConfigReader is a Transport that can read the running configuration of a node.
+type ConfigReader interface {
+	Transport
+	// Read the running configuration
+	RunningConfig() (string, error)
+}
+
 // Write config to a node.
 func Write(tx Transport, host string, data, info []string, options ...TransportOption) error {
 	// the Kind should configure the transport parameters before
@@ -37,3 +44,15 @@ func Write(tx Transport, host string, data, info []string, options ...TransportO
 
 	return nil
 }
+
+// Read the running config of a node.
+func Read(tx ConfigReader, host string, options ...TransportOption) (string, error) {
+	err := tx.Connect(host, options...)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", host, err)
+	}
+
+	defer tx.Close()
+
+	return tx.RunningConfig()
+}
@@ -24,10 +24,12 @@ const (
 	vkKind           = "clab_kind"            // reserved, will contain the node kind
 	vkType           = "clab_type"            // reserved, will contain the node type
 
-	vkSystemIP = "clab_system_ip" // optional, system IP if present could be used to calc link IPs
-	vkLinkIP   = "clab_link_ip"   // optional, link IP
-	vkLinkName = "clab_link_name" // optional, from ShortNames
-	vkLinkNum  = "clab_link_num"  // optional, link number in case you have multiple, used to calculate the name
+	vkSystemIP   = "clab_system_ip"   // optional, system IP if present could be used to calc link IPs, defaults to the IPAM loopback IPv4
+	vkSystemIPv6 = "clab_system_ipv6" // optional, defaults to the IPAM loopback IPv6
+	vkLinkIP     = "clab_link_ip"     // optional, link IP, defaults to the IPAM link IPv4
+	vkLinkIPv6   = "clab_link_ipv6"   // optional, defaults to the IPAM link IPv6
+	vkLinkName   = "clab_link_name"   // optional, from ShortNames
+	vkLinkNum    = "clab_link_num"    // optional, link number in case you have multiple, used to calculate the name
 )
 
 type Dict map[string]interface{}
@@ -46,6 +48,12 @@ func PrepareVars(nodes map[string]nodes.Node, links map[int]*types.Link) map[str
 		vars[vkManagementIPv4] = nodeCfg.MgmtIPv4Address
 		vars[vkManagementIPv6] = nodeCfg.MgmtIPv6Address
 		vars[vkType] = nodeCfg.NodeType
+		if nodeCfg.LoopbackIPv4 != "" {
+			vars[vkSystemIP] = nodeCfg.LoopbackIPv4
+		}
+		if nodeCfg.LoopbackIPv6 != "" {
+			vars[vkSystemIPv6] = nodeCfg.LoopbackIPv6
+		}
 
 		// Init array for this node
 		for key, val := range nodeCfg.Config.Vars {
@@ -72,6 +80,10 @@ func PrepareVars(nodes map[string]nodes.Node, links map[int]*types.Link) map[str
 
 	// prepare all links
 	for lIdx, link := range links {
+		// the variables describe point-to-point links between the nodes
+		if !link.IsVeth() {
+			continue
+		}
 		varsA := make(Dict)
 		varsB := make(Dict)
 		err := prepareLinkVars(link, varsA, varsB)
@@ -143,6 +155,18 @@ func prepareLinkVars(link *types.Link, varsA, varsB Dict) error {
 		addValues(k, v, v)
 	}
 
+	// Add the addresses allocated by IPAM if they are not present
+	ipamIPs := map[string][2]string{
+		vkLinkIP:   {link.A.IPv4, link.B.IPv4},
+		vkLinkIPv6: {link.A.IPv6, link.B.IPv6},
+	}
+	for k, ips := range ipamIPs {
+		if _, ok := varsA[k]; ok || ips[0] == "" {
+			continue
+		}
+		addValues(k, ips[0], ips[1])
+	}
+
 	// Add additional values if they are not present
 	add := map[string]func(link *types.Link) (string, string, error){
 		vkLinkIP:   linkIP,
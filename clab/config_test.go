@@ -13,6 +13,7 @@ import (
 
 	"github.com/containers/podman/v4/pkg/util"
 	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
 	"github.com/srl-labs/containerlab/utils"
 )
 
@@ -446,6 +447,12 @@ func TestLabelsInit(t *testing.T) {
 
 			labels := c.Nodes[tc.node].Config().Labels
 
+			// config hash depends on the resolved paths, thus only its presence is checked
+			if len(labels[NodeConfigHashLabel]) != 64 {
+				t.Errorf("failed at '%s', expected a config hash label, got %q", name, labels[NodeConfigHashLabel])
+			}
+			tc.want[NodeConfigHashLabel] = labels[NodeConfigHashLabel]
+
 			if !cmp.Equal(labels, tc.want) {
 				t.Errorf("failed at '%s', expected\n%v, got\n%+v", name, tc.want, labels)
 			}
@@ -481,6 +488,229 @@ func TestVerifyRootNetnsInterfaceUniqueness(t *testing.T) {
 	t.Logf("error: %v", err)
 }
 
+func TestVerifyRootNetnsInterfaceUniquenessLinkTypes(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep := func(node, intf string) *types.Endpoint {
+		n := specialEndpointNode(node)
+		if n == nil {
+			n = c.Nodes[node].Config()
+		}
+		return &types.Endpoint{Node: n, EndpointName: intf}
+	}
+	veth := func(a, b *types.Endpoint) *types.Link {
+		return &types.Link{Type: types.LinkTypeVeth, A: a, B: b}
+	}
+	macvlan := func(a, b *types.Endpoint) *types.Link {
+		return &types.Link{Type: types.LinkTypeMacvlan, A: a, B: b}
+	}
+	hostDevice := func(a, b *types.Endpoint) *types.Link {
+		return &types.Link{Type: types.LinkTypeHostDevice, A: a, B: b}
+	}
+	dummy := func(a *types.Endpoint) *types.Link {
+		return &types.Link{Type: types.LinkTypeDummy, A: a}
+	}
+
+	tests := map[string]struct {
+		links   []*types.Link
+		wantErr bool
+	}{
+		"macvlan_links_sharing_parent": {
+			links: []*types.Link{
+				macvlan(ep("lin1", "eth1"), ep("host", "eth0")),
+				macvlan(ep("lin2", "eth1"), ep("host", "eth0")),
+			},
+		},
+		"macvlan_parent_used_by_veth": {
+			links: []*types.Link{
+				macvlan(ep("lin1", "eth1"), ep("host", "eth0")),
+				veth(ep("lin2", "eth1"), ep("host", "eth0")),
+			},
+			wantErr: true,
+		},
+		"macvlan_parent_used_by_host_device": {
+			links: []*types.Link{
+				macvlan(ep("lin1", "eth1"), ep("host", "eth0")),
+				hostDevice(ep("lin2", "eth1"), ep("host", "eth0")),
+			},
+			wantErr: true,
+		},
+		"host_device_moved_twice": {
+			links: []*types.Link{
+				hostDevice(ep("lin1", "eth1"), ep("host", "eth0")),
+				hostDevice(ep("lin2", "eth1"), ep("host", "eth0")),
+			},
+			wantErr: true,
+		},
+		"dummy_links": {
+			links: []*types.Link{
+				dummy(ep("lin1", "dummy0")),
+				dummy(ep("lin2", "dummy0")),
+				veth(ep("lin1", "eth1"), ep("host", "lin1-eth1")),
+			},
+		},
+		"dummy_in_host_netns_used_by_veth": {
+			links: []*types.Link{
+				dummy(ep("host", "dummy0")),
+				veth(ep("lin1", "eth1"), ep("host", "dummy0")),
+			},
+			wantErr: true,
+		},
+		"bridge_interface_used_by_host": {
+			links: []*types.Link{
+				veth(ep("lin1", "eth1"), ep("br1", "eth1")),
+				veth(ep("lin2", "eth1"), ep("host", "eth1")),
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c.Links = map[int]*types.Link{}
+			for i, l := range tt.links {
+				c.Links[i] = l
+			}
+
+			err := c.verifyRootNetnsInterfaceUniqueness()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyRootNetnsInterfaceUniqueness() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewLinkTypes(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ec := types.NewEndpointConfig
+
+	tests := map[string]struct {
+		lc          *types.LinkConfig
+		wantType    string
+		wantA       string
+		wantB       string
+		wantMTU     int
+		wantMode    string
+		wantVxlanID int
+		wantErr     bool
+	}{
+		"veth": {
+			lc:       &types.LinkConfig{Endpoints: []*types.EndpointConfig{ec("lin1", "eth5"), ec("lin2", "eth5")}},
+			wantType: types.LinkTypeVeth,
+			wantA:    "lin1:eth5",
+			wantB:    "lin2:eth5",
+			wantMTU:  DefaultVethLinkMTU,
+		},
+		"macvlan_host_endpoint_first": {
+			lc: &types.LinkConfig{
+				Type:      types.LinkTypeMacvlan,
+				Endpoints: []*types.EndpointConfig{ec("host", "eth0"), ec("lin1", "eth5")},
+			},
+			wantType: types.LinkTypeMacvlan,
+			wantA:    "lin1:eth5",
+			wantB:    "host:eth0",
+			wantMode: types.MacvlanModeBridge,
+		},
+		"macvlan_mode": {
+			lc: &types.LinkConfig{
+				Type:      types.LinkTypeMacvlan,
+				Mode:      types.MacvlanModePrivate,
+				Endpoints: []*types.EndpointConfig{ec("lin1", "eth5"), ec("host", "eth0")},
+			},
+			wantType: types.LinkTypeMacvlan,
+			wantA:    "lin1:eth5",
+			wantB:    "host:eth0",
+			wantMode: types.MacvlanModePrivate,
+		},
+		"host_device": {
+			lc: &types.LinkConfig{
+				Type:      types.LinkTypeHostDevice,
+				Endpoints: []*types.EndpointConfig{ec("host", "enp1s0"), ec("lin1", "eth5")},
+			},
+			wantType: types.LinkTypeHostDevice,
+			wantA:    "lin1:eth5",
+			wantB:    "host:enp1s0",
+		},
+		"vxlan": {
+			lc: &types.LinkConfig{
+				Endpoints: []*types.EndpointConfig{ec(types.VxlanNodeName, "remote=10.0.0.2,vni=100"), ec("lin1", "eth5")},
+			},
+			wantType:    types.LinkTypeVxlan,
+			wantA:       "lin1:eth5",
+			wantB:       types.VxlanNodeName + ":remote=10.0.0.2,vni=100",
+			wantVxlanID: 100,
+		},
+		"dummy": {
+			lc: &types.LinkConfig{
+				Type:      types.LinkTypeDummy,
+				Endpoints: []*types.EndpointConfig{ec("lin1", "dummy0")},
+			},
+			wantType: types.LinkTypeDummy,
+			wantA:    "lin1:dummy0",
+			wantMTU:  DefaultVethLinkMTU,
+		},
+		"macvlan_without_host_endpoint": {
+			lc: &types.LinkConfig{
+				Type:      types.LinkTypeMacvlan,
+				Endpoints: []*types.EndpointConfig{ec("lin1", "eth5"), ec("lin2", "eth5")},
+			},
+			wantErr: true,
+		},
+		"unknown_type": {
+			lc: &types.LinkConfig{
+				Type:      "tap",
+				Endpoints: []*types.EndpointConfig{ec("lin1", "eth5"), ec("lin2", "eth5")},
+			},
+			wantErr: true,
+		},
+	}
+
+	epString := func(e *types.Endpoint) string {
+		if e == nil {
+			return ""
+		}
+		return e.Node.ShortName + ":" + e.EndpointName
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := c.NewLink(tt.lc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if l.Type != tt.wantType {
+				t.Errorf("expected link type %q, got %q", tt.wantType, l.Type)
+			}
+			if a, b := epString(l.A), epString(l.B); a != tt.wantA || b != tt.wantB {
+				t.Errorf("expected endpoints %q and %q, got %q and %q", tt.wantA, tt.wantB, a, b)
+			}
+			if l.MTU != tt.wantMTU {
+				t.Errorf("expected mtu %d, got %d", tt.wantMTU, l.MTU)
+			}
+			if l.MacvlanMode != tt.wantMode {
+				t.Errorf("expected macvlan mode %q, got %q", tt.wantMode, l.MacvlanMode)
+			}
+			switch {
+			case tt.wantVxlanID == 0 && l.Vxlan != nil:
+				t.Errorf("expected no vxlan parameters, got %+v", l.Vxlan)
+			case tt.wantVxlanID != 0 && (l.Vxlan == nil || l.Vxlan.VNI != tt.wantVxlanID):
+				t.Errorf("expected vxlan vni %d, got %+v", tt.wantVxlanID, l.Vxlan)
+			}
+		})
+	}
+}
+
 func TestEnvFileInit(t *testing.T) {
 	tests := map[string]struct {
 		got  string
@@ -531,3 +761,64 @@ func TestEnvFileInit(t *testing.T) {
 		})
 	}
 }
+
+func TestLinkParseErrors(t *testing.T) {
+	tests := map[string]struct {
+		links string
+		want  string
+	}{
+		"malformed string endpoint": {
+			links: `
+    - endpoints: ["n1:eth1", "n2:eth1"]
+    - endpoints: ["n1:eth2", "n2-eth2"]
+`,
+			want: `topo.yml:10: endpoint "n2-eth2" has wrong syntax, expected "node:interface"`,
+		},
+		"unknown node in map endpoint": {
+			links: `
+    - endpoints:
+        - node: n1
+          interface: eth1
+        - node: n3
+          interface: eth1
+`,
+			want: `topo.yml:12: endpoint "n3:eth1" refers to node "n3" which is not specified in the 'topology.nodes' section`,
+		},
+		"invalid endpoint attribute": {
+			links: `
+    - endpoints:
+        - {node: n1, interface: eth1, mtu: 10}
+        - n2:eth1
+`,
+			want: `topo.yml:10: endpoint "n1:eth1": mtu must be in the range of 68-65535, got 10`,
+		},
+		"single endpoint": {
+			links: `
+    - endpoints: ["n1:eth1"]
+`,
+			want: `topo.yml:9: link ["n1:eth1"] has wrong syntax, expected 2 endpoints, found 1`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			topo := `name: parse-errors
+topology:
+  nodes:
+    n1:
+      kind: linux
+    n2:
+      kind: linux
+  links:` + tc.links
+			fPath := filepath.Join(t.TempDir(), "topo.yml")
+			if err := os.WriteFile(fPath, []byte(topo), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := NewContainerLab(WithTopoFile(fPath, ""))
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("expected error %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
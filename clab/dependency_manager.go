@@ -1,99 +1,250 @@
 package clab
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
 )
 
 type DependencyManager interface {
 	// AddNode adds a node to the dependency manager.
 	AddNode(name string)
 	// AddDependency adds a dependency between depender and dependee.
-	// The depender will effectively wait for the dependee to finish.
+	// The depender will effectively wait for the dependee to be created.
 	AddDependency(dependee, depender string) error
-	// WaitForNodeDependencies is called by a node that is meant to be created.
-	// This call will bock until all the nodes that this node depends on are created.
-	WaitForNodeDependencies(nodeName string) error
-	// SignalDone is called by a node that has finished the creation process.
+	// AddStageDependency adds a dependency of the depender stage on the dependee reaching the dependee stage.
+	// When the timeout is not zero, the depender fails to wait for the dependee which doesn't reach the stage in time.
+	AddStageDependency(dependee, dependeeStage, depender, dependerStage string, timeout time.Duration) error
+	// HasDependers returns true when other nodes wait for the node to reach the stage.
+	HasDependers(nodeName, stage string) bool
+	// WaitForNodeDependencies is called by a node that is meant to reach the stage, e.g. to be created.
+	// This call will bock until all the nodes that this stage of the node depends on reach their stages.
+	// An error is returned when some of the nodes this node depends on failed to reach their stages in time.
+	WaitForNodeDependencies(ctx context.Context, nodeName, stage string) error
+	// SignalDone is called by a node that has reached the stage.
 	// internally the dependent nodes will be "notified" that an additional (if multiple exist) dependency is satisfied.
-	SignalDone(nodeName string)
+	SignalDone(nodeName, stage string)
+	// SignalFailed is called by a node that failed to reach the stage.
+	// The dependent nodes stop waiting and fail to wait for their dependencies.
+	// A node failed to be created fails all its stages.
+	SignalFailed(nodeName, stage string)
 	// CheckAcyclicity checks if dependencies contain cycles.
 	CheckAcyclicity() error
 	// String returns a string representation of dependencies recorded with dependency manager.
 	String() string
 }
 
+// nodeStage is a stage of the node lifecycle.
+type nodeStage struct {
+	// done is closed when the node reaches or fails to reach the stage
+	done chan struct{}
+	// failed is set before done is closed when the node fails to reach the stage
+	failed bool
+}
+
+// stageDependency is a dependency on a node reaching a stage.
+type stageDependency struct {
+	dependee string
+	stage    string
+	timeout  time.Duration
+}
+
 type defaultDependencyManager struct {
-	// map of wait group per node.
-	// The scheduling of the nodes creation is dependent on their respective wait group.
-	// Other nodes, that the specific node relies on will increment this wait group.
-	nodeWaitGroup map[string]*sync.WaitGroup
-	// Names of the nodes that depend on a given node are listed here.
-	// On successful creation of the said node, all the depending nodes (dependers) wait groups will be decremented.
-	nodeDependers map[string][]string
+	// m protects the closing of the stages.
+	m sync.Mutex
+	// stages of the nodes keyed by the node name and the stage.
+	// The scheduling of the nodes creation waits for the stages the node depends on.
+	stages map[string]map[string]*nodeStage
+	// dependencies of the node stages keyed by the depender name and its stage.
+	dependencies map[string]map[string][]*stageDependency
 }
 
 func NewDependencyManager() DependencyManager {
 	return &defaultDependencyManager{
-		nodeWaitGroup: map[string]*sync.WaitGroup{},
-		nodeDependers: map[string][]string{},
+		stages:       map[string]map[string]*nodeStage{},
+		dependencies: map[string]map[string][]*stageDependency{},
 	}
 }
 
 // AddNode adds a node to the dependency manager.
 func (dm *defaultDependencyManager) AddNode(name string) {
-	dm.nodeWaitGroup[name] = &sync.WaitGroup{}
-	dm.nodeDependers[name] = []string{}
+	dm.stages[name] = map[string]*nodeStage{}
+	dm.dependencies[name] = map[string][]*stageDependency{}
+	for _, s := range types.WaitForStages {
+		dm.stages[name][s] = &nodeStage{done: make(chan struct{})}
+	}
 }
 
 // AddDependency adds a dependency between depender and dependee.
-// The depender will effectively wait for the dependee to finish.
+// The depender will effectively wait for the dependee to be created.
 func (dm *defaultDependencyManager) AddDependency(dependee, depender string) error {
-	// first check if the referenced nodes are known to the dm
-	if _, exists := dm.nodeWaitGroup[depender]; !exists {
+	return dm.AddStageDependency(dependee, types.WaitForStageCreate, depender, types.WaitForStageCreate, 0)
+}
+
+// AddStageDependency adds a dependency of the depender stage on the dependee reaching the dependee stage.
+// When the timeout is not zero, the depender fails to wait for the dependee which doesn't reach the stage in time.
+func (dm *defaultDependencyManager) AddStageDependency(dependee, dependeeStage, depender, dependerStage string,
+	timeout time.Duration,
+) error {
+	// first check if the referenced nodes and stages are known to the dm
+	if _, exists := dm.stages[depender]; !exists {
 		return fmt.Errorf("node %q is not known to the dependency manager", depender)
 	}
-	if _, exists := dm.nodeDependers[dependee]; !exists {
+	if _, exists := dm.stages[dependee]; !exists {
 		return fmt.Errorf("node %q is not known to the dependency manager", dependee)
 	}
-	// increase the WaitGroup by one for the depender
-	dm.nodeWaitGroup[depender].Add(1)
-	// add a depender node name for a given dependee
-	dm.nodeDependers[dependee] = append(dm.nodeDependers[dependee], depender)
+	if _, exists := dm.stages[dependee][dependeeStage]; !exists {
+		return fmt.Errorf("stage %q is not known to the dependency manager", dependeeStage)
+	}
+	if _, exists := dm.stages[depender][dependerStage]; !exists {
+		return fmt.Errorf("stage %q is not known to the dependency manager", dependerStage)
+	}
+
+	dm.dependencies[depender][dependerStage] = append(dm.dependencies[depender][dependerStage],
+		&stageDependency{dependee: dependee, stage: dependeeStage, timeout: timeout})
+
 	return nil
 }
 
-// WaitForNodeDependencies is called by a node that is meant to be created.
-// This call will bock until all the nodes that this node depends on are created.
-func (dm *defaultDependencyManager) WaitForNodeDependencies(nodeName string) error {
+// HasDependers returns true when other nodes wait for the node to reach the stage.
+func (dm *defaultDependencyManager) HasDependers(nodeName, stage string) bool {
+	for _, stages := range dm.dependencies {
+		for _, deps := range stages {
+			for _, d := range deps {
+				if d.dependee == nodeName && d.stage == stage {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// WaitForNodeDependencies is called by a node that is meant to reach the stage, e.g. to be created.
+// This call will bock until all the nodes that this stage of the node depends on reach their stages.
+// The timeouts of the dependencies count from the moment the node starts to wait.
+func (dm *defaultDependencyManager) WaitForNodeDependencies(ctx context.Context, nodeName, stage string) error {
 	// first check if the referenced node is known to the dm
-	if _, exists := dm.nodeWaitGroup[nodeName]; !exists {
+	if _, exists := dm.stages[nodeName]; !exists {
 		return fmt.Errorf("node %q is not known to the dependency manager", nodeName)
 	}
-	dm.nodeWaitGroup[nodeName].Wait()
+
+	start := time.Now()
+	for _, d := range dm.dependencies[nodeName][stage] {
+		st := dm.stages[d.dependee][d.stage]
+
+		var timeout <-chan time.Time
+		if d.timeout > 0 {
+			timer := time.NewTimer(d.timeout - time.Since(start))
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		// the stage reached before the timeout expired wins over the timeout
+		select {
+		case <-st.done:
+		default:
+			select {
+			case <-st.done:
+			case <-timeout:
+				return fmt.Errorf("node %q timed out after %s waiting for node %q to reach the %s stage",
+					nodeName, d.timeout, d.dependee, d.stage)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if st.failed {
+			return fmt.Errorf("node %q depends on node %q, which failed to reach the %s stage",
+				nodeName, d.dependee, d.stage)
+		}
+	}
+
 	return nil
 }
 
-// SignalDone is called by a node that has finished the creation process.
+// SignalDone is called by a node that has reached the stage.
 // internally the dependent nodes will be "notified" that an additional (if multiple exist) dependency is satisfied.
-func (dm *defaultDependencyManager) SignalDone(nodeName string) {
+func (dm *defaultDependencyManager) SignalDone(nodeName, stage string) {
+	dm.closeStage(nodeName, stage, false)
+}
+
+// SignalFailed is called by a node that failed to reach the stage.
+// The dependent nodes stop waiting and fail to wait for their dependencies.
+// A node failed to be created fails all its stages.
+func (dm *defaultDependencyManager) SignalFailed(nodeName, stage string) {
+	if stage != types.WaitForStageCreate {
+		dm.closeStage(nodeName, stage, true)
+		return
+	}
+	for _, s := range types.WaitForStages {
+		dm.closeStage(nodeName, s, true)
+	}
+}
+
+// closeStage marks the stage of the node as reached or failed, unless it is already closed.
+func (dm *defaultDependencyManager) closeStage(nodeName, stage string, failed bool) {
 	// first check if the referenced node is known to the dm
-	if _, exists := dm.nodeDependers[nodeName]; !exists {
-		log.Errorf("tried to Signal Done for node %q but node is unknown to the DependencyManager", nodeName)
+	st, exists := dm.stages[nodeName][stage]
+	if !exists {
+		log.Errorf("tried to Signal stage %s for node %q but node is unknown to the DependencyManager", stage, nodeName)
 		return
 	}
-	for _, depender := range dm.nodeDependers[nodeName] {
-		dm.nodeWaitGroup[depender].Done()
+
+	dm.m.Lock()
+	defer dm.m.Unlock()
+
+	select {
+	case <-st.done:
+		return
+	default:
 	}
+	st.failed = failed
+	close(st.done)
 }
 
 // CheckAcyclicity checks if dependencies contain cycles.
+// The stages of the nodes are checked as separate vertices of the graph,
+// with the later stages of a node depending on its creation.
 func (dm *defaultDependencyManager) CheckAcyclicity() error {
 	log.Debugf("Dependencies:\n%s", dm.String())
-	if !isAcyclic(dm.nodeDependers, 1) {
+
+	// map of dependee stage vertices to their dependers
+	nodeDependers := map[string][]string{}
+	// addStage adds the stage of the node to the graph, the later stages follow the node creation
+	addStage := func(name, stage string) {
+		v := stageVertex(name, stage)
+		if _, ok := nodeDependers[v]; ok {
+			return
+		}
+		nodeDependers[v] = []string{}
+		if stage != types.WaitForStageCreate {
+			nodeDependers[name] = append(nodeDependers[name], v)
+		}
+	}
+
+	for name := range dm.stages {
+		addStage(name, types.WaitForStageCreate)
+	}
+	// the later stages are only added when they have dependencies, so the graph of the creation stays simple
+	for depender, stages := range dm.dependencies {
+		for s, deps := range stages {
+			for _, d := range deps {
+				addStage(depender, s)
+				addStage(d.dependee, d.stage)
+				dependee := stageVertex(d.dependee, d.stage)
+				nodeDependers[dependee] = append(nodeDependers[dependee], stageVertex(depender, s))
+			}
+		}
+	}
+
+	if !isAcyclic(nodeDependers, 1) {
 		return fmt.Errorf("cyclic dependencies found!\n%s", dm.String())
 	}
 
@@ -101,34 +252,42 @@ func (dm *defaultDependencyManager) CheckAcyclicity() error {
 }
 
 // String returns a string representation of dependencies recorded with dependency manager.
+// The stages other than the node creation are listed only when they have dependencies.
 func (dm *defaultDependencyManager) String() string {
-	// since dm.nodeDependers contains a map of dependee->[dependers] it is not
-	// particularly suitable for displaying the dependency graph
-	// this function reverses the order so that it becomes depender->[dependees]
+	// dm.dependencies already contains a map of depender->[dependees],
+	// which is suitable for displaying the dependency graph
 
-	// map to record the dependencies in string based representation
-	dependencies := map[string][]string{}
-
-	// prepare dependencies table
-	for name := range dm.nodeWaitGroup {
-		dependencies[name] = []string{}
-	}
-
-	// build the dependency datastruct
-	for dependee, dependers := range dm.nodeDependers {
-		for _, depender := range dependers {
-			dependencies[depender] = append(dependencies[depender], dependee)
+	result := []string{}
+	for name, stages := range dm.dependencies {
+		// the creation of the nodes is listed even without dependencies
+		if len(stages[types.WaitForStageCreate]) == 0 {
+			result = append(result, fmt.Sprintf("%s -> [  ]", name))
+		}
+		for s, deps := range stages {
+			if len(deps) == 0 {
+				continue
+			}
+			dependees := make([]string, 0, len(deps))
+			for _, d := range deps {
+				dependees = append(dependees, stageVertex(d.dependee, d.stage))
+			}
+			result = append(result, fmt.Sprintf("%s -> [ %s ]", stageVertex(name, s), strings.Join(dependees, ", ")))
 		}
 	}
+	sort.Strings(result)
 
-	result := []string{}
-	// print dependencies
-	for nodename, deps := range dependencies {
-		result = append(result, fmt.Sprintf("%s -> [ %s ]", nodename, strings.Join(deps, ", ")))
-	}
 	return strings.Join(result, "\n")
 }
 
+// stageVertex returns the name of the stage of the node in the dependency graph,
+// the creation stage is named after the node.
+func stageVertex(name, stage string) string {
+	if stage == types.WaitForStageCreate {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, stage)
+}
+
 // isAcyclic checks the provided dependencies map for cycles.
 // i indicates the check round. Must be set to 1.
 func isAcyclic(nodeDependers map[string][]string, i int) bool {
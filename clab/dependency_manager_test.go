@@ -1,7 +1,12 @@
 package clab
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/types"
 )
 
 func Test_recursiveAcyclicityCheck(t *testing.T) {
@@ -97,3 +102,98 @@ func Test_recursiveAcyclicityCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestDependencyManagerSignalFailed(t *testing.T) {
+	dm := NewDependencyManager()
+	for _, n := range []string{"node1", "node2", "node3"} {
+		dm.AddNode(n)
+	}
+	// node3 waits for node1 and node2
+	if err := dm.AddDependency("node1", "node3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.AddDependency("node2", "node3"); err != nil {
+		t.Fatal(err)
+	}
+
+	dm.SignalDone("node1", types.WaitForStageCreate)
+	dm.SignalFailed("node2", types.WaitForStageCreate)
+
+	err := dm.WaitForNodeDependencies(context.Background(), "node3", types.WaitForStageCreate)
+	if err == nil || !strings.Contains(err.Error(), "node2") || strings.Contains(err.Error(), "node1") {
+		t.Errorf("expected error naming the failed node2 dependency, got %v", err)
+	}
+
+	// the nodes without failed dependencies wait successfully
+	if err := dm.WaitForNodeDependencies(context.Background(), "node1", types.WaitForStageCreate); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDependencyManagerStages(t *testing.T) {
+	dm := NewDependencyManager()
+	for _, n := range []string{"spine1", "ixia", "client"} {
+		dm.AddNode(n)
+	}
+	// ixia waits for spine1 to become healthy, client waits for spine1 post-deploy actions with a timeout
+	if err := dm.AddStageDependency("spine1", types.WaitForStageHealthy, "ixia", types.WaitForStageCreate, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.AddStageDependency("spine1", types.WaitForStagePostDeploy, "client",
+		types.WaitForStageCreate, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.CheckAcyclicity(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !dm.HasDependers("spine1", types.WaitForStageHealthy) || dm.HasDependers("spine1", types.WaitForStageCreate) {
+		t.Errorf("unexpected dependers of spine1 stages")
+	}
+
+	waited := make(chan error)
+	go func() {
+		waited <- dm.WaitForNodeDependencies(context.Background(), "ixia", types.WaitForStageCreate)
+	}()
+
+	// the creation of spine1 doesn't release the nodes waiting for its later stages
+	dm.SignalDone("spine1", types.WaitForStageCreate)
+	select {
+	case err := <-waited:
+		t.Fatalf("ixia stopped waiting before spine1 became healthy: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	dm.SignalDone("spine1", types.WaitForStageHealthy)
+	if err := <-waited; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// spine1 never finishes its post-deploy actions
+	err := dm.WaitForNodeDependencies(context.Background(), "client", types.WaitForStageCreate)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestDependencyManagerStageCycle(t *testing.T) {
+	dm := NewDependencyManager()
+	for _, n := range []string{"node1", "node2"} {
+		dm.AddNode(n)
+	}
+	// node1 waits for node2 to become healthy
+	if err := dm.AddStageDependency("node2", types.WaitForStageHealthy, "node1", types.WaitForStageCreate, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.CheckAcyclicity(); err != nil {
+		t.Fatal(err)
+	}
+
+	// node2 becomes healthy only after it is created, which waits for node1 then
+	if err := dm.AddDependency("node1", "node2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dm.CheckAcyclicity(); err == nil {
+		t.Errorf("expected cycle across the stages of node1 and node2")
+	}
+}
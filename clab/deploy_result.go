@@ -0,0 +1,194 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// Deploy failure policies.
+const (
+	// OnErrorContinue deploys the rest of the lab when a node fails to deploy
+	OnErrorContinue = "continue"
+	// OnErrorAbort stops scheduling the nodes when a node fails to deploy, the created nodes are kept
+	OnErrorAbort = "abort"
+	// OnErrorRollback stops scheduling the nodes when a node fails to deploy and destroys the lab
+	OnErrorRollback = "rollback"
+)
+
+// Stages of the node deployment reported in the node errors.
+const (
+	StageCertificate   = "certificate"
+	StagePreDeployHook = "pre-deploy hook"
+	StagePreDeploy     = "pre-deploy"
+	StageDeploy        = "deploy"
+	StagePostCreate    = "post-create hook"
+	StagePostDeploy    = "post-deploy"
+	// StageDependency is reported for the nodes which are not deployed because their dependencies failed
+	StageDependency = "dependency"
+	// StageAborted is reported for the nodes which are not deployed because the deployment was aborted
+	StageAborted = "aborted"
+	// StageLink is reported for the links failed to be created
+	StageLink = "link"
+)
+
+// deploymentStatusFailed is the deployment status of the nodes failed to deploy.
+const deploymentStatusFailed = "failed"
+
+// errDeployAborted is the error of the nodes not deployed because the deployment was aborted.
+var errDeployAborted = errors.New("deployment aborted due to failures of other nodes")
+
+// ValidateOnErrorPolicy checks that the deploy failure policy is known.
+func ValidateOnErrorPolicy(p string) error {
+	switch p {
+	case OnErrorContinue, OnErrorAbort, OnErrorRollback:
+		return nil
+	}
+	return fmt.Errorf("invalid on-error policy %q, expected one of %s, %s or %s",
+		p, OnErrorContinue, OnErrorAbort, OnErrorRollback)
+}
+
+// NodeError is the error of a node failed to deploy.
+type NodeError struct {
+	Node  string
+	Kind  string
+	Stage string
+	Err   error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %q failed at %s stage: %v", e.Node, e.Stage, e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// LinkError is the error of a link failed to be created.
+type LinkError struct {
+	Link string
+	Type string
+	Err  error
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("%s failed to be created: %v", e.Link, e.Err)
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
+
+// DeployResult collects the errors of the nodes failed to deploy and of the links failed to be created.
+// It is safe for concurrent use.
+type DeployResult struct {
+	m        sync.Mutex
+	policy   string
+	errs     map[string]*NodeError
+	linkErrs []*LinkError
+}
+
+// NewDeployResult returns the deploy result of the deployment with the failure policy.
+func NewDeployResult(policy string) *DeployResult {
+	if policy == "" {
+		policy = OnErrorContinue
+	}
+	return &DeployResult{
+		policy: policy,
+		errs:   map[string]*NodeError{},
+	}
+}
+
+// Policy returns the failure policy of the deployment.
+func (r *DeployResult) Policy() string {
+	return r.policy
+}
+
+// AddNodeError records the error of node n failed at the stage.
+// Only the first error of a node is recorded.
+func (r *DeployResult) AddNodeError(n nodes.Node, stage string, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	name := n.Config().ShortName
+	if _, ok := r.errs[name]; ok {
+		return
+	}
+	r.errs[name] = &NodeError{Node: name, Kind: n.Config().Kind, Stage: stage, Err: err}
+}
+
+// AddLinkError records the error of the link l failed to be created.
+func (r *DeployResult) AddLinkError(l *types.Link, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.linkErrs = append(r.linkErrs, &LinkError{Link: l.String(), Type: l.Type, Err: err})
+}
+
+// Failed returns true when the node failed to deploy.
+func (r *DeployResult) Failed(name string) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	_, ok := r.errs[name]
+	return ok
+}
+
+// Aborted returns true when the nodes are no longer deployed, which is when a node or a link failed
+// and the failure policy is not to continue.
+func (r *DeployResult) Aborted() bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.policy != OnErrorContinue && (len(r.errs) != 0 || len(r.linkErrs) != 0)
+}
+
+// Errors returns the errors of the failed nodes sorted by the node names.
+func (r *DeployResult) Errors() []*NodeError {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	errs := make([]*NodeError, 0, len(r.errs))
+	for _, e := range r.errs {
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Node < errs[j].Node })
+
+	return errs
+}
+
+// LinkErrors returns the errors of the failed links sorted by the link names.
+func (r *DeployResult) LinkErrors() []*LinkError {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	errs := make([]*LinkError, len(r.linkErrs))
+	copy(errs, r.linkErrs)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Link < errs[j].Link })
+
+	return errs
+}
+
+// Err returns an error when some of the nodes failed to deploy or some of the links failed to be created.
+func (r *DeployResult) Err() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	switch {
+	case len(r.errs) == 0 && len(r.linkErrs) == 0:
+		return nil
+	case len(r.linkErrs) == 0:
+		return fmt.Errorf("%d nodes failed to deploy", len(r.errs))
+	case len(r.errs) == 0:
+		return fmt.Errorf("%d links failed to be created", len(r.linkErrs))
+	}
+	return fmt.Errorf("%d nodes failed to deploy and %d links failed to be created", len(r.errs), len(r.linkErrs))
+}
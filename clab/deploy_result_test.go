@@ -0,0 +1,83 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestDeployResultLinkErrors(t *testing.T) {
+	link := func(a, b string) *types.Link {
+		return &types.Link{
+			Type: types.LinkTypeVeth,
+			A:    &types.Endpoint{Node: &types.NodeConfig{ShortName: a}, EndpointName: "eth1"},
+			B:    &types.Endpoint{Node: &types.NodeConfig{ShortName: b}, EndpointName: "eth1"},
+		}
+	}
+
+	tests := map[string]struct {
+		policy      string
+		links       []*types.Link
+		wantAborted bool
+		wantErr     string
+	}{
+		"no_errors": {
+			policy: OnErrorAbort,
+		},
+		"continue": {
+			policy:  OnErrorContinue,
+			links:   []*types.Link{link("n2", "n3"), link("n1", "n2")},
+			wantErr: "2 links failed to be created",
+		},
+		"abort": {
+			policy:      OnErrorAbort,
+			links:       []*types.Link{link("n1", "n2")},
+			wantAborted: true,
+			wantErr:     "1 links failed to be created",
+		},
+		"rollback": {
+			policy:      OnErrorRollback,
+			links:       []*types.Link{link("n1", "n2")},
+			wantAborted: true,
+			wantErr:     "1 links failed to be created",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			res := NewDeployResult(tt.policy)
+			for _, l := range tt.links {
+				res.AddLinkError(l, errors.New("file exists"))
+			}
+
+			if got := res.Aborted(); got != tt.wantAborted {
+				t.Errorf("Aborted() = %v, want %v", got, tt.wantAborted)
+			}
+
+			err := res.Err()
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("Err() = %v, want %q", err, tt.wantErr)
+			}
+
+			errs := res.LinkErrors()
+			if len(errs) != len(tt.links) {
+				t.Fatalf("expected %d link errors, got %d", len(tt.links), len(errs))
+			}
+			for i := 1; i < len(errs); i++ {
+				if errs[i-1].Link > errs[i].Link {
+					t.Errorf("link errors are not sorted: %s before %s", errs[i-1].Link, errs[i].Link)
+				}
+			}
+			for _, e := range errs {
+				if e.Type != types.LinkTypeVeth || e.Err.Error() != "file exists" {
+					t.Errorf("unexpected link error %+v", e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// WithHost sets the host of a distributed lab the lab is deployed on.
+// Only the nodes placed on this host are deployed, and the links to the nodes
+// of other hosts are replaced with vxlan tunnels to these hosts.
+func WithHost(name string) ClabOption {
+	return func(c *CLab) error {
+		c.host = name
+		return nil
+	}
+}
+
+// verifyHosts checks the hosts of a distributed lab and the placement of the nodes on them.
+// The placement is only checked when the lab is deployed on one of the hosts.
+func (c *CLab) verifyHosts(nodeNames []string) error {
+	if c.host == "" {
+		return nil
+	}
+
+	hosts := c.Config.Topology.Hosts
+	if _, ok := hosts[c.host]; !ok {
+		return fmt.Errorf("host %q is not defined in the 'topology.hosts' section", c.host)
+	}
+
+	hostNames := make([]string, 0, len(hosts))
+	for name := range hosts {
+		hostNames = append(hostNames, name)
+	}
+	sort.Strings(hostNames)
+	for _, name := range hostNames {
+		if err := hosts[name].Validate(); err != nil {
+			return fmt.Errorf("host %q: %v", name, err)
+		}
+	}
+
+	for _, name := range nodeNames {
+		h := c.Config.Topology.GetNodeHost(name)
+		if h == "" {
+			return fmt.Errorf("node %q is not placed on any of the hosts of the 'topology.hosts' section", name)
+		}
+		if _, ok := hosts[h]; !ok {
+			return fmt.Errorf("node %q is placed on host %q which is not defined in the 'topology.hosts' section", name, h)
+		}
+	}
+
+	return nil
+}
+
+// nodeHost returns the host of the node, or an empty string for the nodes which are not defined in the topology,
+// such as the special endpoint nodes.
+func (c *CLab) nodeHost(name string) string {
+	if _, ok := c.Config.Topology.Nodes[name]; !ok {
+		return ""
+	}
+	return c.Config.Topology.GetNodeHost(name)
+}
+
+// isLocalNode returns true when the node is deployed on the host the lab is deployed on,
+// which is the case for all nodes of the labs that are not distributed.
+// The nodes which are not defined in the topology are local to the nodes they are linked with.
+func (c *CLab) isLocalNode(name string) bool {
+	h := c.nodeHost(name)
+	return c.host == "" || h == "" || h == c.host
+}
+
+// localWaitFor returns the dependencies on the local nodes, the remote ones are skipped with a warning.
+func (c *CLab) localWaitFor(wfs []*types.WaitFor) []*types.WaitFor {
+	local := make([]*types.WaitFor, 0, len(wfs))
+	for _, w := range wfs {
+		if !c.isLocalNode(w.Node) {
+			log.Warnf("node %q is deployed on host %q and is skipped", w.Node, c.nodeHost(w.Node))
+			continue
+		}
+		local = append(local, w)
+	}
+	return local
+}
+
+// isCrossHostLink returns true when l is a veth link between the nodes of different hosts.
+func (c *CLab) isCrossHostLink(l *types.LinkConfig) bool {
+	if l.LinkType() != types.LinkTypeVeth || len(l.Endpoints) != 2 || l.Endpoints[0] == nil || l.Endpoints[1] == nil {
+		return false
+	}
+	a, b := c.nodeHost(l.Endpoints[0].Node), c.nodeHost(l.Endpoints[1].Node)
+	return a != "" && b != "" && a != b
+}
+
+// crossHostLinkKey returns the key of a cross-host link, which doesn't depend on the order of its endpoints.
+func crossHostLinkKey(l *types.LinkConfig) string {
+	a, b := l.Endpoints[0].String(), l.Endpoints[1].String()
+	if b < a {
+		a, b = b, a
+	}
+	return a + "--" + b
+}
+
+// crossHostVNIs derives the VNIs of the tunnels of the cross-host links from the link endpoints,
+// so that the hosts on both ends of a link derive the same VNI independently.
+// The VNIs are keyed by the index of the link in the topology.
+// All the links of the topology are considered regardless of the local host,
+// VNIs set on the vxlan links are not derived, and the collisions are resolved
+// by taking the next free VNI in the order of the link keys.
+func (c *CLab) crossHostVNIs() map[int]int {
+	if c.host == "" {
+		return nil
+	}
+
+	used := map[int]bool{}
+	idx := []int{}
+	for i, l := range c.Config.Topology.Links {
+		if l == nil {
+			continue
+		}
+		if c.isCrossHostLink(l) {
+			idx = append(idx, i)
+			continue
+		}
+		if vi := l.VxlanEndpointIdx(); vi >= 0 {
+			if p, err := types.ParseVxlanParams(l.Endpoints[vi].Interface); err == nil && p.VNI != 0 {
+				used[p.VNI] = true
+			}
+		}
+	}
+
+	links := c.Config.Topology.Links
+	sort.SliceStable(idx, func(i, j int) bool {
+		return crossHostLinkKey(links[idx[i]]) < crossHostLinkKey(links[idx[j]])
+	})
+
+	vnis := make(map[int]int, len(idx))
+	for _, i := range idx {
+		vni := deriveVNI(crossHostLinkKey(links[i]))
+		for used[vni] {
+			vni = vni%types.MaxVxlanVNI + 1
+		}
+		used[vni] = true
+		vnis[i] = vni
+	}
+
+	return vnis
+}
+
+// deriveVNI returns a VNI derived from the hash of the link key.
+func deriveVNI(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key)) // nolint:errcheck
+	return int(h.Sum32()%types.MaxVxlanVNI) + 1
+}
+
+// localLinkConfig returns the part of the link l that is deployed on the local host:
+// the link itself when its nodes are local, a vxlan link from the local endpoint to the host of the remote one
+// when l is a cross-host link, and nil when the nodes of the link are deployed on other hosts.
+// vni is the VNI of the tunnel of the cross-host link.
+func (c *CLab) localLinkConfig(l *types.LinkConfig, vni int) *types.LinkConfig {
+	if c.host == "" || l == nil {
+		return l
+	}
+
+	if !c.isCrossHostLink(l) {
+		for _, e := range l.Endpoints {
+			if e != nil && !c.isLocalNode(e.Node) {
+				return nil
+			}
+		}
+		return l
+	}
+
+	local := 0
+	if !c.isLocalNode(l.Endpoints[0].Node) {
+		local = 1
+	}
+	if !c.isLocalNode(l.Endpoints[local].Node) {
+		return nil
+	}
+
+	// the addresses of the hosts are validated along with the hosts
+	remoteHost := c.Config.Topology.Hosts[c.nodeHost(l.Endpoints[1-local].Node)]
+	remote, _ := netip.ParseAddr(remoteHost.Address)
+	params := &types.VxlanParams{
+		Remote:  remote,
+		VNI:     vni,
+		UDPPort: types.DefaultVxlanUDPPort,
+		Dev:     c.Config.Topology.Hosts[c.host].Dev,
+	}
+
+	// the local endpoint keeps its position in the link, so that its errors are reported at the right place
+	ep := *l.Endpoints[local]
+	ep.EndpointAttrs = l.EndpointAttrs(local)
+	endpoints := make([]*types.EndpointConfig, 2)
+	endpoints[local] = &ep
+	endpoints[1-local] = types.NewEndpointConfig(types.VxlanNodeName, params.String())
+
+	log.Debugf("Link %s is replaced with a vxlan tunnel to %s with vni %d", crossHostLinkKey(l), remoteHost.Address, vni)
+
+	return &types.LinkConfig{
+		Type:           types.LinkTypeVxlan,
+		Endpoints:      endpoints,
+		Labels:         l.Labels,
+		Vars:           l.Vars,
+		LinkImpairment: l.LinkImpairment,
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDistributedLab(t *testing.T) {
+	topo := `name: distributed
+topology:
+  hosts:
+    a:
+      address: 10.0.0.1
+    b:
+      address: 10.0.0.2
+      dev: eth9
+  defaults:
+    host: b
+  nodes:
+    n1:
+      kind: linux
+      host: a
+    n2:
+      kind: linux
+    n3:
+      kind: linux
+  links:
+    - endpoints: ["n2:eth1", "n1:eth1"]
+    - endpoints: ["n2:eth2", "n3:eth2"]
+    - endpoints: ["n1:eth3", "n3:eth3"]
+`
+	fPath := filepath.Join(t.TempDir(), "topo.yml")
+	if err := os.WriteFile(fPath, []byte(topo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vni0 := deriveVNI("n1:eth1--n2:eth1")
+	vni2 := deriveVNI("n1:eth3--n3:eth3")
+
+	for host, want := range map[string]struct {
+		nodes []string
+		// links keyed by their index in the topology
+		links map[int]string
+	}{
+		"a": {
+			nodes: []string{"n1"},
+			links: map[int]string{
+				0: "n1:eth1 vxlan:remote=10.0.0.2,vni=" + strconv.Itoa(vni0) + ",udp-port=4789",
+				2: "n1:eth3 vxlan:remote=10.0.0.2,vni=" + strconv.Itoa(vni2) + ",udp-port=4789",
+			},
+		},
+		"b": {
+			nodes: []string{"n2", "n3"},
+			links: map[int]string{
+				0: "n2:eth1 vxlan:remote=10.0.0.1,vni=" + strconv.Itoa(vni0) + ",udp-port=4789,dev=eth9",
+				1: "n2:eth2 n3:eth2",
+				2: "n3:eth3 vxlan:remote=10.0.0.1,vni=" + strconv.Itoa(vni2) + ",udp-port=4789,dev=eth9",
+			},
+		},
+	} {
+		c, err := NewContainerLab(WithHost(host), WithTopoFile(fPath, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nodes := make([]string, 0, len(c.Nodes))
+		for n := range c.Nodes {
+			nodes = append(nodes, n)
+		}
+		sort.Strings(nodes)
+		if d := cmp.Diff(want.nodes, nodes); d != "" {
+			t.Errorf("host %s: nodes mismatch (-want +got):\n%s", host, d)
+		}
+
+		links := make(map[int]string, len(c.Links))
+		for i, l := range c.Links {
+			links[i] = l.A.Node.ShortName + ":" + l.A.EndpointName + " " + l.B.Node.ShortName + ":" + l.B.EndpointName
+		}
+		if d := cmp.Diff(want.links, links); d != "" {
+			t.Errorf("host %s: links mismatch (-want +got):\n%s", host, d)
+		}
+	}
+}
@@ -33,6 +33,8 @@ func ParseExecOutputFormat(s string) (string, error) {
 // ExecCmd represents an exec command.
 type ExecCmd struct {
 	Cmd []string `json:"cmd"` // Cmd is a slice-based representation of a string command.
+	// Stdin is written to the standard input of the command, which is closed afterwards
+	Stdin []byte `json:"-"`
 }
 
 // NewExecCmdFromString creates ExecCmd for a string-based command.
@@ -90,6 +92,16 @@ func (e *ExecCmd) GetCmd() []string {
 	return e.Cmd
 }
 
+// SetStdin sets the data written to the standard input of the command.
+func (e *ExecCmd) SetStdin(data []byte) {
+	e.Stdin = data
+}
+
+// GetStdin returns the data written to the standard input of the command.
+func (e *ExecCmd) GetStdin() []byte {
+	return e.Stdin
+}
+
 // GetCmdString sets the command that is to be executed.
 func (e *ExecCmd) GetCmdString() string {
 	return strings.Join(e.Cmd, " ")
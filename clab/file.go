@@ -21,6 +21,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/srl-labs/containerlab/utils"
 	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
 )
 
 const (
@@ -33,6 +34,8 @@ type TopoFile struct {
 	dir      string // topo file dir path
 	fullName string // file name with extension
 	name     string // file name without extension
+	// content is the rendered topology the lab configuration is unmarshalled from
+	content []byte
 }
 
 // GetDir returns the path of a directory that contains topology file.
@@ -96,13 +99,58 @@ func (c *CLab) GetTopology(topo, varsFile string) error {
 
 	c.Config.Topology.ImportEnvs()
 
-	c.TopoFile = &TopoFile{
-		path:     topoAbsPath,
-		dir:      topoDir,
+	c.TopoFile = newTopoFile(topoAbsPath)
+	c.TopoFile.content = yamlFile
+	return nil
+}
+
+// position returns the "file:line" position of the element of the topology file found by the path,
+// which consists of the map keys and the list indexes leading to the element.
+// When the element is not found, the position of its closest found parent is returned.
+func (tf *TopoFile) position(path ...interface{}) string {
+	var root yaml3.Node
+	if err := yaml3.Unmarshal(tf.content, &root); err != nil || len(root.Content) == 0 {
+		return tf.fullName
+	}
+
+	n := root.Content[0]
+	for _, p := range path {
+		var next *yaml3.Node
+		switch p := p.(type) {
+		case string:
+			if n.Kind != yaml3.MappingNode {
+				break
+			}
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == p {
+					next = n.Content[i+1]
+					break
+				}
+			}
+		case int:
+			if n.Kind == yaml3.SequenceNode && p < len(n.Content) {
+				next = n.Content[p]
+			}
+		}
+		if next == nil {
+			break
+		}
+		n = next
+	}
+
+	return fmt.Sprintf("%s:%d", tf.fullName, n.Line)
+}
+
+// newTopoFile returns TopoFile for the topology file path p.
+func newTopoFile(p string) *TopoFile {
+	fileBase := filepath.Base(p)
+
+	return &TopoFile{
+		path:     p,
+		dir:      filepath.Dir(p),
 		fullName: fileBase,
 		name:     strings.TrimSuffix(fileBase, path.Ext(fileBase)),
 	}
-	return nil
 }
 
 func readTemplateVariables(topo, varsFile string) (interface{}, error) {
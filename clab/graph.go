@@ -28,8 +28,12 @@ type GraphTopo struct {
 type Link struct {
 	Source         string `json:"source,omitempty"`
 	SourceEndpoint string `json:"source_endpoint,omitempty"`
+	SourceIPv4     string `json:"source_ipv4,omitempty"`
+	SourceIPv6     string `json:"source_ipv6,omitempty"`
 	Target         string `json:"target,omitempty"`
 	TargetEndpoint string `json:"target_endpoint,omitempty"`
+	TargetIPv4     string `json:"target_ipv4,omitempty"`
+	TargetIPv6     string `json:"target_ipv6,omitempty"`
 }
 
 type TopoData struct {
@@ -87,6 +91,10 @@ func (c *CLab) GenerateGraph(_ string) error {
 
 	// Process the links inbetween Nodes
 	for _, link := range c.Links {
+		// dummy links are not connected to anything
+		if link.B == nil {
+			continue
+		}
 		attr = make(map[string]string)
 		attr["color"] = "black"
 
@@ -94,6 +102,13 @@ func (c *CLab) GenerateGraph(_ string) error {
 			(strings.Contains(link.B.Node.ShortName, "client")) {
 			attr["color"] = "blue"
 		}
+		// label the ends of the edge with the addresses of the endpoints
+		if l := endpointAddrLabel(link.A); l != "" {
+			attr["taillabel"] = l
+		}
+		if l := endpointAddrLabel(link.B); l != "" {
+			attr["headlabel"] = l
+		}
 		if err := g.AddEdge(link.A.Node.ShortName, link.B.Node.ShortName, false, attr); err != nil {
 			return err
 		}
@@ -122,6 +137,21 @@ func (c *CLab) GenerateGraph(_ string) error {
 	return nil
 }
 
+// endpointAddrLabel returns the quoted dot label with the addresses of the endpoint e
+// or an empty string when the endpoint has no addresses.
+func endpointAddrLabel(e *types.Endpoint) string {
+	var addrs []string
+	for _, a := range []string{e.IPv4, e.IPv6} {
+		if a != "" {
+			addrs = append(addrs, a)
+		}
+	}
+	if len(addrs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%q", strings.Join(addrs, "\n"))
+}
+
 // generatePngFromDot generated PNG from the provided dot file.
 func generatePngFromDot(dotfile string, outfile string) (err error) {
 	_, err = exec.Command("dot", "-o", outfile, "-Tpng", dotfile).CombinedOutput()
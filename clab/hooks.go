@@ -0,0 +1,157 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// RunLabHooks runs the lab hooks of the stage with the lab context exported as environment variables.
+func (c *CLab) RunLabHooks(ctx context.Context, stage string) error {
+	return c.runHooks(ctx, c.Config.Hooks.Get(stage), stage, "lab "+c.Config.Name, c.labHookEnv(stage))
+}
+
+// RunNodeHooks runs the hooks of the stage of node n with the lab and the node context
+// exported as environment variables.
+func (c *CLab) RunNodeHooks(ctx context.Context, n nodes.Node, stage string) error {
+	cfg := n.Config()
+	hooks := cfg.Hooks.Get(stage)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	env := c.labHookEnv(stage)
+
+	c.m.RLock()
+	env = append(env,
+		"CLAB_NODE_NAME="+cfg.ShortName,
+		"CLAB_NODE_LONG_NAME="+cfg.LongName,
+		"CLAB_NODE_KIND="+cfg.Kind,
+		"CLAB_NODE_DIR="+cfg.LabDir,
+		"CLAB_NODE_MGMT_IPV4="+cfg.MgmtIPv4Address,
+		"CLAB_NODE_MGMT_IPV6="+cfg.MgmtIPv6Address,
+		"CLAB_NODE_NSPATH="+cfg.NSPath,
+	)
+	c.m.RUnlock()
+
+	return c.runHooks(ctx, hooks, stage, "node "+cfg.ShortName, env)
+}
+
+// RunNodesHooks runs the hooks of the stage of the nodes ns one node after another in the order of their names.
+func (c *CLab) RunNodesHooks(ctx context.Context, ns map[string]nodes.Node, stage string) error {
+	names := make([]string, 0, len(ns))
+	for name := range ns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.RunNodeHooks(ctx, ns[name], stage); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasHooks returns true when the lab or some of its nodes have hooks of the stage.
+func (c *CLab) HasHooks(stage string) bool {
+	if len(c.Config.Hooks.Get(stage)) != 0 {
+		return true
+	}
+	for _, n := range c.Nodes {
+		if len(n.Config().Hooks.Get(stage)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// labHookEnv returns the environment variables with the lab context exported to the hooks.
+// The management addresses of the lab nodes are exported as CLAB_NODE_<NAME>_MGMT_IPV4/IPV6 variables,
+// they are read under the lab lock, since the deployment workers update them concurrently.
+func (c *CLab) labHookEnv(stage string) []string {
+	env := []string{
+		"CLAB_HOOK_STAGE=" + stage,
+		"CLAB_LAB_NAME=" + c.Config.Name,
+	}
+	if c.Dir != nil {
+		env = append(env, "CLAB_LAB_DIR="+c.Dir.Lab)
+	}
+	if c.TopoFile != nil {
+		env = append(env, "CLAB_TOPO_FILE="+c.TopoFile.path)
+	}
+
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	for name, n := range c.Nodes {
+		prefix := "CLAB_NODE_" + hookEnvName(name)
+		if ip := n.Config().MgmtIPv4Address; ip != "" {
+			env = append(env, prefix+"_MGMT_IPV4="+ip)
+		}
+		if ip := n.Config().MgmtIPv6Address; ip != "" {
+			env = append(env, prefix+"_MGMT_IPV6="+ip)
+		}
+	}
+
+	return env
+}
+
+// hookEnvName returns the name in the form suitable for an environment variable name,
+// with the letters in upper case and the characters other than letters and digits replaced with underscores.
+func hookEnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// runHooks runs the hooks with sh -c in the directory of the topology file one after another.
+// The failed hook stops the run and fails it, unless the failure policy of the hook is warn or ignore.
+func (c *CLab) runHooks(ctx context.Context, hooks []*types.Hook, stage, owner string, env []string) error {
+	for _, h := range hooks {
+		log.Infof("Running %s hook of %s: %s", stage, owner, h.Command)
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		if c.TopoFile != nil && c.TopoFile.dir != "" {
+			cmd.Dir = c.TopoFile.dir
+		}
+		cmd.Env = append(os.Environ(), env...)
+
+		out, err := cmd.CombinedOutput()
+		if len(out) > 0 {
+			log.Infof("%s hook of %s output:\n%s", stage, owner, strings.TrimRight(string(out), "\n"))
+		}
+		if err == nil {
+			continue
+		}
+
+		switch h.GetOnError() {
+		case types.HookOnErrorIgnore:
+			log.Debugf("%s hook %q of %s failed: %v", stage, h.Command, owner, err)
+		case types.HookOnErrorWarn:
+			log.Warnf("%s hook %q of %s failed: %v", stage, h.Command, owner, err)
+		default:
+			return fmt.Errorf("%s hook %q of %s failed: %v", stage, h.Command, owner, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/srl-labs/containerlab/mocks"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// TestRunNodeHooksConcurrently runs the node hooks while the runtime information of the nodes is updated
+// the way the deployment workers do, it is meant to be run with -race.
+func TestRunNodeHooksConcurrently(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	c := &CLab{
+		Config: &Config{Name: "hooks"},
+		m:      &sync.RWMutex{},
+		Nodes:  map[string]nodes.Node{},
+	}
+
+	for i := 0; i < 4; i++ {
+		i := i
+		cfg := &types.NodeConfig{
+			ShortName: fmt.Sprintf("node%d", i),
+			Hooks:     &types.Hooks{PostCreate: []*types.Hook{{Command: "true"}}},
+		}
+
+		n := mocks.NewMockNode(mockCtrl)
+		n.EXPECT().Config().Return(cfg).AnyTimes()
+		n.EXPECT().UpdateConfigWithRuntimeInfo(gomock.Any()).DoAndReturn(
+			func(context.Context) error {
+				cfg.MgmtIPv4Address = fmt.Sprintf("172.20.20.%d", i+2)
+				cfg.MgmtIPv6Address = fmt.Sprintf("2001:172:20:20::%d", i+2)
+				return nil
+			},
+		)
+		c.Nodes[cfg.ShortName] = n
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, n := range c.Nodes {
+		wg.Add(1)
+		go func(n nodes.Node) {
+			defer wg.Done()
+
+			if err := c.updateRuntimeInfo(context.Background(), n); err != nil {
+				t.Error(err)
+			}
+			if err := c.RunNodeHooks(context.Background(), n, types.HookPostCreate); err != nil {
+				t.Error(err)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
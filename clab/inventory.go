@@ -36,6 +36,7 @@ func (c *CLab) generateAnsibleInventory(w io.Writer) error {
 		{{- if (eq (index .Labels "ansible-no-host-var") "") }}
           ansible_host: {{.MgmtIPv4Address}}
 		{{- end -}}
+		{{- template "loopback" . -}}
 {{- end}}
 {{- end}}
 {{- range $name, $nodes := .Groups}}
@@ -44,8 +45,17 @@ func (c *CLab) generateAnsibleInventory(w io.Writer) error {
       {{- range $nodes}}
         {{.LongName}}:
           ansible_host: {{.MgmtIPv4Address}}
+          {{- template "loopback" . -}}
       {{- end}}
 {{- end}}
+{{- define "loopback" }}
+  {{- if .LoopbackIPv4 }}
+          loopback_ipv4: {{.LoopbackIPv4}}
+  {{- end }}
+  {{- if .LoopbackIPv6 }}
+          loopback_ipv6: {{.LoopbackIPv6}}
+  {{- end }}
+{{- end}}
 `
 
 	type inv struct {
@@ -53,6 +53,23 @@ func TestGenerateAnsibleInventory(t *testing.T) {
       hosts:
         clab-topo8_ansible_groups-node1:
           ansible_host: 172.100.100.11
+`,
+		},
+		"case3": {
+			got: "test_data/topo12_ipam.yml",
+			want: `all:
+  children:
+    linux:
+      hosts:
+        clab-topo12-node1:
+          ansible_host: 172.100.100.11
+          loopback_ipv4: 10.255.0.1/32
+        clab-topo12-node2:
+          ansible_host: 172.100.100.12
+          loopback_ipv4: 10.255.0.2/32
+        clab-topo12-node3:
+          ansible_host: 172.100.100.13
+          loopback_ipv4: 10.255.0.3/32
 `,
 		},
 	}
@@ -0,0 +1,240 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/ipam"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// names of the IPAM pools, which are the keys of the allocations in the lab state.
+const (
+	ipamP2PIPv4      = "p2p-ipv4"
+	ipamP2PIPv6      = "p2p-ipv6"
+	ipamLoopbackIPv4 = "loopback-ipv4"
+	ipamLoopbackIPv6 = "loopback-ipv6"
+)
+
+// allocateAddresses allocates the addresses of the links and the loopback addresses of the nodes
+// from the pools of the ipam section of the topology.
+// The allocations of a previous deployment of the lab, which are kept in the lab state,
+// are preserved, and the new links and nodes are allocated the lowest free subnets
+// in the order of the links definition and the node names.
+func (c *CLab) allocateAddresses() error {
+	if c.Config.IPAM == nil {
+		return nil
+	}
+
+	p2p, err := parseIPAMPools(c.Config.IPAM.P2P, 31, 127)
+	if err != nil {
+		return fmt.Errorf("invalid ipam p2p pools: %v", err)
+	}
+	loopback, err := parseIPAMPools(c.Config.IPAM.Loopback, 32, 128)
+	if err != nil {
+		return fmt.Errorf("invalid ipam loopback pools: %v", err)
+	}
+	for _, p := range loopback {
+		if p.Bits != p.Prefix.Addr().BitLen() {
+			return fmt.Errorf("invalid ipam loopback pool %s: loopback addresses must be allocated as /%d",
+				p, p.Prefix.Addr().BitLen())
+		}
+	}
+
+	prev := c.previousIPAMAllocations()
+	c.ipamAllocations = make(map[string]map[string]string)
+
+	// veth links in the order of their definition,
+	// the indexes of the links have gaps when the links of other hosts of a distributed lab are skipped
+	linkIdx := make([]int, 0, len(c.Links))
+	for i := range c.Links {
+		linkIdx = append(linkIdx, i)
+	}
+	sort.Ints(linkIdx)
+
+	links := make([]*types.Link, 0, len(c.Links))
+	linkKeys := make([]string, 0, len(c.Links))
+	for _, i := range linkIdx {
+		l := c.Links[i]
+		if !l.IsVeth() || isBridgeKind(l.A.Node.Kind) || isBridgeKind(l.B.Node.Kind) {
+			continue
+		}
+		links = append(links, l)
+		linkKeys = append(linkKeys, ipamLinkKey(l))
+	}
+
+	nodeNames := make([]string, 0, len(c.Nodes))
+	for name := range c.Nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+
+	for _, p := range p2p {
+		name := ipamP2PIPv4
+		if p.Prefix.Addr().Is6() {
+			name = ipamP2PIPv6
+		}
+
+		subnets, err := c.allocate(name, ipam.NewAllocator(p), linkKeys, prev[name])
+		if err != nil {
+			return err
+		}
+
+		for i, l := range links {
+			a, b, err := ipam.PointToPoint(subnets[i])
+			if err != nil {
+				return err
+			}
+			// the endpoint that comes first in the link key gets the first address
+			if ipamEndpointKey(l.B) < ipamEndpointKey(l.A) {
+				a, b = b, a
+			}
+			setEndpointIP(l.A, a)
+			setEndpointIP(l.B, b)
+		}
+	}
+
+	for _, p := range loopback {
+		name := ipamLoopbackIPv4
+		if p.Prefix.Addr().Is6() {
+			name = ipamLoopbackIPv6
+		}
+
+		a := ipam.NewAllocator(p)
+		// the first address of a pool, which is not a single address, is not used for loopbacks
+		if p.Prefix.Bits() < p.Bits {
+			a.Exclude(netip.PrefixFrom(p.Prefix.Addr(), p.Bits))
+		}
+
+		subnets, err := c.allocate(name, a, nodeNames, prev[name])
+		if err != nil {
+			return err
+		}
+
+		for i, n := range nodeNames {
+			cfg := c.Nodes[n].Config()
+			if p.Prefix.Addr().Is4() {
+				cfg.LoopbackIPv4 = subnets[i].String()
+			} else {
+				cfg.LoopbackIPv6 = subnets[i].String()
+			}
+		}
+	}
+
+	return nil
+}
+
+// allocate allocates the subnets of the pool name to keys with allocator a,
+// preserving the previous allocations of the keys.
+// The allocated subnets are returned in the order of keys.
+func (c *CLab) allocate(name string, a *ipam.Allocator, keys []string, prev map[string]string) ([]netip.Prefix, error) {
+	// previous allocations are reserved first, so that new keys don't take them
+	for _, k := range keys {
+		s, ok := prev[k]
+		if !ok {
+			continue
+		}
+		pfx, err := netip.ParsePrefix(s)
+		if err != nil || !a.Reserve(k, pfx) {
+			log.Warnf("%s: previous allocation %s of %s is not valid for the pool and will be replaced", name, s, k)
+		}
+	}
+
+	subnets := make([]netip.Prefix, 0, len(keys))
+	for _, k := range keys {
+		s, err := a.Allocate(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate %s subnet for %s: %v", name, k, err)
+		}
+		subnets = append(subnets, s)
+	}
+
+	allocs := make(map[string]string, len(keys))
+	for k, s := range a.Allocations() {
+		allocs[k] = s.String()
+	}
+	c.ipamAllocations[name] = allocs
+
+	return subnets, nil
+}
+
+// previousIPAMAllocations returns the IPAM allocations from the state of a previous deployment of the lab.
+func (c *CLab) previousIPAMAllocations() map[string]map[string]string {
+	if !utils.FileExists(filepath.Join(c.Dir.Lab, LabStateFName)) {
+		return nil
+	}
+
+	s, err := ReadLabState(c.Dir.Lab)
+	if err != nil {
+		log.Warnf("IPAM allocations of the previous deployment can't be restored: %v", err)
+		return nil
+	}
+
+	return s.IPAM
+}
+
+// parseIPAMPools parses the pools with at most one pool per address family.
+func parseIPAMPools(pools types.IPAMPools, v4Bits, v6Bits int) ([]*ipam.Pool, error) {
+	res := make([]*ipam.Pool, 0, len(pools))
+	var v4, v6 bool
+
+	for _, s := range pools {
+		p, err := ipam.ParsePool(s, v4Bits, v6Bits)
+		if err != nil {
+			return nil, err
+		}
+
+		dup := v4
+		if p.Prefix.Addr().Is6() {
+			dup = v6
+		}
+		if dup {
+			return nil, fmt.Errorf("only one pool per address family is supported, found another one %q", s)
+		}
+		v4 = v4 || p.Prefix.Addr().Is4()
+		v6 = v6 || p.Prefix.Addr().Is6()
+
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+// ipamLinkKey returns the key of a link, which doesn't depend on the order of its endpoints.
+func ipamLinkKey(l *types.Link) string {
+	a, b := ipamEndpointKey(l.A), ipamEndpointKey(l.B)
+	if b < a {
+		a, b = b, a
+	}
+	return a + "--" + b
+}
+
+func ipamEndpointKey(e *types.Endpoint) string {
+	return e.Node.ShortName + ":" + e.EndpointName
+}
+
+// setEndpointIP sets the address of the endpoint, unless it is set in the topology.
+func setEndpointIP(e *types.Endpoint, addr netip.Prefix) {
+	switch {
+	case addr.Addr().Is4() && e.IPv4 == "":
+		e.IPv4 = addr.String()
+	case addr.Addr().Is6() && e.IPv6 == "":
+		e.IPv6 = addr.String()
+	default:
+		return
+	}
+
+	updateNodeEndpoint(e)
+}
+
+func isBridgeKind(kind string) bool {
+	return kind == "bridge" || kind == "ovs-bridge"
+}
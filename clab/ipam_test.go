@@ -0,0 +1,95 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// linkIPs returns the addresses of the link endpoints keyed by the endpoint.
+func linkIPs(c *CLab) map[string]string {
+	ips := make(map[string]string)
+	for _, l := range c.Links {
+		for _, e := range []struct{ k, v4, v6 string }{
+			{ipamEndpointKey(l.A), l.A.IPv4, l.A.IPv6},
+			{ipamEndpointKey(l.B), l.B.IPv4, l.B.IPv6},
+		} {
+			ips[e.k] = e.v4 + " " + e.v6
+		}
+	}
+	return ips
+}
+
+func TestAllocateAddresses(t *testing.T) {
+	t.Setenv("PWD", t.TempDir())
+
+	c, err := NewContainerLab(WithTopoFile("test_data/topo12_ipam.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"node1:eth1": "10.0.0.0/31 2001:db8::/127",
+		"node2:eth1": "10.0.0.1/31 2001:db8::1/127",
+		"node1:eth2": "10.0.0.2/31 2001:db8::2/127",
+		"node3:eth1": "10.0.0.3/31 2001:db8::3/127",
+	}
+	if d := cmp.Diff(want, linkIPs(c)); d != "" {
+		t.Errorf("link addresses mismatch (-want +got):\n%s", d)
+	}
+
+	wantLo := map[string]string{
+		"node1": "10.255.0.1/32",
+		"node2": "10.255.0.2/32",
+		"node3": "10.255.0.3/32",
+	}
+	for n, ip := range wantLo {
+		if got := c.Nodes[n].Config().LoopbackIPv4; got != ip {
+			t.Errorf("expected loopback %s of %s, got %s", ip, n, got)
+		}
+	}
+
+	// the lab is redeployed with a link and a node added before the existing ones
+	if err := os.MkdirAll(c.Dir.Lab, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WriteLabState(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile("test_data/topo12_ipam.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topo := strings.Replace(string(b), "    node1:\n", "    node0:\n      kind: linux\n    node1:\n", 1)
+	topo = strings.Replace(topo, "  links:\n", "  links:\n    - endpoints: [\"node0:eth1\", \"node3:eth2\"]\n", 1)
+	topoPath := filepath.Join(t.TempDir(), "topo12.yml")
+	if err := os.WriteFile(topoPath, []byte(topo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = NewContainerLab(WithTopoFile(topoPath, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want["node0:eth1"] = "10.0.0.4/31 2001:db8::4/127"
+	want["node3:eth2"] = "10.0.0.5/31 2001:db8::5/127"
+	if d := cmp.Diff(want, linkIPs(c)); d != "" {
+		t.Errorf("link addresses mismatch after redeploy (-want +got):\n%s", d)
+	}
+
+	wantLo["node0"] = "10.255.0.4/32"
+	for n, ip := range wantLo {
+		if got := c.Nodes[n].Config().LoopbackIPv4; got != ip {
+			t.Errorf("expected loopback %s of %s after redeploy, got %s", ip, n, got)
+		}
+	}
+}
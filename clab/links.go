@@ -0,0 +1,160 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/vishvananda/netlink"
+)
+
+// ResolveEndpointNode returns the config of the node nodeName referenced in a link endpoint
+// of a deployed lab. For nodes backed by containers the config is built from the labels
+// of the running lab container and has the network namespace path of the container set.
+func (c *CLab) ResolveEndpointNode(ctx context.Context, nodeName string) (*types.NodeConfig, error) {
+	if n := specialEndpointNode(nodeName); n != nil {
+		return n, nil
+	}
+
+	if n, ok := c.Nodes[nodeName]; ok {
+		cfg := n.Config()
+		switch {
+		case inRootNetns(cfg):
+			return cfg, nil
+		// external containers are not labeled by containerlab
+		case cfg.Kind == "ext-container":
+			nsPath, err := n.GetRuntime().GetNSPath(ctx, cfg.ShortName)
+			if err != nil {
+				return nil, err
+			}
+			return &types.NodeConfig{
+				ShortName:        cfg.ShortName,
+				LongName:         cfg.ShortName,
+				Kind:             cfg.Kind,
+				NSPath:           nsPath,
+				DeploymentStatus: "created",
+			}, nil
+		}
+	}
+
+	filter := []*types.GenericFilter{
+		{FilterType: "label", Field: ContainerlabLabel, Operator: "=", Match: c.Config.Name},
+		{FilterType: "label", Field: NodeNameLabel, Operator: "=", Match: nodeName},
+	}
+	for _, r := range c.Runtimes {
+		ctrs, err := r.ListContainers(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not list containers: %v", err)
+		}
+		if len(ctrs) == 0 || len(ctrs[0].Names) == 0 {
+			continue
+		}
+
+		ctr := ctrs[0]
+		nsPath, err := r.GetNSPath(ctx, ctr.Names[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.NodeConfig{
+			ShortName:        nodeName,
+			LongName:         ctr.Names[0],
+			Kind:             ctr.Labels[NodeKindLabel],
+			NSPath:           nsPath,
+			DeploymentStatus: "created",
+		}, nil
+	}
+
+	return nil, fmt.Errorf("node %q is not found in the running lab %q", nodeName, c.Config.Name)
+}
+
+// SetLinkState sets the interface ifName of the node n administratively up or down.
+// When one end of a veth pair is set down, its peer loses carrier.
+func SetLinkState(n *types.NodeConfig, ifName string, up bool) error {
+	return inNodeNetns(n, func() error {
+		l, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return fmt.Errorf("failed to lookup interface %q of node %q: %v", ifName, n.ShortName, err)
+		}
+
+		if up {
+			log.Infof("Setting interface %s:%s up", n.ShortName, ifName)
+			return netlink.LinkSetUp(l)
+		}
+
+		log.Infof("Setting interface %s:%s down", n.ShortName, ifName)
+		return netlink.LinkSetDown(l)
+	})
+}
+
+// DeleteLink deletes the interface ifName of the node n.
+// Deleting one end of a veth pair removes its peer as well.
+func DeleteLink(n *types.NodeConfig, ifName string) error {
+	return inNodeNetns(n, func() error {
+		l, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return fmt.Errorf("failed to lookup interface %q of node %q: %v", ifName, n.ShortName, err)
+		}
+
+		log.Infof("Deleting interface %s:%s", n.ShortName, ifName)
+		return netlink.LinkDel(l)
+	})
+}
+
+// AddLink creates the link l between the nodes of a running lab and adds it to the lab links,
+// so that it is written to the lab state and removed when the lab is destroyed.
+func (c *CLab) AddLink(l *types.Link) error {
+	if err := c.CreateVirtualWiring(l); err != nil {
+		return err
+	}
+
+	c.addLink(l)
+
+	return nil
+}
+
+// RemoveLink deletes the interface ifName of the node n and removes the links it is an endpoint of
+// from the lab links.
+func (c *CLab) RemoveLink(n *types.NodeConfig, ifName string) error {
+	if err := DeleteLink(n, ifName); err != nil {
+		return err
+	}
+
+	c.removeLinks(n.ShortName, ifName)
+
+	return nil
+}
+
+// addLink adds the link l to the lab links under the index following the last one.
+func (c *CLab) addLink(l *types.Link) {
+	if c.Links == nil {
+		c.Links = map[int]*types.Link{}
+	}
+
+	idx := 0
+	for i := range c.Links {
+		if i >= idx {
+			idx = i + 1
+		}
+	}
+
+	c.Links[idx] = l
+}
+
+// removeLinks removes the links with the endpoint nodeName:ifName from the lab links.
+func (c *CLab) removeLinks(nodeName, ifName string) {
+	for i, l := range c.Links {
+		for _, e := range []*types.Endpoint{l.A, l.B} {
+			if e != nil && e.Node.ShortName == nodeName && e.EndpointName == ifName {
+				log.Debugf("Removing link %s from the lab links", l)
+				delete(c.Links, i)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// stateLinks returns the links of the lab state in the node:interface <--> node:interface format.
+func stateLinks(t *testing.T, labDir string) []string {
+	t.Helper()
+
+	s, err := ReadLabState(labDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var links []string
+	for _, l := range s.Links {
+		links = append(links, l.A.Node+":"+l.A.Interface+" <--> "+l.B.Node+":"+l.B.Interface)
+	}
+	sort.Strings(links)
+
+	return links
+}
+
+func TestLinkAddRemoveState(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Dir = newDirectory(t.TempDir())
+
+	// the link between the nodes of the lab and the link to the host added by the link operations
+	c.addLink(&types.Link{
+		Type: types.LinkTypeVeth,
+		A:    &types.Endpoint{Node: c.Nodes["lin1"].Config(), EndpointName: "eth3"},
+		B:    &types.Endpoint{Node: c.Nodes["lin2"].Config(), EndpointName: "eth3"},
+		MTU:  DefaultVethLinkMTU,
+	})
+	c.addLink(&types.Link{
+		Type: types.LinkTypeVeth,
+		A:    &types.Endpoint{Node: c.Nodes["lin1"].Config(), EndpointName: "eth4"},
+		B:    &types.Endpoint{Node: specialEndpointNode("host"), EndpointName: "lin1-eth4"},
+		MTU:  DefaultVethLinkMTU,
+	})
+	if err := c.WriteLabState(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"lin1:eth1 <--> lin2:eth1",
+		"lin1:eth2 <--> br1:lin1-eth2",
+		"lin1:eth3 <--> lin2:eth3",
+		"lin1:eth4 <--> host:lin1-eth4",
+		"lin2:eth2 <--> host:lin2-eth2",
+	}
+	if d := cmp.Diff(want, stateLinks(t, c.Dir.Lab)); d != "" {
+		t.Errorf("links after add mismatch (-want +got):\n%s", d)
+	}
+
+	// the lab loaded from the state has the added links, which are removed on destroy
+	restored, err := NewContainerLab(WithLabState(c.Dir.Lab))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Links) != len(want) {
+		t.Fatalf("expected %d links in the restored lab, got %d", len(want), len(restored.Links))
+	}
+
+	// links are removed by either of their endpoints
+	restored.removeLinks("lin2", "eth3")
+	restored.removeLinks("host", "lin1-eth4")
+	restored.removeLinks("lin1", "eth1")
+	// unknown endpoints don't remove links
+	restored.removeLinks("lin2", "eth9")
+	if err := restored.WriteLabState(); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []string{
+		"lin1:eth2 <--> br1:lin1-eth2",
+		"lin2:eth2 <--> host:lin2-eth2",
+	}
+	if d := cmp.Diff(want, stateLinks(t, c.Dir.Lab)); d != "" {
+		t.Errorf("links after remove mismatch (-want +got):\n%s", d)
+	}
+
+	// a link added after removal doesn't replace the remaining links
+	restored.addLink(&types.Link{
+		Type: types.LinkTypeVeth,
+		A:    &types.Endpoint{Node: restored.Nodes["lin1"].Config(), EndpointName: "eth1"},
+		B:    &types.Endpoint{Node: restored.Nodes["lin2"].Config(), EndpointName: "eth1"},
+	})
+	if len(restored.Links) != 3 {
+		t.Errorf("expected 3 links after re-adding a link, got %d", len(restored.Links))
+	}
+}
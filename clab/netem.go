@@ -0,0 +1,176 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// netemTbfLatency is the maximum time a packet can sit in the tbf queue used for rate limiting.
+	netemTbfLatency = 50 * time.Millisecond
+	// netemTbfMinBurst is the minimal tbf bucket size in bytes, which fits at least a full frame.
+	netemTbfMinBurst = 1600
+)
+
+// SetLinkImpairment applies the impairment of the link l to both of its endpoints.
+func SetLinkImpairment(l *types.Link) error {
+	if l.Impairment.IsEmpty() {
+		return nil
+	}
+
+	for _, e := range l.Endpoints() {
+		e := e
+		log.Infof("Setting impairment on %s:%s", e.Node.ShortName, e.EndpointName)
+		err := inNodeNetns(e.Node, func() error {
+			return SetImpairment(e.EndpointName, l.Impairment)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set impairment on %s:%s: %v", e.Node.ShortName, e.EndpointName, err)
+		}
+	}
+
+	return nil
+}
+
+// SetImpairment replaces the root qdisc of the interface ifName in the current network namespace
+// with a netem qdisc configured according to the impairment li.
+// When rate is set, a tbf qdisc is attached as a child of the netem qdisc.
+func SetImpairment(ifName string, li *types.LinkImpairment) error {
+	if err := li.Validate(); err != nil {
+		return err
+	}
+	delay, jitter, _ := li.DelayDuration()
+
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return fmt.Errorf("failed to lookup %q: %v", ifName, err)
+	}
+
+	// tc qdisc replace dev $IFACE root handle 1: netem ...
+	netem := netlink.NewNetem(
+		netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		netlink.NetemQdiscAttrs{
+			Latency:     uint32(delay.Microseconds()),
+			Jitter:      uint32(jitter.Microseconds()),
+			Loss:        float32(li.Loss),
+			Duplicate:   float32(li.Duplicate),
+			CorruptProb: float32(li.Corruption),
+		},
+	)
+	if err := netlink.QdiscReplace(netem); err != nil {
+		return err
+	}
+
+	if li.Rate == 0 {
+		return nil
+	}
+
+	// tc qdisc replace dev $IFACE parent 1:1 handle 2: tbf rate ...
+	rate := li.Rate * 1000 / 8 // kbit/s -> bytes/s
+	burst := uint32(float64(rate) / netlink.Hz())
+	if burst < netemTbfMinBurst {
+		burst = netemTbfMinBurst
+	}
+
+	tbf := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(2, 0),
+			Parent:    netlink.MakeHandle(1, 1),
+		},
+		Rate:   rate,
+		Limit:  uint32(float64(rate)*netemTbfLatency.Seconds()) + burst,
+		Buffer: netlink.Xmittime(rate, burst),
+	}
+
+	return netlink.QdiscReplace(tbf)
+}
+
+// GetImpairment returns the impairment set on the interface ifName in the current network namespace.
+// A nil impairment is returned when the interface has no netem root qdisc.
+func GetImpairment(ifName string) (*types.LinkImpairment, error) {
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup %q: %v", ifName, err)
+	}
+
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return nil, err
+	}
+
+	var li *types.LinkImpairment
+	for _, q := range qdiscs {
+		if netem, ok := q.(*netlink.Netem); ok && netem.Parent == netlink.HANDLE_ROOT {
+			li = &types.LinkImpairment{
+				Loss:       u32ToPercentage(netem.Loss),
+				Duplicate:  u32ToPercentage(netem.Duplicate),
+				Corruption: u32ToPercentage(netem.CorruptProb),
+			}
+			if netem.Latency > 0 {
+				li.Delay = tickToDuration(netem.Latency).String()
+			}
+			if netem.Jitter > 0 {
+				li.Jitter = tickToDuration(netem.Jitter).String()
+			}
+		}
+	}
+	if li == nil {
+		return nil, nil
+	}
+
+	for _, q := range qdiscs {
+		if tbf, ok := q.(*netlink.Tbf); ok && tbf.Parent == netlink.MakeHandle(1, 1) {
+			li.Rate = tbf.Rate * 8 / 1000
+		}
+	}
+
+	return li, nil
+}
+
+// ResetImpairment removes the netem root qdisc from the interface ifName in the current network namespace.
+// Deleting the root qdisc removes its children as well.
+func ResetImpairment(ifName string) error {
+	li, err := GetImpairment(ifName)
+	if err != nil || li == nil {
+		return err
+	}
+
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return fmt.Errorf("failed to lookup %q: %v", ifName, err)
+	}
+
+	netem := &netlink.Netem{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+	}
+	return netlink.QdiscDel(netem)
+}
+
+// u32ToPercentage is the reverse of netlink.Percentage2u32.
+func u32ToPercentage(v uint32) float64 {
+	// round to 3 decimal places to hide the conversion error
+	return math.Round(float64(v)/math.MaxUint32*100*1000) / 1000
+}
+
+// tickToDuration converts netem time value expressed in scheduler ticks to time.Duration.
+func tickToDuration(tick uint32) time.Duration {
+	return time.Duration(math.Round(float64(tick)/netlink.TickInUsec())) * time.Microsecond
+}
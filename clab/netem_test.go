@@ -0,0 +1,139 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/containernetworking/plugins/pkg/testutils"
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/vishvananda/netlink"
+)
+
+func TestSetImpairmentInvalid(t *testing.T) {
+	tests := map[string]*types.LinkImpairment{
+		"invalid_delay":     {Delay: "10"},
+		"jitter_only":       {Jitter: "1ms"},
+		"loss_out_of_range": {Loss: 101},
+	}
+
+	for name, li := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the impairment is validated before the interface is looked up
+			if err := SetImpairment("clab-noif0", li); err == nil || strings.Contains(err.Error(), "failed to lookup") {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+// newImpairmentTestLink returns the veth link between the nodes node1 and node2 with the network namespaces
+// created for the test, it skips the test when it can't be created.
+func newImpairmentTestLink(t *testing.T, li *types.LinkImpairment) *types.Link {
+	t.Helper()
+
+	if os.Geteuid() != 0 {
+		t.Skip("setting qdiscs requires root privileges")
+	}
+
+	l := &types.Link{Type: types.LinkTypeVeth, Impairment: li}
+	for i, e := range []**types.Endpoint{&l.A, &l.B} {
+		nodeNS, err := testutils.NewNS()
+		if err != nil {
+			t.Skipf("failed to create network namespace: %v", err)
+		}
+		t.Cleanup(func() {
+			nodeNS.Close()
+			_ = testutils.UnmountNS(nodeNS)
+		})
+
+		*e = &types.Endpoint{
+			Node:         &types.NodeConfig{ShortName: []string{"node1", "node2"}[i], NSPath: nodeNS.Path()},
+			EndpointName: "eth1",
+		}
+	}
+
+	veth := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "clab-netem0"}, PeerName: "clab-netem1"}
+	if err := netlink.LinkAdd(veth); err != nil {
+		t.Skipf("failed to create veth: %v", err)
+	}
+	// the netem qdisc kernel module might be missing
+	if err := SetImpairment("clab-netem0", &types.LinkImpairment{Delay: "1ms"}); err != nil {
+		_ = netlink.LinkDel(veth)
+		t.Skipf("failed to set netem qdisc: %v", err)
+	}
+	if err := ResetImpairment("clab-netem0"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []struct {
+		name string
+		ep   *types.Endpoint
+	}{{"clab-netem0", l.A}, {"clab-netem1", l.B}} {
+		link, err := netlink.LinkByName(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vEth := vEthEndpoint{Link: link, LinkName: e.ep.EndpointName, NSPath: e.ep.Node.NSPath}
+		if err := vEth.toNS(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return l
+}
+
+func TestLinkImpairment(t *testing.T) {
+	li := &types.LinkImpairment{
+		Delay:      "10ms",
+		Jitter:     "2ms",
+		Loss:       5,
+		Corruption: 0.5,
+		Duplicate:  1,
+		Rate:       10000,
+	}
+	l := newImpairmentTestLink(t, li)
+
+	if err := SetLinkImpairment(l); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range l.Endpoints() {
+		var got *types.LinkImpairment
+		err := inNodeNetns(e.Node, func() (err error) {
+			got, err = GetImpairment(e.EndpointName)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := cmp.Diff(li, got); d != "" {
+			t.Errorf("impairment of %s:%s mismatch (-want +got):\n%s", e.Node.ShortName, e.EndpointName, d)
+		}
+	}
+
+	for _, e := range l.Endpoints() {
+		err := inNodeNetns(e.Node, func() error {
+			if err := ResetImpairment(e.EndpointName); err != nil {
+				return err
+			}
+			got, err := GetImpairment(e.EndpointName)
+			if err != nil {
+				return err
+			}
+			if got != nil {
+				t.Errorf("expected impairment of %s:%s to be cleared, got %+v", e.Node.ShortName, e.EndpointName, got)
+			}
+			// resetting the interface without the impairment is a no-op
+			return ResetImpairment(e.EndpointName)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -17,6 +17,26 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
+// kernelDataplaneKinds are the kinds which data plane is the linux kernel of the node's network namespace,
+// thus the addresses of their endpoints are configured by containerlab.
+var kernelDataplaneKinds = map[string]struct{}{
+	"linux":         {},
+	"host":          {},
+	"ext-container": {},
+}
+
+// macvlanModes maps the macvlan modes of the topology to the netlink ones.
+var macvlanModes = map[string]netlink.MacvlanMode{
+	types.MacvlanModeBridge:   netlink.MACVLAN_MODE_BRIDGE,
+	types.MacvlanModePrivate:  netlink.MACVLAN_MODE_PRIVATE,
+	types.MacvlanModeVepa:     netlink.MACVLAN_MODE_VEPA,
+	types.MacvlanModePassthru: netlink.MACVLAN_MODE_PASSTHRU,
+}
+
+// hostDeviceAliasPrefix prefixes the name of a host interface moved to a node,
+// which is kept in the interface alias to find the interface when it returns to the root namespace.
+const hostDeviceAliasPrefix = "clab-host-device:"
+
 type vEthEndpoint struct {
 	Link      netlink.Link
 	LinkName  string
@@ -28,6 +48,17 @@ type vEthEndpoint struct {
 
 // CreateVirtualWiring creates the virtual topology between the containers.
 func (c *CLab) CreateVirtualWiring(l *types.Link) (err error) {
+	switch l.Type {
+	case types.LinkTypeMacvlan:
+		return createMacvlan(l)
+	case types.LinkTypeHostDevice:
+		return moveHostDevice(l)
+	case types.LinkTypeVxlan:
+		return createVxlanStitch(l)
+	case types.LinkTypeDummy:
+		return createDummy(l)
+	}
+
 	log.Infof("Creating virtual wire: %s:%s <--> %s:%s", l.A.Node.ShortName, l.A.EndpointName, l.B.Node.ShortName, l.B.EndpointName)
 
 	// connect containers (or container and a bridge) using veth pair
@@ -117,9 +148,209 @@ func (c *CLab) CreateVirtualWiring(l *types.Link) (err error) {
 	return err
 }
 
+// createMacvlan creates a macvlan interface on top of the host interface of the link l
+// and moves it to the node of the link.
+func createMacvlan(l *types.Link) error {
+	log.Infof("Creating macvlan interface %s:%s on top of host interface %s",
+		l.A.Node.ShortName, l.A.EndpointName, l.B.EndpointName)
+
+	parent, err := netlink.LinkByName(l.B.EndpointName)
+	if err != nil {
+		return fmt.Errorf("failed to lookup host interface %q: %v", l.B.EndpointName, err)
+	}
+
+	mac, err := net.ParseMAC(l.A.MAC)
+	if err != nil {
+		return err
+	}
+
+	mvl := &netlink.Macvlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:         fmt.Sprintf("clab-%s", genIfName()),
+			ParentIndex:  parent.Attrs().Index,
+			HardwareAddr: mac,
+			MTU:          l.MTU,
+		},
+		Mode: macvlanModes[l.MacvlanMode],
+	}
+	if err := netlink.LinkAdd(mvl); err != nil {
+		return fmt.Errorf("failed to create macvlan interface %s:%s: %v", l.A.Node.ShortName, l.A.EndpointName, err)
+	}
+
+	ep := vEthEndpoint{
+		Link:     mvl,
+		LinkName: l.A.EndpointName,
+		NSName:   l.A.Node.LongName,
+		NSPath:   l.A.Node.NSPath,
+	}
+	if err := ep.toNS(); err != nil {
+		_ = netlink.LinkDel(mvl)
+		return err
+	}
+
+	return nil
+}
+
+// moveHostDevice moves the host interface of the link l to the node of the link.
+func moveHostDevice(l *types.Link) error {
+	log.Infof("Moving host interface %s to %s:%s", l.B.EndpointName, l.A.Node.ShortName, l.A.EndpointName)
+
+	link, err := hostDeviceByName(l.B.EndpointName)
+	if err != nil {
+		return err
+	}
+
+	// the interface has to be down to be renamed in the node netns
+	if err := netlink.LinkSetDown(link); err != nil {
+		return fmt.Errorf("failed to set %q down: %v", l.B.EndpointName, err)
+	}
+	if err := netlink.LinkSetAlias(link, hostDeviceAliasPrefix+l.B.EndpointName); err != nil {
+		return fmt.Errorf("failed to set alias of %q: %v", l.B.EndpointName, err)
+	}
+	if l.A.MAC != "" {
+		mac, err := net.ParseMAC(l.A.MAC)
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetHardwareAddr(link, mac); err != nil {
+			return fmt.Errorf("failed to set mac address of %q: %v", l.B.EndpointName, err)
+		}
+	}
+
+	ep := vEthEndpoint{
+		Link:     link,
+		LinkName: l.A.EndpointName,
+		NSName:   l.A.Node.LongName,
+		NSPath:   l.A.Node.NSPath,
+	}
+
+	return ep.toNS()
+}
+
+// hostDeviceByName returns the host interface name, which is looked up by its alias
+// when the interface has been returned to the root namespace by the kernel under another name.
+func hostDeviceByName(name string) (netlink.Link, error) {
+	link, err := netlink.LinkByName(name)
+	if err == nil {
+		return link, nil
+	}
+
+	if link, aliasErr := netlink.LinkByAlias(hostDeviceAliasPrefix + name); aliasErr == nil {
+		return link, nil
+	}
+
+	return nil, fmt.Errorf("failed to lookup host interface %q: %v", name, err)
+}
+
+// createDummy creates a dummy interface in the node of the link l.
+func createDummy(l *types.Link) error {
+	log.Infof("Creating dummy interface %s:%s", l.A.Node.ShortName, l.A.EndpointName)
+
+	mac, err := net.ParseMAC(l.A.MAC)
+	if err != nil {
+		return err
+	}
+
+	dummy := &netlink.Dummy{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:         fmt.Sprintf("clab-%s", genIfName()),
+			HardwareAddr: mac,
+			MTU:          l.MTU,
+		},
+	}
+	if err := netlink.LinkAdd(dummy); err != nil {
+		return fmt.Errorf("failed to create dummy interface %s:%s: %v", l.A.Node.ShortName, l.A.EndpointName, err)
+	}
+
+	ep := vEthEndpoint{
+		Link:     dummy,
+		LinkName: l.A.EndpointName,
+		NSName:   l.A.Node.LongName,
+		NSPath:   l.A.Node.NSPath,
+	}
+	if err := ep.toNS(); err != nil {
+		_ = netlink.LinkDel(dummy)
+		return err
+	}
+
+	return nil
+}
+
+// SetEndpointsConfig sets the MTU of the link endpoints and, for the nodes which data plane is the linux kernel,
+// configures the addresses of the endpoints.
+func SetEndpointsConfig(l *types.Link) error {
+	for _, e := range l.Endpoints() {
+		e := e
+		_, kernel := kernelDataplaneKinds[e.Node.Kind]
+		if e.MTU == 0 && (!kernel || (e.IPv4 == "" && e.IPv6 == "")) {
+			continue
+		}
+
+		err := inNodeNetns(e.Node, func() error {
+			return setEndpointConfig(e, kernel)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure %s:%s: %v", e.Node.ShortName, e.EndpointName, err)
+		}
+	}
+
+	return nil
+}
+
+// setEndpointConfig sets the MTU and, when withAddrs is true, the addresses of the endpoint e
+// in the current network namespace.
+func setEndpointConfig(e *types.Endpoint, withAddrs bool) error {
+	link, err := netlink.LinkByName(e.EndpointName)
+	if err != nil {
+		return fmt.Errorf("failed to lookup %q: %v", e.EndpointName, err)
+	}
+
+	if e.MTU != 0 {
+		if err := netlink.LinkSetMTU(link, e.MTU); err != nil {
+			return fmt.Errorf("failed to set mtu %d: %v", e.MTU, err)
+		}
+	}
+
+	if !withAddrs {
+		return nil
+	}
+
+	for _, a := range []string{e.IPv4, e.IPv6} {
+		if a == "" {
+			continue
+		}
+
+		addr, err := netlink.ParseAddr(a)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("Setting address %s on %s:%s", a, e.Node.ShortName, e.EndpointName)
+		// replace keeps the operation idempotent when the link is re-created
+		if err := netlink.AddrReplace(link, addr); err != nil {
+			return fmt.Errorf("failed to set address %s: %v", a, err)
+		}
+	}
+
+	return nil
+}
+
 // RemoveHostOrBridgeVeth tries to remove veths connected to the host network namespace or a linux bridge
 // and does nothing in case they are not found.
+// Host interfaces of host-device links are returned to the host network namespace under their original names,
+// the interfaces of vxlan links are removed from the host network namespace,
+// while the interfaces of macvlan and dummy links are removed along with the network namespace of their node.
 func (c *CLab) RemoveHostOrBridgeVeth(l *types.Link) (err error) {
+	switch l.Type {
+	case types.LinkTypeMacvlan, types.LinkTypeDummy:
+		return nil
+	case types.LinkTypeHostDevice:
+		return releaseHostDevice(l)
+	case types.LinkTypeVxlan:
+		deleteVxlanStitch(l)
+		return nil
+	}
+
 	switch {
 	case l.A.Node.Kind == "host" || l.A.Node.Kind == "bridge":
 		link, err := netlink.LinkByName(l.A.EndpointName)
@@ -153,6 +384,68 @@ func (c *CLab) RemoveHostOrBridgeVeth(l *types.Link) (err error) {
 	return nil
 }
 
+// releaseHostDevice returns the host interface of the host-device link l to the host network namespace.
+// The interface is moved from the node netns, if the node still exists, otherwise it is looked up by its alias
+// in the host netns, where the kernel moves physical interfaces once the node netns is gone.
+func releaseHostDevice(l *types.Link) error {
+	if _, err := netlink.LinkByName(l.B.EndpointName); err == nil {
+		log.Debugf("Host interface %q is already in the host network namespace", l.B.EndpointName)
+		return nil
+	}
+
+	// moving the interface fails when the node netns is gone,
+	// the error is only reported when the interface is not found in the host netns either
+	var moveErr error
+	if l.A.Node.NSPath != "" {
+		moveErr = moveToHostNetns(l.A.Node, l.A.EndpointName)
+		if moveErr != nil {
+			log.Debugf("Failed to move interface %s:%s to the host network namespace: %v",
+				l.A.Node.ShortName, l.A.EndpointName, moveErr)
+		}
+	}
+
+	link, err := netlink.LinkByAlias(hostDeviceAliasPrefix + l.B.EndpointName)
+	if err != nil {
+		if moveErr != nil {
+			return fmt.Errorf("failed to return host interface %s from %s:%s: %v",
+				l.B.EndpointName, l.A.Node.ShortName, l.A.EndpointName, moveErr)
+		}
+		log.Debugf("Host interface %q is not found: %v", l.B.EndpointName, err)
+		return nil
+	}
+
+	log.Infof("Returning host interface %s from %s:%s", l.B.EndpointName, l.A.Node.ShortName, l.A.EndpointName)
+
+	if err := netlink.LinkSetDown(link); err != nil {
+		return fmt.Errorf("failed to set %q down: %v", link.Attrs().Name, err)
+	}
+	if err := netlink.LinkSetName(link, l.B.EndpointName); err != nil {
+		return fmt.Errorf("failed to rename %q to %q: %v", link.Attrs().Name, l.B.EndpointName, err)
+	}
+	if err := netlink.LinkSetAlias(link, ""); err != nil {
+		return fmt.Errorf("failed to reset alias of %q: %v", l.B.EndpointName, err)
+	}
+
+	return netlink.LinkSetUp(link)
+}
+
+// moveToHostNetns moves the interface ifName of the node n to the host network namespace.
+func moveToHostNetns(n *types.NodeConfig, ifName string) error {
+	hostNS, err := ns.GetCurrentNS()
+	if err != nil {
+		return err
+	}
+	defer hostNS.Close()
+
+	return inNodeNetns(n, func() error {
+		link, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return err
+		}
+		return netlink.LinkSetNsFd(link, int(hostNS.Fd()))
+	})
+}
+
 // createVethIface takes two veth endpoint structs and create a veth pair and return
 // veth interface links.
 func createVethIface(ifName, peerName string, mtu int, aMAC, bMAC net.HardwareAddr) (linkA, linkB netlink.Link, err error) {
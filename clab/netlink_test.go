@@ -0,0 +1,97 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/vishvananda/netlink"
+)
+
+// hostDeviceLink returns the host-device link moving the host interface hostIf to the interface eth1 of node1
+// with the network namespace nsPath.
+func hostDeviceLink(nsPath, hostIf string) *types.Link {
+	return &types.Link{
+		Type: types.LinkTypeHostDevice,
+		A: &types.Endpoint{
+			Node:         &types.NodeConfig{ShortName: "node1", LongName: "clab-test-node1", NSPath: nsPath},
+			EndpointName: "eth1",
+		},
+		B: &types.Endpoint{Node: specialEndpointNode("host"), EndpointName: hostIf},
+	}
+}
+
+func TestReleaseHostDeviceNodeNetnsGone(t *testing.T) {
+	l := hostDeviceLink(filepath.Join(t.TempDir(), "netns"), "clab-nohost0")
+
+	// the host interface is neither in the node netns nor in the host netns
+	if err := releaseHostDevice(l); err == nil {
+		t.Error("expected error releasing host interface of a node without netns")
+	}
+}
+
+func TestHostDeviceTeardown(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("moving interfaces between network namespaces requires root privileges")
+	}
+
+	nodeNS, err := testutils.NewNS()
+	if err != nil {
+		t.Skipf("failed to create network namespace: %v", err)
+	}
+	defer func() {
+		nodeNS.Close()
+		_ = testutils.UnmountNS(nodeNS)
+	}()
+
+	// a veth stands for the physical interface of the host
+	hostIf := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "clab-hdev0"}, PeerName: "clab-hdev0p"}
+	if err := netlink.LinkAdd(hostIf); err != nil {
+		t.Skipf("failed to create host interface: %v", err)
+	}
+	defer func() {
+		if link, err := netlink.LinkByName("clab-hdev0p"); err == nil {
+			_ = netlink.LinkDel(link)
+		}
+	}()
+
+	l := hostDeviceLink(nodeNS.Path(), "clab-hdev0")
+
+	if err := moveHostDevice(l); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := netlink.LinkByName("clab-hdev0"); err == nil {
+		t.Fatal("host interface is still in the host netns")
+	}
+	err = nodeNS.Do(func(_ ns.NetNS) error {
+		_, err := netlink.LinkByName("eth1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("host interface is not found in the node netns: %v", err)
+	}
+
+	if err := releaseHostDevice(l); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := netlink.LinkByName("clab-hdev0")
+	if err != nil {
+		t.Fatalf("host interface is not returned to the host netns: %v", err)
+	}
+	if alias := link.Attrs().Alias; alias != "" {
+		t.Errorf("expected alias of the returned host interface to be reset, got %q", alias)
+	}
+
+	// releasing the interface already returned is a no-op
+	if err := releaseHostDevice(l); err != nil {
+		t.Errorf("expected no error releasing returned host interface, got %v", err)
+	}
+}
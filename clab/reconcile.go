@@ -0,0 +1,537 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+	"github.com/vishvananda/netlink"
+)
+
+// containerlessKinds are the kinds that don't have a container managed by containerlab.
+// These nodes are always scheduled during reconciliation, since their deployment is a noop
+// or merely discovers the node's network namespace.
+var containerlessKinds = map[string]struct{}{ // nolint:gochecknoglobals
+	"bridge":        {},
+	"ovs-bridge":    {},
+	"host":          {},
+	"ext-container": {},
+}
+
+// ReconcilePlan describes the changes needed to bring a deployed lab in line with its topology.
+type ReconcilePlan struct {
+	// AddNodes are the nodes defined in the topology that have no running container.
+	AddNodes []string
+	// RecreateNodes are the nodes whose container configuration differs from the topology.
+	RecreateNodes []string
+	// DeleteNodes are the nodes of a deployed lab that are no longer defined in the topology.
+	DeleteNodes []string
+	// AddLinks are the links that are missing or have to be rewired.
+	AddLinks map[int]*types.Link
+	// DeleteEndpoints are the interfaces that are removed before links are rewired.
+	DeleteEndpoints []*types.Endpoint
+
+	// containers of the deleted nodes and their runtimes, keyed by container name.
+	deleteContainers map[string]runtime.ContainerRuntime
+}
+
+// IsEmpty returns true when the deployed lab matches the topology.
+func (p *ReconcilePlan) IsEmpty() bool {
+	return len(p.AddNodes) == 0 && len(p.RecreateNodes) == 0 && len(p.DeleteNodes) == 0 &&
+		len(p.AddLinks) == 0 && len(p.DeleteEndpoints) == 0
+}
+
+// String returns a human readable representation of the plan.
+func (p *ReconcilePlan) String() string {
+	if p.IsEmpty() {
+		return "no changes, the lab matches the topology\n"
+	}
+
+	sb := strings.Builder{}
+	for _, n := range p.AddNodes {
+		fmt.Fprintf(&sb, "+ node %s\n", n)
+	}
+	for _, n := range p.RecreateNodes {
+		fmt.Fprintf(&sb, "~ node %s\n", n)
+	}
+	for _, n := range p.DeleteNodes {
+		fmt.Fprintf(&sb, "- node %s\n", n)
+	}
+
+	linkIdx := make([]int, 0, len(p.AddLinks))
+	for i := range p.AddLinks {
+		linkIdx = append(linkIdx, i)
+	}
+	sort.Ints(linkIdx)
+	for _, i := range linkIdx {
+		l := p.AddLinks[i]
+		if !l.IsVeth() {
+			fmt.Fprintf(&sb, "+ %s link %s:%s\n", l.Type, l.A.Node.ShortName, l.A.EndpointName)
+			continue
+		}
+		fmt.Fprintf(&sb, "+ link %s:%s <--> %s:%s\n", l.A.Node.ShortName, l.A.EndpointName,
+			l.B.Node.ShortName, l.B.EndpointName)
+	}
+
+	for _, e := range p.DeleteEndpoints {
+		fmt.Fprintf(&sb, "- interface %s:%s\n", e.Node.ShortName, e.EndpointName)
+	}
+
+	return sb.String()
+}
+
+// PlanReconcile compares the containers and links of a deployed lab with the topology
+// and returns the plan of changes needed to reconcile them.
+// Network namespace paths of the running nodes are populated as part of the planning.
+func (c *CLab) PlanReconcile(ctx context.Context) (*ReconcilePlan, error) {
+	plan := &ReconcilePlan{
+		AddLinks:         map[int]*types.Link{},
+		deleteContainers: map[string]runtime.ContainerRuntime{},
+	}
+
+	filter := []*types.GenericFilter{{
+		FilterType: "label", Field: ContainerlabLabel,
+		Operator: "=", Match: c.Config.Name,
+	}}
+
+	// running containers of the lab keyed by node name
+	running := map[string]types.GenericContainer{}
+	for _, r := range c.Runtimes {
+		ctrs, err := r.ListContainers(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("could not list containers: %v", err)
+		}
+
+		for _, ctr := range ctrs {
+			if len(ctr.Names) == 0 {
+				continue
+			}
+			nodeName := ctr.Labels[NodeNameLabel]
+			if _, ok := c.Nodes[nodeName]; !ok {
+				plan.DeleteNodes = append(plan.DeleteNodes, nodeName)
+				plan.deleteContainers[ctr.Names[0]] = r
+				continue
+			}
+			running[nodeName] = ctr
+		}
+	}
+
+	// changed holds the nodes that are added or recreated
+	changed := map[string]struct{}{}
+	for name, n := range c.Nodes {
+		cfg := n.Config()
+		if _, ok := containerlessKinds[cfg.Kind]; ok {
+			continue
+		}
+
+		ctr, ok := running[name]
+		switch {
+		case !ok:
+			plan.AddNodes = append(plan.AddNodes, name)
+			changed[name] = struct{}{}
+		case ctr.State != "running" || nodeChanged(cfg, &ctr):
+			plan.RecreateNodes = append(plan.RecreateNodes, name)
+			changed[name] = struct{}{}
+		}
+	}
+
+	// nodes sharing the network namespace of a changed node lose their network and have to be recreated too
+	for found := true; found; {
+		found = false
+		for name, n := range c.Nodes {
+			if _, ok := changed[name]; ok {
+				continue
+			}
+			netMode := strings.SplitN(n.Config().NetworkMode, ":", 2)
+			if netMode[0] != "container" || len(netMode) != 2 {
+				continue
+			}
+			if _, ok := changed[netMode[1]]; ok {
+				plan.RecreateNodes = append(plan.RecreateNodes, name)
+				changed[name] = struct{}{}
+				found = true
+			}
+		}
+	}
+
+	sort.Strings(plan.AddNodes)
+	sort.Strings(plan.RecreateNodes)
+	sort.Strings(plan.DeleteNodes)
+
+	// resolve netns paths of the nodes that keep running, which is needed to inspect their interfaces
+	for name, n := range c.Nodes {
+		cfg := n.Config()
+		if _, ok := changed[name]; ok {
+			continue
+		}
+
+		cName := cfg.LongName
+		switch cfg.Kind {
+		case "ext-container":
+			cName = cfg.ShortName
+		case "bridge", "ovs-bridge", "host":
+			continue
+		}
+
+		nspath, err := n.GetRuntime().GetNSPath(ctx, cName)
+		if err != nil {
+			log.Debugf("failed to get netns path of node %q: %v", name, err)
+			continue
+		}
+		cfg.NSPath = nspath
+	}
+
+	c.planLinks(plan, changed)
+
+	return plan, nil
+}
+
+// nodeChanged returns true when the container ctr was created from a configuration different from cfg.
+func nodeChanged(cfg *types.NodeConfig, ctr *types.GenericContainer) bool {
+	if h, ok := ctr.Labels[NodeConfigHashLabel]; ok {
+		return h != cfg.Labels[NodeConfigHashLabel]
+	}
+	// containers deployed without a config hash are compared by image only
+	return ctr.Image != cfg.Image
+}
+
+// planLinks populates the plan with links that are to be (re)created and interfaces to be removed.
+func (c *CLab) planLinks(plan *ReconcilePlan, changed map[string]struct{}) {
+	// desired interfaces per node
+	desired := map[string]map[string]struct{}{}
+	// interfaces to delete, keyed by node and interface name to avoid duplicates
+	toDelete := map[string]*types.Endpoint{}
+
+	for i, l := range c.Links {
+		for _, e := range l.Endpoints() {
+			if desired[e.Node.ShortName] == nil {
+				desired[e.Node.ShortName] = map[string]struct{}{}
+			}
+			desired[e.Node.ShortName][e.EndpointName] = struct{}{}
+		}
+
+		_, aChanged := changed[l.A.Node.ShortName]
+
+		if !l.IsVeth() {
+			var link netlink.Link
+			if !aChanged {
+				link = lookupLink(l.A.Node, l.A.EndpointName)
+			}
+			if link != nil && linkIfaceMatches(l, link) {
+				continue
+			}
+
+			plan.AddLinks[i] = l
+			if link != nil {
+				toDelete[l.A.Node.ShortName+":"+l.A.EndpointName] = l.A
+			}
+			continue
+		}
+
+		_, bChanged := changed[l.B.Node.ShortName]
+
+		var aVeth, bVeth *netlink.Veth
+		if !aChanged {
+			aVeth = lookupVeth(l.A.Node, l.A.EndpointName)
+		}
+		if !bChanged {
+			bVeth = lookupVeth(l.B.Node, l.B.EndpointName)
+		}
+
+		if aVeth != nil && bVeth != nil && vethPeers(aVeth, bVeth) {
+			continue
+		}
+
+		plan.AddLinks[i] = l
+
+		// interfaces left over from a former wiring have to be removed before the link is created
+		if aVeth != nil {
+			toDelete[l.A.Node.ShortName+":"+l.A.EndpointName] = l.A
+		}
+		if bVeth != nil {
+			toDelete[l.B.Node.ShortName+":"+l.B.EndpointName] = l.B
+		}
+	}
+
+	// interfaces created by containerlab that are no longer part of the topology
+	for name, n := range c.Nodes {
+		cfg := n.Config()
+		if _, ok := changed[name]; ok || cfg.NSPath == "" || inRootNetns(cfg) {
+			continue
+		}
+
+		for _, ifName := range clabVeths(cfg) {
+			if _, ok := desired[name][ifName]; ok {
+				continue
+			}
+			toDelete[name+":"+ifName] = &types.Endpoint{Node: cfg, EndpointName: ifName}
+		}
+	}
+
+	keys := make([]string, 0, len(toDelete))
+	for k := range toDelete {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		plan.DeleteEndpoints = append(plan.DeleteEndpoints, toDelete[k])
+	}
+}
+
+// Reconcile applies the reconcile plan to the deployed lab.
+// It returns once the scheduled nodes and links are created, with the errors of the nodes and links failed to be created collected in res.
+func (c *CLab) Reconcile(ctx context.Context, plan *ReconcilePlan, nodeWorkers, linkWorkers uint,
+	res *DeployResult,
+) error {
+	for cName, r := range plan.deleteContainers {
+		log.Infof("Removing container: %s", cName)
+		if err := r.DeleteContainer(ctx, cName); err != nil {
+			log.Errorf("could not remove container %q: %v", cName, err)
+		}
+		_ = utils.DeleteNetnsSymlink(cName)
+	}
+
+	for _, name := range plan.RecreateNodes {
+		n := c.Nodes[name]
+		log.Infof("Removing container %q to recreate it", n.Config().LongName)
+		if err := n.Delete(ctx); err != nil {
+			return fmt.Errorf("could not remove container %q: %v", n.Config().LongName, err)
+		}
+		_ = utils.DeleteNetnsSymlink(n.Config().LongName)
+	}
+
+	for _, e := range plan.DeleteEndpoints {
+		log.Infof("Removing interface %s:%s", e.Node.ShortName, e.EndpointName)
+		if err := deleteVeth(e.Node, e.EndpointName); err != nil {
+			log.Errorf("failed to remove interface %s:%s: %v", e.Node.ShortName, e.EndpointName, err)
+		}
+	}
+
+	scheduled := c.ReconciledNodes(plan)
+
+	for name, n := range c.Nodes {
+		if _, ok := containerlessKinds[n.Config().Kind]; ok {
+			scheduled[name] = n
+			continue
+		}
+		// nodes that keep running are treated as created, which allows their links to be wired
+		if _, ok := scheduled[name]; !ok {
+			n.Config().DeploymentStatus = "created"
+		}
+	}
+
+	if nodeWorkers > uint(len(scheduled)) {
+		nodeWorkers = uint(len(scheduled))
+	}
+	if linkWorkers > uint(len(plan.AddLinks)) {
+		linkWorkers = uint(len(plan.AddLinks))
+	}
+
+	nodesWg, err := c.createNodes(ctx, nodeWorkers, scheduled, res)
+	if err != nil {
+		return err
+	}
+	c.createLinks(ctx, linkWorkers, plan.AddLinks, res)
+	if nodesWg != nil {
+		nodesWg.Wait()
+	}
+
+	return nil
+}
+
+// ReconciledNodes returns the nodes which are created when the plan is applied.
+func (c *CLab) ReconciledNodes(plan *ReconcilePlan) map[string]nodes.Node {
+	res := map[string]nodes.Node{}
+	for _, name := range plan.AddNodes {
+		res[name] = c.Nodes[name]
+	}
+	for _, name := range plan.RecreateNodes {
+		res[name] = c.Nodes[name]
+	}
+	return res
+}
+
+// CheckReconcileConditions runs the topology checks that apply to an already deployed lab.
+// Unlike CheckTopologyDefinition it expects the lab containers and host interfaces to exist.
+func (c *CLab) CheckReconcileConditions(ctx context.Context) error {
+	for _, node := range c.Nodes {
+		err := node.CheckDeploymentConditions(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.verifyLinks(); err != nil {
+		return err
+	}
+	if err := c.verifyDuplicateAddresses(); err != nil {
+		return err
+	}
+	if err := c.verifyRootNetnsInterfaceUniqueness(); err != nil {
+		return err
+	}
+	return c.verifyLicFilesExist()
+}
+
+// nodeConfigHash returns a digest of the node configuration fields that define its container.
+// A change of the digest means the container has to be recreated.
+func nodeConfigHash(cfg *types.NodeConfig) string {
+	// maps are marshalled with sorted keys, which makes the digest stable
+	b, _ := json.Marshal(struct {
+		Kind, NodeType, Image, Entrypoint, Cmd, NetworkMode string
+		MgmtIPv4, MgmtIPv6, User, CPUSet, Memory            string
+		StartupConfig, License, Runtime                     string
+		CPU                                                 float64
+		Env, Sysctls, Labels                                map[string]string
+		Binds                                               []string
+		PortBindings                                        interface{}
+	}{
+		Kind:          cfg.Kind,
+		NodeType:      cfg.NodeType,
+		Image:         cfg.Image,
+		Entrypoint:    cfg.Entrypoint,
+		Cmd:           cfg.Cmd,
+		NetworkMode:   cfg.NetworkMode,
+		MgmtIPv4:      cfg.MgmtIPv4Address,
+		MgmtIPv6:      cfg.MgmtIPv6Address,
+		User:          cfg.User,
+		CPUSet:        cfg.CPUSet,
+		Memory:        cfg.Memory,
+		StartupConfig: cfg.StartupConfig,
+		License:       cfg.License,
+		Runtime:       cfg.Runtime,
+		CPU:           cfg.CPU,
+		Env:           cfg.Env,
+		Sysctls:       cfg.Sysctls,
+		Labels:        cfg.Labels,
+		Binds:         cfg.Binds,
+		PortBindings:  cfg.PortBindings,
+	})
+
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// inRootNetns returns true for the nodes whose interfaces reside in the host network namespace.
+func inRootNetns(n *types.NodeConfig) bool {
+	switch n.Kind {
+	case "bridge", "ovs-bridge", "host":
+		return true
+	}
+	return false
+}
+
+// inNodeNetns runs f in the network namespace of the node.
+func inNodeNetns(n *types.NodeConfig, f func() error) error {
+	if inRootNetns(n) {
+		return f()
+	}
+	if n.NSPath == "" {
+		return fmt.Errorf("network namespace of node %q is unknown", n.ShortName)
+	}
+
+	nodeNS, err := ns.GetNS(n.NSPath)
+	if err != nil {
+		return err
+	}
+	defer nodeNS.Close()
+
+	return nodeNS.Do(func(_ ns.NetNS) error { return f() })
+}
+
+// linkIfaceMatches returns true when the node interface link is of the kind the link l creates.
+func linkIfaceMatches(l *types.Link, link netlink.Link) bool {
+	switch l.Type {
+	// the host interface of a host-device link can be of any type
+	case types.LinkTypeHostDevice:
+		return true
+	// the node end of a vxlan link is a veth, which host end is stitched to the vxlan interface
+	case types.LinkTypeVxlan:
+		vxlanName, vethName := vxlanIfNames(l)
+		if _, err := netlink.LinkByName(vxlanName); err != nil {
+			return false
+		}
+		hostVeth, err := netlink.LinkByName(vethName)
+		return err == nil && link.Attrs().ParentIndex == hostVeth.Attrs().Index
+	}
+	return link.Type() == l.Type
+}
+
+// lookupLink returns the interface ifName of the node n or nil if it doesn't exist.
+func lookupLink(n *types.NodeConfig, ifName string) netlink.Link {
+	var link netlink.Link
+	_ = inNodeNetns(n, func() error {
+		l, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return err
+		}
+		link = l
+		return nil
+	})
+	return link
+}
+
+// lookupVeth returns the veth interface ifName of the node n or nil if it doesn't exist.
+func lookupVeth(n *types.NodeConfig, ifName string) *netlink.Veth {
+	veth, _ := lookupLink(n, ifName).(*netlink.Veth)
+	return veth
+}
+
+// vethPeers returns true when the veth interfaces a and b are the two ends of the same veth pair.
+// For veth interfaces the parent index holds the interface index of the peer.
+func vethPeers(a, b *netlink.Veth) bool {
+	return a.Attrs().ParentIndex == b.Attrs().Index && b.Attrs().ParentIndex == a.Attrs().Index
+}
+
+// clabVeths returns the names of the veth interfaces of the node n that were created by containerlab.
+func clabVeths(n *types.NodeConfig) []string {
+	var names []string
+	_ = inNodeNetns(n, func() error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			if l.Type() != "veth" {
+				continue
+			}
+			if strings.HasPrefix(l.Attrs().HardwareAddr.String(), ClabOUI) {
+				names = append(names, l.Attrs().Name)
+			}
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names
+}
+
+// deleteVeth removes the interface ifName of the node n, which also removes its veth peer.
+// A missing interface is not considered an error.
+func deleteVeth(n *types.NodeConfig, ifName string) error {
+	return inNodeNetns(n, func() error {
+		l, err := netlink.LinkByName(ifName)
+		if err != nil {
+			var notFound netlink.LinkNotFoundError
+			if errors.As(err, &notFound) {
+				return nil
+			}
+			return err
+		}
+		return netlink.LinkDel(l)
+	})
+}
@@ -0,0 +1,441 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/containernetworking/plugins/pkg/testutils"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/srl-labs/containerlab/mocks"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+	"github.com/vishvananda/netlink"
+)
+
+func TestNodeChanged(t *testing.T) {
+	cfg := &types.NodeConfig{
+		Kind:  "linux",
+		Image: "alpine:3",
+		Env:   map[string]string{"A": "1"},
+	}
+	hash := nodeConfigHash(cfg)
+	cfg.Labels = map[string]string{NodeConfigHashLabel: hash}
+
+	tests := map[string]struct {
+		ctr  *types.GenericContainer
+		want bool
+	}{
+		"same_hash": {
+			ctr: &types.GenericContainer{
+				Image:  "alpine:3",
+				Labels: map[string]string{NodeConfigHashLabel: hash},
+			},
+			want: false,
+		},
+		"different_hash": {
+			ctr: &types.GenericContainer{
+				Image:  "alpine:3",
+				Labels: map[string]string{NodeConfigHashLabel: "abc"},
+			},
+			want: true,
+		},
+		"no_hash_same_image": {
+			ctr: &types.GenericContainer{
+				Image:  "alpine:3",
+				Labels: map[string]string{},
+			},
+			want: false,
+		},
+		"no_hash_different_image": {
+			ctr: &types.GenericContainer{
+				Image:  "alpine:2",
+				Labels: map[string]string{},
+			},
+			want: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := nodeChanged(cfg, tc.ctr); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNodeConfigHash(t *testing.T) {
+	a := &types.NodeConfig{Image: "alpine:3", Env: map[string]string{"A": "1", "B": "2"}}
+	b := &types.NodeConfig{Image: "alpine:3", Env: map[string]string{"B": "2", "A": "1"}}
+
+	if nodeConfigHash(a) != nodeConfigHash(b) {
+		t.Error("expected equal hashes for equal configs")
+	}
+
+	b.Env["B"] = "3"
+	if nodeConfigHash(a) == nodeConfigHash(b) {
+		t.Error("expected different hashes for different configs")
+	}
+}
+
+func TestReconcilePlanString(t *testing.T) {
+	n1 := &types.NodeConfig{ShortName: "n1"}
+	n2 := &types.NodeConfig{ShortName: "n2"}
+
+	plan := &ReconcilePlan{
+		AddNodes:      []string{"n2"},
+		RecreateNodes: []string{"n1"},
+		DeleteNodes:   []string{"n3"},
+		AddLinks: map[int]*types.Link{
+			0: {
+				A: &types.Endpoint{Node: n1, EndpointName: "eth1"},
+				B: &types.Endpoint{Node: n2, EndpointName: "eth1"},
+			},
+		},
+		DeleteEndpoints: []*types.Endpoint{{Node: n1, EndpointName: "eth2"}},
+	}
+
+	want := `+ node n2
+~ node n1
+- node n3
++ link n1:eth1 <--> n2:eth1
+- interface n1:eth2
+`
+	if got := plan.String(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	if !(&ReconcilePlan{}).IsEmpty() {
+		t.Error("expected empty plan")
+	}
+}
+
+// reconcileTestNode returns the config of the linux node name labeled with its config hash.
+func reconcileTestNode(name, networkMode string) *types.NodeConfig {
+	cfg := &types.NodeConfig{
+		ShortName:   name,
+		LongName:    "clab-test-" + name,
+		Kind:        "linux",
+		Image:       "alpine:3",
+		NetworkMode: networkMode,
+	}
+	cfg.Labels = map[string]string{NodeConfigHashLabel: nodeConfigHash(cfg)}
+	return cfg
+}
+
+// reconcileTestContainer returns the container of the node name in the state with the config hash.
+func reconcileTestContainer(name, state, hash string) types.GenericContainer {
+	return types.GenericContainer{
+		Names: []string{"clab-test-" + name},
+		Image: "alpine:3",
+		State: state,
+		Labels: map[string]string{
+			ContainerlabLabel:   "test",
+			NodeNameLabel:       name,
+			NodeConfigHashLabel: hash,
+		},
+	}
+}
+
+// newReconcileTestLab returns the lab with the mocked nodes cfgs and the runtime listing the containers ctrs.
+func newReconcileTestLab(mockCtrl *gomock.Controller, cfgs []*types.NodeConfig, ctrs []types.GenericContainer) *CLab {
+	rt := mocks.NewMockContainerRuntime(mockCtrl)
+	rt.EXPECT().ListContainers(gomock.Any(), gomock.Any()).Return(ctrs, nil).AnyTimes()
+	rt.EXPECT().GetNSPath(gomock.Any(), gomock.Any()).Return("", errors.New("no netns")).AnyTimes()
+
+	c := &CLab{
+		Config:   &Config{Name: "test"},
+		m:        &sync.RWMutex{},
+		Nodes:    map[string]nodes.Node{},
+		Links:    map[int]*types.Link{},
+		Runtimes: map[string]runtime.ContainerRuntime{"docker": rt},
+	}
+	for _, cfg := range cfgs {
+		n := mocks.NewMockNode(mockCtrl)
+		n.EXPECT().Config().Return(cfg).AnyTimes()
+		n.EXPECT().GetRuntime().Return(rt).AnyTimes()
+		c.Nodes[cfg.ShortName] = n
+	}
+
+	return c
+}
+
+func TestPlanReconcileNodes(t *testing.T) {
+	n1 := reconcileTestNode("n1", "")
+	hash := n1.Labels[NodeConfigHashLabel]
+	// n2 shares the netns of n1 and n3 shares the netns of n2
+	n2 := reconcileTestNode("n2", "container:n1")
+	n3 := reconcileTestNode("n3", "container:n2")
+
+	tests := map[string]struct {
+		nodes        []*types.NodeConfig
+		ctrs         []types.GenericContainer
+		wantAdd      []string
+		wantRecreate []string
+		wantDelete   []string
+	}{
+		"unchanged": {
+			nodes: []*types.NodeConfig{n1},
+			ctrs:  []types.GenericContainer{reconcileTestContainer("n1", "running", hash)},
+		},
+		"added": {
+			nodes:   []*types.NodeConfig{n1, reconcileTestNode("n2", "")},
+			ctrs:    []types.GenericContainer{reconcileTestContainer("n1", "running", hash)},
+			wantAdd: []string{"n2"},
+		},
+		"config_changed": {
+			nodes:        []*types.NodeConfig{n1},
+			ctrs:         []types.GenericContainer{reconcileTestContainer("n1", "running", "old")},
+			wantRecreate: []string{"n1"},
+		},
+		"stopped": {
+			nodes:        []*types.NodeConfig{n1},
+			ctrs:         []types.GenericContainer{reconcileTestContainer("n1", "exited", hash)},
+			wantRecreate: []string{"n1"},
+		},
+		"removed": {
+			nodes: []*types.NodeConfig{n1},
+			ctrs: []types.GenericContainer{
+				reconcileTestContainer("n1", "running", hash),
+				reconcileTestContainer("n2", "running", hash),
+			},
+			wantDelete: []string{"n2"},
+		},
+		"network_mode_of_changed_node": {
+			nodes: []*types.NodeConfig{n1, n2, n3},
+			ctrs: []types.GenericContainer{
+				reconcileTestContainer("n1", "running", "old"),
+				reconcileTestContainer("n2", "running", n2.Labels[NodeConfigHashLabel]),
+				reconcileTestContainer("n3", "running", n3.Labels[NodeConfigHashLabel]),
+			},
+			wantRecreate: []string{"n1", "n2", "n3"},
+		},
+		"containerless_kinds": {
+			nodes: []*types.NodeConfig{
+				{ShortName: "br1", Kind: "bridge"},
+				{ShortName: "host1", Kind: "host"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			c := newReconcileTestLab(mockCtrl, tc.nodes, tc.ctrs)
+
+			plan, err := c.PlanReconcile(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d := cmp.Diff(tc.wantAdd, plan.AddNodes); d != "" {
+				t.Errorf("added nodes mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tc.wantRecreate, plan.RecreateNodes); d != "" {
+				t.Errorf("recreated nodes mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tc.wantDelete, plan.DeleteNodes); d != "" {
+				t.Errorf("deleted nodes mismatch (-want +got):\n%s", d)
+			}
+			for _, n := range tc.wantDelete {
+				if _, ok := plan.deleteContainers["clab-test-"+n]; !ok {
+					t.Errorf("expected container of node %q to be scheduled for removal", n)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanReconcileListError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	c := newReconcileTestLab(mockCtrl, nil, nil)
+	rt := mocks.NewMockContainerRuntime(mockCtrl)
+	rt.EXPECT().ListContainers(gomock.Any(), gomock.Any()).Return(nil, errors.New("runtime is down"))
+	c.Runtimes["docker"] = rt
+
+	if _, err := c.PlanReconcile(context.Background()); err == nil {
+		t.Error("expected error when containers can't be listed")
+	}
+}
+
+// vethPair is the veth link between the interface a of node n1 and the interface b of node n2.
+type vethPair struct{ a, b string }
+
+// planLinksNodes returns the nodes n1 and n2 with the network namespaces created for the test
+// and the veth pairs wired between them, it skips the test when they can't be created.
+func planLinksNodes(t *testing.T, wired []vethPair) (n1, n2 *types.NodeConfig) {
+	t.Helper()
+
+	if os.Geteuid() != 0 {
+		t.Skip("creating network namespaces requires root privileges")
+	}
+
+	cfgs := make([]*types.NodeConfig, 2)
+	for i := range cfgs {
+		nodeNS, err := testutils.NewNS()
+		if err != nil {
+			t.Skipf("failed to create network namespace: %v", err)
+		}
+		t.Cleanup(func() {
+			nodeNS.Close()
+			_ = testutils.UnmountNS(nodeNS)
+		})
+
+		cfgs[i] = &types.NodeConfig{
+			ShortName: fmt.Sprintf("n%d", i+1),
+			LongName:  fmt.Sprintf("clab-test-n%d", i+1),
+			Kind:      "linux",
+			NSPath:    nodeNS.Path(),
+		}
+	}
+
+	for _, p := range wired {
+		aMAC, _ := net.ParseMAC(utils.GenMac(ClabOUI))
+		bMAC, _ := net.ParseMAC(utils.GenMac(ClabOUI))
+		linkA, linkB, err := createVethIface("clab-plan0", "clab-plan1", 1500, aMAC, bMAC)
+		if err != nil {
+			t.Skipf("failed to create veth: %v", err)
+		}
+
+		for _, vEth := range []vEthEndpoint{
+			{Link: linkA, LinkName: p.a, NSPath: cfgs[0].NSPath},
+			{Link: linkB, LinkName: p.b, NSPath: cfgs[1].NSPath},
+		} {
+			if err := vEth.toNS(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return cfgs[0], cfgs[1]
+}
+
+func TestPlanLinks(t *testing.T) {
+	tests := map[string]struct {
+		// veth pairs that exist between the nodes
+		wired []vethPair
+		// veth links of the topology
+		links   []vethPair
+		changed []string
+		// indexes of the links to be created
+		wantAdd []int
+		// interfaces to be removed
+		wantDelete []string
+	}{
+		"unchanged": {
+			wired: []vethPair{{"eth1", "eth1"}, {"eth2", "eth2"}},
+			links: []vethPair{{"eth1", "eth1"}, {"eth2", "eth2"}},
+		},
+		"added": {
+			wired:   []vethPair{{"eth1", "eth1"}},
+			links:   []vethPair{{"eth1", "eth1"}, {"eth2", "eth2"}},
+			wantAdd: []int{1},
+		},
+		"removed": {
+			wired:      []vethPair{{"eth1", "eth1"}, {"eth2", "eth2"}},
+			links:      []vethPair{{"eth1", "eth1"}},
+			wantDelete: []string{"n1:eth2", "n2:eth2"},
+		},
+		"rewired": {
+			wired:      []vethPair{{"eth1", "eth1"}, {"eth2", "eth2"}},
+			links:      []vethPair{{"eth1", "eth2"}},
+			wantAdd:    []int{0},
+			wantDelete: []string{"n1:eth1", "n1:eth2", "n2:eth1", "n2:eth2"},
+		},
+		"node_changed": {
+			wired:      []vethPair{{"eth1", "eth1"}},
+			links:      []vethPair{{"eth1", "eth1"}},
+			changed:    []string{"n1"},
+			wantAdd:    []int{0},
+			wantDelete: []string{"n2:eth1"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			n1, n2 := planLinksNodes(t, tc.wired)
+			c := newReconcileTestLab(mockCtrl, []*types.NodeConfig{n1, n2}, nil)
+			for i, p := range tc.links {
+				c.Links[i] = &types.Link{
+					Type: types.LinkTypeVeth,
+					A:    &types.Endpoint{Node: n1, EndpointName: p.a},
+					B:    &types.Endpoint{Node: n2, EndpointName: p.b},
+				}
+			}
+
+			changed := map[string]struct{}{}
+			for _, n := range tc.changed {
+				changed[n] = struct{}{}
+			}
+
+			plan := &ReconcilePlan{AddLinks: map[int]*types.Link{}}
+			c.planLinks(plan, changed)
+
+			var gotAdd []int
+			for i := range plan.AddLinks {
+				gotAdd = append(gotAdd, i)
+			}
+			sort.Ints(gotAdd)
+			if d := cmp.Diff(tc.wantAdd, gotAdd); d != "" {
+				t.Errorf("added links mismatch (-want +got):\n%s", d)
+			}
+
+			var gotDelete []string
+			for _, e := range plan.DeleteEndpoints {
+				gotDelete = append(gotDelete, e.Node.ShortName+":"+e.EndpointName)
+			}
+			if d := cmp.Diff(tc.wantDelete, gotDelete); d != "" {
+				t.Errorf("removed interfaces mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPlanLinksUnknownNetns(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	n1, n2 := reconcileTestNode("n1", ""), reconcileTestNode("n2", "")
+	c := newReconcileTestLab(mockCtrl, []*types.NodeConfig{n1, n2}, nil)
+	c.Links[0] = &types.Link{
+		Type: types.LinkTypeVeth,
+		A:    &types.Endpoint{Node: n1, EndpointName: "eth1"},
+		B:    &types.Endpoint{Node: n2, EndpointName: "eth1"},
+	}
+	c.Links[1] = &types.Link{
+		Type: types.LinkTypeMacvlan,
+		A:    &types.Endpoint{Node: n2, EndpointName: "eth2"},
+		B:    &types.Endpoint{Node: specialEndpointNode("host"), EndpointName: "eth0"},
+	}
+
+	plan := &ReconcilePlan{AddLinks: map[int]*types.Link{}}
+	c.planLinks(plan, map[string]struct{}{"n1": {}})
+
+	// the interfaces of the nodes which netns is unknown can't be inspected, the links are created
+	if len(plan.AddLinks) != 2 {
+		t.Errorf("expected both links to be added, got %v", plan.AddLinks)
+	}
+	if len(plan.DeleteEndpoints) != 0 {
+		t.Errorf("expected no interfaces to be removed, got %v", plan.DeleteEndpoints)
+	}
+}
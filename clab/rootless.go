@@ -0,0 +1,51 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+// WithRootless deploys the lab with a rootless runtime, in whose user and network namespaces containerlab runs.
+// The external access to the management network is disabled, unless it is enabled in the topology.
+func WithRootless(rootless bool) ClabOption {
+	return func(c *CLab) error {
+		c.rootless = rootless
+		return nil
+	}
+}
+
+// verifyRootlessLinks checks that the links of the lab deployed with a rootless runtime
+// are wired within the network namespace of the runtime, since the host interfaces are out of its reach.
+func (c *CLab) verifyRootlessLinks() error {
+	if !c.rootless {
+		return nil
+	}
+
+	// the links are checked in a stable order, so that the same link is reported on every run
+	keys := make([]int, 0, len(c.Links))
+	for k := range c.Links {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	for _, k := range keys {
+		l := c.Links[k]
+		switch l.Type {
+		case types.LinkTypeMacvlan, types.LinkTypeHostDevice, types.LinkTypeVxlan:
+			return fmt.Errorf("%s: %s links are not supported in the rootless mode", l.String(), l.Type)
+		}
+		for _, e := range l.Endpoints() {
+			if e.Node.Kind == "host" {
+				return fmt.Errorf("%s: links to the host are not supported in the rootless mode", l.String())
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRootlessExternalAccess(t *testing.T) {
+	// external access enabled explicitly in the topology
+	enabledTopo := filepath.Join(t.TempDir(), "external-access.clab.yml")
+	err := os.WriteFile(enabledTopo, []byte(`name: external-access
+mgmt:
+  external-access: true
+topology:
+  nodes:
+    node1:
+      kind: linux
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		opts []ClabOption
+		want bool
+	}{
+		"rootful": {
+			opts: []ClabOption{WithTopoFile("test_data/topo1.yml", "")},
+			want: true,
+		},
+		"rootless": {
+			opts: []ClabOption{WithRootless(true), WithTopoFile("test_data/topo1.yml", "")},
+			want: false,
+		},
+		"rootless_set_after_topology": {
+			opts: []ClabOption{WithTopoFile("test_data/topo1.yml", ""), WithRootless(true)},
+			want: false,
+		},
+		"rootless_enabled_in_topology": {
+			opts: []ClabOption{WithRootless(true), WithTopoFile(enabledTopo, "")},
+			want: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewContainerLab(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := *c.Config.Mgmt.ExternalAccess; got != tt.want {
+				t.Errorf("ExternalAccess = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// SnapshotFileSuffix is the suffix of the lab snapshot archive files.
+	SnapshotFileSuffix = ".clab-snapshot.tgz"
+	// SnapshotVersion is the version of the lab snapshot format.
+	SnapshotVersion = 1
+
+	snapshotManifestFName = "manifest.json"
+	snapshotTopoFName     = "topology.clab.yml"
+	snapshotTimeFormat    = "20060102-150405"
+)
+
+// SnapshotManifest describes the content of a lab snapshot archive.
+// The paths in the manifest are relative to the root of the archive.
+type SnapshotManifest struct {
+	Version int       `json:"version"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	// Topology is the path of the resolved topology file the lab is restored from
+	Topology string `json:"topology"`
+	// LabDir is the path of the lab directory
+	LabDir string `json:"lab-dir"`
+	// CA is the path of the lab CA directory
+	CA    string                   `json:"ca,omitempty"`
+	Nodes map[string]*SnapshotNode `json:"nodes"`
+}
+
+// SnapshotNode describes the snapshot of a lab node.
+type SnapshotNode struct {
+	Kind string `json:"kind"`
+	// LabDir is the path of the node's lab directory
+	LabDir string `json:"lab-dir"`
+	// SavedConfig is the path of the saved node configuration used as the node's startup-config on restore
+	SavedConfig string `json:"saved-config,omitempty"`
+}
+
+// SnapshotFileName returns the name of the snapshot file of the lab labName created at t.
+func SnapshotFileName(labName string, t time.Time) string {
+	return labName + "-" + t.Format(snapshotTimeFormat) + SnapshotFileSuffix
+}
+
+// CreateSnapshot archives the lab directories of the nodes, the lab CA and the resolved topology
+// into the snapshot file fPath. Node configs are expected to be saved beforehand.
+func (c *CLab) CreateSnapshot(fPath string) (*SnapshotManifest, error) {
+	if !utils.DirExists(c.Dir.Lab) {
+		return nil, fmt.Errorf("lab directory %s not found, the lab is not deployed", c.Dir.Lab)
+	}
+
+	topoCfg, err := c.topologyConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	labDirName := filepath.Base(c.Dir.Lab)
+	m := &SnapshotManifest{
+		Version:  SnapshotVersion,
+		Name:     c.Config.Name,
+		Created:  time.Now(),
+		Topology: snapshotTopoFName,
+		LabDir:   labDirName,
+		Nodes:    make(map[string]*SnapshotNode, len(c.Nodes)),
+	}
+
+	// dirs are the directories archived in the snapshot
+	var dirs []string
+	if utils.DirExists(c.Dir.LabCA) {
+		m.CA = filepath.Join(labDirName, filepath.Base(c.Dir.LabCA))
+		dirs = append(dirs, c.Dir.LabCA)
+	}
+
+	for name, n := range c.Nodes {
+		cfg := n.Config()
+		if cfg.LabDir == "" || !utils.DirExists(cfg.LabDir) {
+			continue
+		}
+
+		rel, err := filepath.Rel(c.Dir.Lab, cfg.LabDir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("lab directory %s of node %q is outside of the lab directory %s", cfg.LabDir, name, c.Dir.Lab)
+		}
+
+		sn := &SnapshotNode{
+			Kind:   cfg.Kind,
+			LabDir: filepath.Join(labDirName, rel),
+		}
+		if p := n.SavedConfigPath(); p != "" && utils.FileExists(p) {
+			if rel, err := filepath.Rel(c.Dir.Lab, p); err == nil {
+				sn.SavedConfig = filepath.Join(labDirName, rel)
+				// the restored topology references the saved config relative to the topology file
+				if nodeDef, ok := topoCfg.Topology.Nodes[name]; ok && nodeDef != nil {
+					nodeDef.StartupConfig = sn.SavedConfig
+				}
+			}
+		}
+
+		m.Nodes[name] = sn
+		dirs = append(dirs, cfg.LabDir)
+	}
+
+	topoBytes, err := yaml.Marshal(topoCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the topology: %v", err)
+	}
+
+	f, err := os.Create(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	manifestBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	// manifest goes first, so that it can be read without reading the whole archive
+	if err := writeTarFile(tw, snapshotManifestFName, manifestBytes); err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, snapshotTopoFName, topoBytes); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(dirs)
+	for _, d := range dirs {
+		log.Debugf("Adding %s to the snapshot", d)
+		if err := writeTarDir(tw, d, c.Dir.Lab); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return m, f.Close()
+}
+
+// ReadSnapshotManifest reads the manifest of the snapshot file fPath.
+func ReadSnapshotManifest(fPath string) (*SnapshotManifest, error) {
+	f, err := os.Open(fPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", fPath, err)
+	}
+	tr := tar.NewReader(gr)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %v", fPath, err)
+	}
+	if hdr.Name != snapshotManifestFName {
+		return nil, fmt.Errorf("snapshot %s doesn't start with the manifest", fPath)
+	}
+
+	m := &SnapshotManifest{}
+	if err := json.NewDecoder(tr).Decode(m); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest of snapshot %s: %v", fPath, err)
+	}
+
+	if m.Version < 1 || m.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported version %d of snapshot %s", m.Version, fPath)
+	}
+
+	return m, nil
+}
+
+// ListSnapshots returns the manifests of the snapshot files found in the directory dir keyed by the file path.
+// When labName is not empty, only the snapshots of that lab are returned.
+func ListSnapshots(dir, labName string) (map[string]*SnapshotManifest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+SnapshotFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string]*SnapshotManifest, len(files))
+	for _, fPath := range files {
+		m, err := ReadSnapshotManifest(fPath)
+		if err != nil {
+			log.Warnf("Skipping snapshot: %v", err)
+			continue
+		}
+		if labName != "" && m.Name != labName {
+			continue
+		}
+		snapshots[fPath] = m
+	}
+
+	return snapshots, nil
+}
+
+// RestoreSnapshot extracts the snapshot file fPath into the directory dir, which becomes
+// the parent directory of the restored lab directory, and returns the path of the restored topology file.
+// The topology file is named after the lab and has the nodes' startup-config set to their saved configs.
+func RestoreSnapshot(fPath, dir string) (string, error) {
+	m, err := ReadSnapshotManifest(fPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range []string{m.LabDir, m.Name} {
+		if name == "" || name == "." || name == ".." || strings.ContainsRune(name, os.PathSeparator) {
+			return "", fmt.Errorf("snapshot %s contains illegal lab name or directory %q", fPath, name)
+		}
+	}
+
+	// symlinks of dir are resolved to check the paths of the extracted files
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	labDir := filepath.Join(dir, m.LabDir)
+	if _, err := os.Stat(labDir); err == nil {
+		return "", fmt.Errorf("lab directory %s already exists, destroy the lab with --cleanup flag before restoring it", labDir)
+	}
+
+	topoPath := filepath.Join(dir, m.Name+".clab.yml")
+	if _, err := os.Stat(topoPath); err == nil {
+		return "", fmt.Errorf("topology file %s already exists", topoPath)
+	}
+
+	f, err := os.Open(fPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	tr := tar.NewReader(gr)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read snapshot %s: %v", fPath, err)
+		}
+
+		var dst, root string
+		switch {
+		case hdr.Name == snapshotManifestFName:
+			continue
+		case hdr.Name == m.Topology:
+			if hdr.Typeflag != tar.TypeReg {
+				return "", fmt.Errorf("snapshot %s topology file %s is not a regular file", fPath, hdr.Name)
+			}
+			dst, root = topoPath, dir
+		// only the lab directory content is extracted
+		case strings.HasPrefix(hdr.Name, m.LabDir+"/"):
+			dst, root = filepath.Join(dir, hdr.Name), labDir
+			if !isWithinDir(dst, labDir) || dst == labDir {
+				return "", fmt.Errorf("snapshot %s contains illegal file path %s", fPath, hdr.Name)
+			}
+		default:
+			log.Debugf("Skipping unexpected snapshot file %s", hdr.Name)
+			continue
+		}
+
+		if err := extractTarEntry(tr, hdr, dst, root); err != nil {
+			return "", fmt.Errorf("failed to extract %s from snapshot %s: %v", hdr.Name, fPath, err)
+		}
+	}
+
+	return topoPath, nil
+}
+
+// topologyConfig returns a copy of the lab config as read from the topology file.
+// For the labs loaded from the lab state, the topology file the lab was deployed from is read.
+func (c *CLab) topologyConfig() (*Config, error) {
+	if c.state == nil {
+		return copyConfig(c.Config)
+	}
+
+	if c.TopoFile.path == "" || !utils.FileExists(c.TopoFile.path) {
+		return nil, fmt.Errorf("topology file %q of lab %q is not found", c.TopoFile.path, c.Config.Name)
+	}
+
+	tc := &CLab{
+		Config: &Config{
+			Mgmt:     new(types.MgmtNet),
+			Topology: types.NewTopology(),
+		},
+	}
+	if err := tc.GetTopology(c.TopoFile.path, ""); err != nil {
+		return nil, err
+	}
+
+	return tc.Config, nil
+}
+
+// copyConfig returns a deep copy of the lab config.
+func copyConfig(cfg *Config) (*Config, error) {
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+	err = yaml.Unmarshal(b, c)
+
+	return c, err
+}
+
+// writeTarFile writes a regular file named name with the content b to tw.
+func writeTarFile(tw *tar.Writer, name string, b []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(b)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
+// writeTarDir recursively writes the directory dir of the lab directory labDir to tw.
+// The names of the archived files are relative to the parent of the lab directory.
+// Symlinks are archived with the targets relative to the symlink, the symlinks with the targets
+// outside of the lab directory are skipped, since they are not extracted on restore.
+func writeTarDir(tw *tar.Writer, dir, labDir string) error {
+	base := filepath.Dir(labDir)
+
+	return filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var link string
+		switch {
+		case fi.Mode().IsRegular(), fi.IsDir():
+		case fi.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+			target := link
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(p), target)
+			}
+			if !isWithinDir(filepath.Clean(target), labDir) {
+				log.Warnf("Skipping symlink %s with target %s outside of the lab directory", p, link)
+				return nil
+			}
+			if filepath.IsAbs(link) {
+				if link, err = filepath.Rel(filepath.Dir(p), target); err != nil {
+					return err
+				}
+			}
+		default:
+			// sockets, pipes and devices are skipped
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// extractTarEntry extracts the archive entry hdr read from tr to the path dst.
+// The entry must not resolve outside of the directory root, which has its symlinks resolved,
+// neither through the symlinks extracted before, nor through the symlink it creates.
+func extractTarEntry(tr *tar.Reader, hdr *tar.Header, dst, root string) error {
+	mode := os.FileMode(hdr.Mode).Perm()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(dst))
+	if err != nil {
+		return err
+	}
+	if !isWithinDir(parent, root) {
+		return fmt.Errorf("path %s resolves outside of %s", dst, root)
+	}
+	dst = filepath.Join(parent, filepath.Base(dst))
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(dst, mode); err != nil {
+			return err
+		}
+		// dst could be an existing symlink
+		rdst, err := filepath.EvalSymlinks(dst)
+		if err != nil {
+			return err
+		}
+		if !isWithinDir(rdst, root) {
+			return fmt.Errorf("path %s resolves outside of %s", dst, root)
+		}
+	case tar.TypeSymlink:
+		if filepath.IsAbs(hdr.Linkname) {
+			return fmt.Errorf("symlink %s has absolute target %s", dst, hdr.Linkname)
+		}
+		if !isWithinDir(filepath.Join(parent, hdr.Linkname), root) {
+			return fmt.Errorf("symlink %s target %s resolves outside of %s", dst, hdr.Linkname, root)
+		}
+		return os.Symlink(hdr.Linkname, dst)
+	case tar.TypeReg:
+		// dst itself is not followed if it is a symlink
+		f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|syscall.O_NOFOLLOW, mode)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(f, tr); err != nil { // skipcq: GSC-G110
+			return err
+		}
+		return f.Close()
+	}
+
+	return nil
+}
+
+// isWithinDir returns true if the cleaned path p is the directory dir or is within it.
+func isWithinDir(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+string(os.PathSeparator))
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/utils"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	t.Setenv("PWD", t.TempDir())
+
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(c.Nodes["lin1"].Config().LabDir, "config", "cfg.txt"): "lin1 config",
+		filepath.Join(c.Dir.LabCARoot, "root-ca.pem"):                       "root ca",
+	}
+	for p, content := range files {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := utils.CreateFile(p, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fPath := filepath.Join(t.TempDir(), SnapshotFileName(c.Config.Name, time.Now()))
+	m, err := c.CreateSnapshot(fPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.Nodes["lin1"]; !ok || len(m.Nodes) != 1 {
+		t.Errorf("expected only lin1 node in the snapshot, got %v", m.Nodes)
+	}
+	if m.CA != "clab-topo11/ca" {
+		t.Errorf("expected CA path clab-topo11/ca, got %q", m.CA)
+	}
+
+	snapshots, err := ListSnapshots(filepath.Dir(fPath), "topo11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm, ok := snapshots[fPath]; !ok || rm.Name != "topo11" {
+		t.Fatalf("expected snapshot %s of lab topo11 to be listed, got %v", fPath, snapshots)
+	}
+
+	restoreDir := t.TempDir()
+	topoPath, err := RestoreSnapshot(fPath, restoreDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := map[string]string{
+		filepath.Join(restoreDir, "clab-topo11", "lin1", "config", "cfg.txt"): "lin1 config",
+		filepath.Join(restoreDir, "clab-topo11", "ca", "root", "root-ca.pem"): "root ca",
+	}
+	for p, want := range restored {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("file %s: expected %q, got %q", p, want, got)
+		}
+	}
+
+	rc := &CLab{Config: &Config{}}
+	if err := rc.GetTopology(topoPath, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.Config.Topology.Nodes) != 3 || len(rc.Config.Topology.Links) != 3 {
+		t.Errorf("expected 3 nodes and 3 links in the restored topology, got %d and %d",
+			len(rc.Config.Topology.Nodes), len(rc.Config.Topology.Links))
+	}
+
+	if _, err := RestoreSnapshot(fPath, restoreDir); err == nil {
+		t.Error("expected error restoring over an existing lab directory")
+	}
+}
+
+// writeTestSnapshot writes a snapshot of lab test with the manifest and the archive entries hdrs.
+// Regular file entries have their name as content.
+func writeTestSnapshot(t *testing.T, fPath string, hdrs []*tar.Header) {
+	t.Helper()
+
+	f, err := os.Create(fPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	m, err := json.Marshal(&SnapshotManifest{
+		Version:  SnapshotVersion,
+		Name:     "test",
+		Topology: "clab-test/topology.clab.yml",
+		LabDir:   "clab-test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdrs = append([]*tar.Header{{Name: snapshotManifestFName, Typeflag: tar.TypeReg, Size: int64(len(m))}}, hdrs...)
+	for i, hdr := range hdrs {
+		content := []byte(hdr.Name)
+		if i == 0 {
+			content = m
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Mode, hdr.Size = 0644, int64(len(content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(content); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreMaliciousSnapshot(t *testing.T) {
+	tests := map[string][]*tar.Header{
+		"absolute_symlink": {
+			{Name: "clab-test/escape", Typeflag: tar.TypeSymlink, Linkname: "/tmp"},
+		},
+		"relative_symlink_outside": {
+			{Name: "clab-test/node/escape", Typeflag: tar.TypeSymlink, Linkname: "../../.."},
+		},
+		"file_through_symlink": {
+			{Name: "clab-test/self", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "clab-test/up", Typeflag: tar.TypeSymlink, Linkname: "self/.."},
+			{Name: "clab-test/up/pwned", Typeflag: tar.TypeReg},
+		},
+		"dotdot_path": {
+			{Name: "clab-test/../pwned", Typeflag: tar.TypeReg},
+		},
+		"symlink_topology": {
+			{Name: "clab-test/topology.clab.yml", Typeflag: tar.TypeSymlink, Linkname: "../pwned"},
+		},
+	}
+
+	for name, hdrs := range tests {
+		t.Run(name, func(t *testing.T) {
+			fPath := filepath.Join(t.TempDir(), "test.clab-snapshot.tgz")
+			writeTestSnapshot(t, fPath, hdrs)
+
+			// the lab directory parent is nested to detect the files written outside of it
+			dir := filepath.Join(t.TempDir(), "labs")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := RestoreSnapshot(fPath, dir); err == nil {
+				t.Error("expected error restoring malicious snapshot")
+			}
+
+			if utils.FileExists(filepath.Join(filepath.Dir(dir), "pwned")) ||
+				utils.FileExists(filepath.Join(dir, "pwned")) {
+				t.Error("file written outside of the lab directory")
+			}
+		})
+	}
+}
+
+func TestSnapshotSymlinks(t *testing.T) {
+	t.Setenv("PWD", t.TempDir())
+
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfgDir := filepath.Join(c.Nodes["lin1"].Config().LabDir, "config")
+	if err := os.MkdirAll(cfgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.CreateFile(filepath.Join(cfgDir, "cfg.txt"), "lin1 config"); err != nil {
+		t.Fatal(err)
+	}
+
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := utils.CreateFile(outside, "outside"); err != nil {
+		t.Fatal(err)
+	}
+
+	symlinks := map[string]string{
+		"absolute":         filepath.Join(cfgDir, "cfg.txt"),
+		"relative":         "cfg.txt",
+		"absolute_outside": outside,
+		"relative_outside": "../../../outside.txt",
+	}
+	for name, target := range symlinks {
+		if err := os.Symlink(target, filepath.Join(cfgDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fPath := filepath.Join(t.TempDir(), SnapshotFileName(c.Config.Name, time.Now()))
+	if _, err := c.CreateSnapshot(fPath); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreDir := t.TempDir()
+	if _, err := RestoreSnapshot(fPath, restoreDir); err != nil {
+		t.Fatal(err)
+	}
+	restoredCfgDir := filepath.Join(restoreDir, "clab-topo11", "lin1", "config")
+
+	// the absolute symlinks within the lab directory are restored relative to the symlink
+	for _, name := range []string{"absolute", "relative"} {
+		p := filepath.Join(restoredCfgDir, name)
+		link, err := os.Readlink(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link != "cfg.txt" {
+			t.Errorf("symlink %s: expected target cfg.txt, got %s", name, link)
+		}
+		if b, err := os.ReadFile(p); err != nil || string(b) != "lin1 config" {
+			t.Errorf("symlink %s: expected to read %q, got %q (%v)", name, "lin1 config", b, err)
+		}
+	}
+
+	// the symlinks outside of the lab directory are not archived
+	for _, name := range []string{"absolute_outside", "relative_outside"} {
+		if _, err := os.Lstat(filepath.Join(restoredCfgDir, name)); !os.IsNotExist(err) {
+			t.Errorf("symlink %s: expected to be skipped, got %v", name, err)
+		}
+	}
+}
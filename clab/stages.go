@@ -0,0 +1,109 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// reachWaitedStages reaches the stages of the created node which other nodes wait for with their wait-for dependencies.
+// The node becomes healthy in the background until ctx is done, the dependent nodes wait for it within their timeouts.
+// The post-deploy actions of the node are run right away instead of once all the nodes are created,
+// wg is done when they finish and their error is collected in res.
+func (c *CLab) reachWaitedStages(ctx context.Context, node nodes.Node, dm DependencyManager,
+	res *DeployResult, wg *sync.WaitGroup,
+) {
+	name := node.Config().ShortName
+
+	if dm.HasDependers(name, types.WaitForStageHealthy) {
+		go func() {
+			log.Infof("Waiting for node %q to become healthy", name)
+			if err := nodes.WaitHealthy(ctx, node); err != nil {
+				log.Warnf("node %q failed to become healthy: %v", name, err)
+				dm.SignalFailed(name, types.WaitForStageHealthy)
+				return
+			}
+			log.Infof("Node %q is healthy", name)
+			dm.SignalDone(name, types.WaitForStageHealthy)
+		}()
+	}
+
+	if !dm.HasDependers(name, types.WaitForStagePostDeploy) {
+		return
+	}
+	if c.skipPostDeploy {
+		dm.SignalDone(name, types.WaitForStagePostDeploy)
+		return
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		// the management addresses assigned by the runtime are used by the post-deploy actions
+		if err := c.updateRuntimeInfo(ctx, node); err != nil {
+			log.Debugf("failed to update runtime information of node %q: %v", name, err)
+		}
+
+		// the other nodes are still deployed, so the post-deploy actions read the snapshot of their configs
+		err := node.PostDeploy(ctx, c.nodesSnapshot())
+
+		c.m.Lock()
+		if c.postDeployed == nil {
+			c.postDeployed = map[string]struct{}{}
+		}
+		c.postDeployed[name] = struct{}{}
+		c.m.Unlock()
+
+		if err != nil {
+			log.Errorf("failed to run postdeploy task for node %s: %v", name, err)
+			res.AddNodeError(node, StagePostDeploy, err)
+			dm.SignalFailed(name, types.WaitForStagePostDeploy)
+			return
+		}
+		dm.SignalDone(name, types.WaitForStagePostDeploy)
+	}()
+}
+
+// PostDeployed returns true when the node already ran its post-deploy actions during its deployment,
+// which is the case for the nodes other nodes wait for to finish the post-deploy actions.
+func (c *CLab) PostDeployed(name string) bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	_, ok := c.postDeployed[name]
+	return ok
+}
+
+// nodeSnapshot is a lab node with the copy of its config.
+type nodeSnapshot struct {
+	nodes.Node
+	cfg *types.NodeConfig
+}
+
+// Config returns the copy of the node config.
+func (n *nodeSnapshot) Config() *types.NodeConfig {
+	return n.cfg
+}
+
+// nodesSnapshot returns the lab nodes with the copies of their configs taken under the lab lock,
+// which are not changed by the deployment workers updating the runtime information of the nodes.
+func (c *CLab) nodesSnapshot() map[string]nodes.Node {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	ns := make(map[string]nodes.Node, len(c.Nodes))
+	for name, n := range c.Nodes {
+		cfg := *n.Config()
+		ns[name] = &nodeSnapshot{Node: n, cfg: &cfg}
+	}
+
+	return ns
+}
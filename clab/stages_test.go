@@ -0,0 +1,129 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/srl-labs/containerlab/mocks"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func Test_reachWaitedStages(t *testing.T) {
+	tests := map[string]struct {
+		stage          string
+		healthErr      error
+		postDeployErr  error
+		skipPostDeploy bool
+		wantErr        bool
+		wantFailed     bool
+		wantPostDeploy bool
+	}{
+		"healthy": {
+			stage: types.WaitForStageHealthy,
+		},
+		"unhealthy": {
+			stage:     types.WaitForStageHealthy,
+			healthErr: errors.New("connection refused"),
+			wantErr:   true,
+		},
+		"post_deploy": {
+			stage:          types.WaitForStagePostDeploy,
+			wantPostDeploy: true,
+		},
+		"post_deploy_failed": {
+			stage:          types.WaitForStagePostDeploy,
+			postDeployErr:  errors.New("failed to generate certificate"),
+			wantErr:        true,
+			wantFailed:     true,
+			wantPostDeploy: true,
+		},
+		"post_deploy_skipped": {
+			stage:          types.WaitForStagePostDeploy,
+			skipPostDeploy: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			node := mocks.NewMockNode(mockCtrl)
+			node.EXPECT().Config().Return(
+				&types.NodeConfig{
+					ShortName:   "node1",
+					Healthcheck: &types.HealthcheckConfig{Exec: "true", Interval: "10ms", Retries: 1},
+				},
+			).AnyTimes()
+			node.EXPECT().CheckHealth(gomock.Any()).Return(tt.healthErr).AnyTimes()
+			if tt.wantPostDeploy {
+				node.EXPECT().UpdateConfigWithRuntimeInfo(gomock.Any()).Return(nil)
+				node.EXPECT().PostDeploy(gomock.Any(), gomock.Any()).Return(tt.postDeployErr)
+			}
+
+			// node2 waits for node1 to reach the stage
+			dm := NewDependencyManager()
+			dm.AddNode("node1")
+			dm.AddNode("node2")
+			if err := dm.AddStageDependency("node1", tt.stage, "node2", types.WaitForStageCreate, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			c := &CLab{
+				m:              &sync.RWMutex{},
+				skipPostDeploy: tt.skipPostDeploy,
+			}
+			res := NewDeployResult(OnErrorContinue)
+			wg := &sync.WaitGroup{}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			c.reachWaitedStages(ctx, node, dm, res, wg)
+
+			// the waiting node is released when node1 fails to reach the stage, not after the wait times out
+			err := dm.WaitForNodeDependencies(ctx, "node2", types.WaitForStageCreate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WaitForNodeDependencies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			wg.Wait()
+			if got := res.Failed("node1"); got != tt.wantFailed {
+				t.Errorf("Failed() = %v, want %v", got, tt.wantFailed)
+			}
+			if got := c.PostDeployed("node1"); got != tt.wantPostDeploy {
+				t.Errorf("PostDeployed() = %v, want %v", got, tt.wantPostDeploy)
+			}
+		})
+	}
+}
+
+func Test_nodesSnapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := &types.NodeConfig{ShortName: "node1", MgmtIPv4Address: "172.20.20.2"}
+	node := mocks.NewMockNode(mockCtrl)
+	node.EXPECT().Config().Return(cfg).AnyTimes()
+
+	c := &CLab{
+		m:     &sync.RWMutex{},
+		Nodes: map[string]nodes.Node{"node1": node},
+	}
+
+	ns := c.nodesSnapshot()
+	cfg.MgmtIPv4Address = "172.20.20.3"
+
+	if got := ns["node1"].Config().MgmtIPv4Address; got != "172.20.20.2" {
+		t.Errorf("snapshot MgmtIPv4Address = %q, want %q", got, "172.20.20.2")
+	}
+}
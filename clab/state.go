@@ -0,0 +1,314 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+const (
+	// LabStateFName is the name of the lab state file written to the lab directory.
+	LabStateFName = "lab-state.json"
+	// LabStateVersion is the version of the lab state file format.
+	LabStateVersion = 1
+)
+
+// LabState is a machine-readable representation of a deployed lab.
+// It holds the resolved nodes and links configuration, so that the lab can be
+// restored without the topology file it was deployed from.
+type LabState struct {
+	Version  int            `json:"version"`
+	Name     string         `json:"name"`
+	Prefix   string         `json:"prefix"`
+	TopoFile string         `json:"topo-file,omitempty"`
+	Runtime  string         `json:"runtime,omitempty"`
+	Mgmt     *types.MgmtNet `json:"mgmt,omitempty"`
+	// Nodes is a map of node names to their resolved configuration.
+	Nodes map[string]*types.NodeConfig `json:"nodes,omitempty"`
+	// NodeRuntimes is a map of node names to the names of the runtimes they are deployed with.
+	NodeRuntimes map[string]string  `json:"node-runtimes,omitempty"`
+	Links        map[int]*LinkState `json:"links,omitempty"`
+	// IPAM is a map of IPAM pool names to the subnets allocated from them to links and nodes.
+	IPAM map[string]map[string]string `json:"ipam,omitempty"`
+	// CertificateAuthority is the configuration of the lab root CA
+	CertificateAuthority *types.CertificateAuthority `json:"certificate-authority,omitempty"`
+	// Hooks are the lab hooks, which are run on destroy
+	Hooks *types.Hooks `json:"hooks,omitempty"`
+}
+
+// LinkState is a state representation of types.Link.
+type LinkState struct {
+	// Type of the link, veth links of the lab states written before the link types were introduced have no type
+	Type   string                 `json:"type,omitempty"`
+	A      *EndpointState         `json:"a"`
+	B      *EndpointState         `json:"b,omitempty"`
+	MTU    int                    `json:"mtu,omitempty"`
+	Labels map[string]string      `json:"labels,omitempty"`
+	Vars   map[string]interface{} `json:"vars,omitempty"`
+	// Impairment is the netem impairment of the link
+	Impairment *types.LinkImpairment `json:"impairment,omitempty"`
+	// MacvlanMode is the mode of the macvlan link
+	MacvlanMode string `json:"macvlan-mode,omitempty"`
+}
+
+// EndpointState is a state representation of types.Endpoint.
+type EndpointState struct {
+	Node      string `json:"node"`
+	Interface string `json:"interface"`
+	MAC       string `json:"mac,omitempty"`
+	IPv4      string `json:"ipv4,omitempty"`
+	IPv6      string `json:"ipv6,omitempty"`
+	MTU       int    `json:"mtu,omitempty"`
+}
+
+// WithLabState loads the lab from the state file found in the lab directory labDir
+// instead of the topology file.
+func WithLabState(labDir string) ClabOption {
+	return func(c *CLab) error {
+		s, err := ReadLabState(labDir)
+		if err != nil {
+			return err
+		}
+
+		c.state = s
+		c.Config.Name = s.Name
+		c.Config.Prefix = &s.Prefix
+		if s.Mgmt != nil {
+			c.Config.Mgmt = s.Mgmt
+		}
+		// the runtime the lab was deployed with is used unless set explicitly
+		c.globalRuntime = s.Runtime
+		c.TopoFile = newTopoFile(s.TopoFile)
+
+		labDir, err = filepath.Abs(labDir)
+		if err != nil {
+			return err
+		}
+		c.Dir = newDirectory(labDir)
+
+		return nil
+	}
+}
+
+// ReadLabState reads the lab state file from the lab directory labDir.
+func ReadLabState(labDir string) (*LabState, error) {
+	fPath := filepath.Join(labDir, LabStateFName)
+
+	b, err := os.ReadFile(fPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("lab state file %s not found, the lab is not deployed or the topology file should be provided", fPath)
+		}
+		return nil, err
+	}
+
+	s := &LabState{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("failed to parse lab state file %s: %v", fPath, err)
+	}
+
+	if s.Version < 1 || s.Version > LabStateVersion {
+		return nil, fmt.Errorf("unsupported version %d of the lab state file %s", s.Version, fPath)
+	}
+
+	return s, nil
+}
+
+// WriteLabState writes the state of the lab to the lab directory.
+func (c *CLab) WriteLabState() error {
+	s := &LabState{
+		Version:      LabStateVersion,
+		Name:         c.Config.Name,
+		TopoFile:     c.TopoFile.path,
+		Runtime:      c.globalRuntime,
+		Mgmt:         c.Config.Mgmt,
+		Nodes:        make(map[string]*types.NodeConfig, len(c.Nodes)),
+		NodeRuntimes: make(map[string]string, len(c.Nodes)),
+		Links:        make(map[int]*LinkState, len(c.Links)),
+		IPAM:         c.ipamAllocations,
+
+		CertificateAuthority: c.Config.CertificateAuthority,
+		Hooks:                c.Config.Hooks,
+	}
+
+	if c.Config.Prefix != nil {
+		s.Prefix = *c.Config.Prefix
+	}
+
+	for name, n := range c.Nodes {
+		s.Nodes[name] = n.Config()
+		if r := n.GetRuntime(); r != nil {
+			s.NodeRuntimes[name] = r.GetName()
+		}
+	}
+
+	for i, l := range c.Links {
+		s.Links[i] = &LinkState{
+			Type:        l.Type,
+			A:           newEndpointState(l.A),
+			MTU:         l.MTU,
+			Labels:      l.Labels,
+			Vars:        l.Vars,
+			Impairment:  l.Impairment,
+			MacvlanMode: l.MacvlanMode,
+		}
+		// dummy links have a single endpoint
+		if l.B != nil {
+			s.Links[i].B = newEndpointState(l.B)
+		}
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fPath := filepath.Join(c.Dir.Lab, LabStateFName)
+	log.Debugf("Writing lab state file %s", fPath)
+
+	return utils.CreateFile(fPath, string(b))
+}
+
+// loadLabState populates lab nodes and links from the lab state.
+func (c *CLab) loadLabState() error {
+	s := c.state
+	log.Infof("Loading lab %q from the state file: %s", s.Name, filepath.Join(c.Dir.Lab, LabStateFName))
+
+	if err := c.initRuntimes(s.NodeRuntimes); err != nil {
+		return err
+	}
+
+	c.Nodes = make(map[string]nodes.Node, len(s.Nodes))
+	c.Links = make(map[int]*types.Link, len(s.Links))
+	c.ipamAllocations = s.IPAM
+	c.Config.CertificateAuthority = s.CertificateAuthority
+	c.Config.Hooks = s.Hooks
+
+	nodeNames := make([]string, 0, len(s.Nodes))
+	for name := range s.Nodes {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+
+	for _, name := range nodeNames {
+		cfg := s.Nodes[name]
+
+		nodeInitializer, ok := nodes.Nodes[cfg.Kind]
+		if !ok {
+			return fmt.Errorf("node %q refers to a kind %q which is not supported", name, cfg.Kind)
+		}
+		n := nodeInitializer()
+
+		// kinds amend the node config during the init, thus the node is initialized
+		// with a copy of the config, which is then replaced with the resolved one
+		initCfg, err := copyNodeConfig(cfg)
+		if err != nil {
+			return err
+		}
+
+		err = n.Init(initCfg, nodes.WithRuntime(c.Runtimes[s.NodeRuntimes[name]]), nodes.WithMgmtNet(c.Config.Mgmt))
+		if err != nil {
+			return fmt.Errorf("failed to initialize node %q: %v", name, err)
+		}
+		*n.Config() = *cfg
+
+		c.Nodes[name] = n
+	}
+
+	for i, ls := range s.Links {
+		l := &types.Link{
+			Type:        ls.Type,
+			MTU:         ls.MTU,
+			Labels:      ls.Labels,
+			Vars:        ls.Vars,
+			Impairment:  ls.Impairment,
+			MacvlanMode: ls.MacvlanMode,
+		}
+		if l.Type == "" {
+			l.Type = types.LinkTypeVeth
+		}
+
+		var err error
+		if l.A, err = c.endpointFromState(ls.A); err != nil {
+			return err
+		}
+		if l.Type != types.LinkTypeDummy {
+			if l.B, err = c.endpointFromState(ls.B); err != nil {
+				return err
+			}
+		}
+		if l.Type == types.LinkTypeVxlan {
+			if l.Vxlan, err = types.ParseVxlanParams(l.B.EndpointName); err != nil {
+				return fmt.Errorf("invalid vxlan link %s in the lab state: %v", l, err)
+			}
+		}
+
+		c.Links[i] = l
+	}
+
+	return nil
+}
+
+// newEndpointState returns the state representation of an endpoint.
+func newEndpointState(e *types.Endpoint) *EndpointState {
+	return &EndpointState{
+		Node:      e.Node.ShortName,
+		Interface: e.EndpointName,
+		MAC:       e.MAC,
+		IPv4:      e.IPv4,
+		IPv6:      e.IPv6,
+		MTU:       e.MTU,
+	}
+}
+
+// endpointFromState creates an endpoint from its state representation
+// and adds it to the endpoints of the referenced node.
+func (c *CLab) endpointFromState(es *EndpointState) (*types.Endpoint, error) {
+	if es == nil {
+		return nil, fmt.Errorf("link endpoint is missing in the lab state")
+	}
+
+	e := &types.Endpoint{
+		EndpointName: es.Interface,
+		MAC:          es.MAC,
+		IPv4:         es.IPv4,
+		IPv6:         es.IPv6,
+		MTU:          es.MTU,
+		Node:         specialEndpointNode(es.Node),
+	}
+
+	if e.Node == nil {
+		n, ok := c.Nodes[es.Node]
+		if !ok {
+			return nil, fmt.Errorf("link endpoint %s:%s refers to an unknown node", es.Node, es.Interface)
+		}
+		e.Node = n.Config()
+		n.Config().Endpoints = append(n.Config().Endpoints, *e)
+	}
+
+	return e, nil
+}
+
+// copyNodeConfig returns a deep copy of the node config.
+func copyNodeConfig(cfg *types.NodeConfig) (*types.NodeConfig, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &types.NodeConfig{}
+	err = json.Unmarshal(b, c)
+
+	return c, err
+}
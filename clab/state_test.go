@@ -0,0 +1,106 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestLabStateRoundTrip(t *testing.T) {
+	c, err := NewContainerLab(WithTopoFile("test_data/topo11.yml", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Dir = newDirectory(t.TempDir())
+	if err := c.WriteLabState(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := NewContainerLab(WithLabState(c.Dir.Lab))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Config.Name != c.Config.Name {
+		t.Errorf("expected lab name %q, got %q", c.Config.Name, restored.Config.Name)
+	}
+
+	if restored.TopoFile.path != c.TopoFile.path {
+		t.Errorf("expected topo file %q, got %q", c.TopoFile.path, restored.TopoFile.path)
+	}
+
+	if len(restored.Nodes) != len(c.Nodes) {
+		t.Fatalf("expected %d nodes, got %d", len(c.Nodes), len(restored.Nodes))
+	}
+
+	// endpoints are compared as a part of the links
+	ignoreEndpoints := cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".Endpoints"
+	}, cmp.Ignore())
+
+	for name, n := range c.Nodes {
+		rn, ok := restored.Nodes[name]
+		if !ok {
+			t.Fatalf("node %q is missing in the restored lab", name)
+		}
+
+		if d := cmp.Diff(n.Config(), rn.Config(), ignoreEndpoints, cmpopts.EquateEmpty()); d != "" {
+			t.Errorf("node %q config mismatch (-want +got):\n%s", name, d)
+		}
+
+		if len(rn.Config().Endpoints) != len(n.Config().Endpoints) {
+			t.Errorf("node %q: expected %d endpoints, got %d", name,
+				len(n.Config().Endpoints), len(rn.Config().Endpoints))
+		}
+	}
+
+	if len(restored.Links) != len(c.Links) {
+		t.Fatalf("expected %d links, got %d", len(c.Links), len(restored.Links))
+	}
+
+	for i, l := range c.Links {
+		rl := restored.Links[i]
+		for _, e := range [][2]*types.Endpoint{{l.A, rl.A}, {l.B, rl.B}} {
+			if e[0].Node.ShortName != e[1].Node.ShortName || e[0].EndpointName != e[1].EndpointName ||
+				e[0].MAC != e[1].MAC || e[0].Node.Kind != e[1].Node.Kind {
+				t.Errorf("link %d endpoint mismatch: expected %s:%s (%s), got %s:%s (%s)", i,
+					e[0].Node.ShortName, e[0].EndpointName, e[0].MAC,
+					e[1].Node.ShortName, e[1].EndpointName, e[1].MAC)
+			}
+		}
+
+		if !cmp.Equal(l.Labels, rl.Labels) || l.MTU != rl.MTU {
+			t.Errorf("link %d attributes mismatch", i)
+		}
+	}
+
+	// restored link endpoints must point to the restored nodes
+	if restored.Links[0].A.Node != restored.Nodes["lin1"].Config() {
+		t.Error("restored link endpoint doesn't reference the restored node config")
+	}
+}
+
+func TestReadLabStateVersion(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, LabStateFName), []byte(`{"version": 100, "name": "test"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadLabState(dir); err == nil {
+		t.Error("expected an error for unsupported lab state version")
+	}
+
+	if _, err := ReadLabState(t.TempDir()); err == nil {
+		t.Error("expected an error for missing lab state file")
+	}
+}
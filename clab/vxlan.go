@@ -5,10 +5,16 @@
 package clab
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
 	"github.com/vishvananda/netlink"
 )
 
@@ -76,3 +82,169 @@ func AddVxLanInterface(vxlan VxLAN) (err error) {
 	}
 	return nil
 }
+
+// allocateVNIs allocates the VNIs of the vxlan links, which don't have one set in the topology.
+// The VNI of a link is derived from the hash of its node endpoint, so that it doesn't change when
+// other links are added, removed or reordered, and the same VNIs are allocated on both sides
+// of the tunnels whose node endpoints have the same names.
+// In case of a collision with the VNI of another link the next free VNI is allocated.
+func (c *CLab) allocateVNIs() error {
+	idx := make([]int, 0, len(c.Links))
+	for i, l := range c.Links {
+		if l.Type == types.LinkTypeVxlan {
+			idx = append(idx, i)
+		}
+	}
+	// the links are sorted by their keys, so that the collisions are resolved regardless of the links order
+	sort.Slice(idx, func(i, j int) bool {
+		return vxlanLinkKey(c.Links[idx[i]]) < vxlanLinkKey(c.Links[idx[j]])
+	})
+
+	// the vxlan interfaces in the host netns are distinguished by the vni and the udp port
+	type tunnelKey struct{ vni, port int }
+	used := map[tunnelKey]*types.Link{}
+	for _, i := range idx {
+		l := c.Links[i]
+		if l.Vxlan.VNI == 0 {
+			continue
+		}
+		k := tunnelKey{l.Vxlan.VNI, l.Vxlan.UDPPort}
+		if other, ok := used[k]; ok {
+			return fmt.Errorf("vni %d and udp port %d are used by both %s and %s", k.vni, k.port, other, l)
+		}
+		used[k] = l
+	}
+
+	for _, i := range idx {
+		l := c.Links[i]
+		if l.Vxlan.VNI != 0 {
+			continue
+		}
+
+		vni := deriveVNI(vxlanLinkKey(l))
+		for n := 0; ; n++ {
+			if n == types.MaxVxlanVNI {
+				return fmt.Errorf("no free vni left for %s", l)
+			}
+			if _, ok := used[tunnelKey{vni, l.Vxlan.UDPPort}]; !ok {
+				break
+			}
+			vni = vni%types.MaxVxlanVNI + 1
+		}
+
+		l.Vxlan.VNI = vni
+		used[tunnelKey{vni, l.Vxlan.UDPPort}] = l
+		log.Debugf("Allocated vni %d to %s", vni, l)
+
+		// the endpoint keeps the allocated vni in the lab state
+		l.B.EndpointName = l.Vxlan.String()
+	}
+
+	return nil
+}
+
+// vxlanLinkKey returns the key of the vxlan link l, which is its node endpoint.
+func vxlanLinkKey(l *types.Link) string {
+	return l.A.Node.ShortName + ":" + l.A.EndpointName
+}
+
+// vxlanIfNames returns the names of the vxlan interface and the host end of the veth pair of the vxlan link l.
+// The names are derived from the node endpoint of the link, which makes them unique across the labs
+// and known to the destroy of the lab.
+func vxlanIfNames(l *types.Link) (vxlanName, vethName string) {
+	h := sha256.Sum256([]byte(l.A.Node.LongName + ":" + l.A.EndpointName))
+	suffix := hex.EncodeToString(h[:])[:8]
+	return "vx-" + suffix, "ve-" + suffix
+}
+
+// createVxlanStitch creates the vxlan link l, which is a veth pair between the node and the host netns,
+// which host end is stitched to a vxlan interface with tc redirection.
+func createVxlanStitch(l *types.Link) error {
+	log.Infof("Creating vxlan link: %s:%s <--> vxlan:%s", l.A.Node.ShortName, l.A.EndpointName, l.Vxlan)
+
+	vxlanName, vethName := vxlanIfNames(l)
+	// interfaces left over from a former deployment of the link are replaced
+	deleteVxlanStitch(l)
+
+	parentIf := l.Vxlan.Dev
+	if parentIf == "" {
+		routes, err := netlink.RouteGet(net.IP(l.Vxlan.Remote.AsSlice()))
+		if err != nil || len(routes) == 0 {
+			return fmt.Errorf("failed to find a route to vxlan remote address %s: %v", l.Vxlan.Remote, err)
+		}
+		link, err := netlink.LinkByIndex(routes[0].LinkIndex)
+		if err != nil {
+			return fmt.Errorf("failed to lookup the interface of the route to %s: %v", l.Vxlan.Remote, err)
+		}
+		parentIf = link.Attrs().Name
+	}
+
+	err := AddVxLanInterface(VxLAN{
+		Name:     vxlanName,
+		ParentIf: parentIf,
+		ID:       l.Vxlan.VNI,
+		Remote:   net.IP(l.Vxlan.Remote.AsSlice()),
+		UDPPort:  l.Vxlan.UDPPort,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the veth pair has the mtu of the vxlan interface, which the kernel derives from the parent interface
+	vxlanIf, err := netlink.LinkByName(vxlanName)
+	if err != nil {
+		return fmt.Errorf("failed to lookup %q: %v", vxlanName, err)
+	}
+
+	hostMAC, _ := net.ParseMAC(utils.GenMac(ClabOUI))
+	nodeMAC, err := net.ParseMAC(l.A.MAC)
+	if err != nil {
+		return err
+	}
+
+	rndName := fmt.Sprintf("clab-%s", genIfName())
+	_, nodeVeth, err := createVethIface(vethName, rndName, vxlanIf.Attrs().MTU, hostMAC, nodeMAC)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.EthtoolTXOff(vethName); err != nil {
+		return err
+	}
+	if err := utils.EthtoolTXOff(rndName); err != nil {
+		return err
+	}
+
+	ep := vEthEndpoint{
+		Link:     nodeVeth,
+		LinkName: l.A.EndpointName,
+		NSName:   l.A.Node.LongName,
+		NSPath:   l.A.Node.NSPath,
+	}
+	if err := ep.toNS(); err != nil {
+		return err
+	}
+
+	return BindIfacesWithTC(vxlanName, vethName)
+}
+
+// deleteVxlanStitch removes the vxlan interface and the host end of the veth pair of the vxlan link l,
+// which also removes the node end of the veth pair.
+func deleteVxlanStitch(l *types.Link) {
+	vxlanName, vethName := vxlanIfNames(l)
+	for _, name := range []string{vethName, vxlanName} {
+		link, err := netlink.LinkByName(name)
+		if err != nil {
+			var notFound netlink.LinkNotFoundError
+			if !errors.As(err, &notFound) {
+				log.Debugf("Failed to lookup %q: %v", name, err)
+			}
+			continue
+		}
+
+		log.Debugf("Removing interface %s of vxlan link %s", name, l)
+		if err := netlink.LinkDel(link); err != nil {
+			log.Debugf("Failed to remove %q: %v", name, err)
+		}
+	}
+}
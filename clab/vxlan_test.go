@@ -0,0 +1,70 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestAllocateVNIs(t *testing.T) {
+	// the vni of n1:eth1 is set explicitly on another link to collide with the derived one
+	collidingVNI := deriveVNI("n1:eth1")
+	links := []string{
+		`["n1:eth1", "vxlan:remote=10.0.0.2"]`,
+		fmt.Sprintf(`["vxlan:remote=10.0.0.2,vni=%d", "n1:eth2"]`, collidingVNI),
+		`["n1:eth3", "vxlan:remote=10.0.0.3,udp-port=14789"]`,
+		`["n1:eth4", "vxlan:remote=10.0.0.3"]`,
+	}
+
+	want := map[string]string{
+		// the colliding vni is taken by the link with the vni set, the next free one is allocated
+		"n1:eth1": fmt.Sprintf("remote=10.0.0.2,vni=%d,udp-port=4789", collidingVNI%types.MaxVxlanVNI+1),
+		"n1:eth2": fmt.Sprintf("remote=10.0.0.2,vni=%d,udp-port=4789", collidingVNI),
+		"n1:eth3": fmt.Sprintf("remote=10.0.0.3,vni=%d,udp-port=14789", deriveVNI("n1:eth3")),
+		"n1:eth4": fmt.Sprintf("remote=10.0.0.3,vni=%d,udp-port=4789", deriveVNI("n1:eth4")),
+	}
+
+	tests := map[string][]int{
+		"links_in_order": {0, 1, 2, 3},
+		// the vnis don't depend on the order of the links
+		"links_reordered": {3, 2, 1, 0},
+		// the vnis of the links don't change when other links are removed
+		"links_removed": {2, 0},
+	}
+
+	for name, order := range tests {
+		t.Run(name, func(t *testing.T) {
+			topo := "name: vxlan\ntopology:\n  nodes:\n    n1:\n      kind: linux\n  links:\n"
+			for _, i := range order {
+				topo += "    - endpoints: " + links[i] + "\n"
+			}
+
+			fPath := filepath.Join(t.TempDir(), "topo.yml")
+			if err := os.WriteFile(fPath, []byte(topo), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewContainerLab(WithTopoFile(fPath, ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(c.Links) != len(order) {
+				t.Fatalf("expected %d links, got %d", len(order), len(c.Links))
+			}
+			for _, l := range c.Links {
+				key := vxlanLinkKey(l)
+				if w := want[key]; l.B.EndpointName != w {
+					t.Errorf("link %s: expected vxlan:%s, got %s", key, w, l)
+				}
+			}
+		})
+	}
+}
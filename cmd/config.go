@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -9,7 +14,9 @@ import (
 	"github.com/srl-labs/containerlab/clab"
 	"github.com/srl-labs/containerlab/clab/config"
 	"github.com/srl-labs/containerlab/clab/config/transport"
+	"github.com/srl-labs/containerlab/clab/exec"
 	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -17,37 +24,57 @@ import (
 // Node Filter for config.
 var configFilter []string
 
+// Output format of the config compare and send.
+var configFormat string
+
+// Path of the raw configuration file to send.
+var sendFile string
+
 // configCmd represents the config command.
 var configCmd = &cobra.Command{
 	Use:          "config",
 	Short:        "configure a lab",
 	Long:         "configure a lab based on templates and variables from the topology definition file\nreference: https://containerlab.dev/cmd/config/",
 	Aliases:      []string{"conf"},
-	ValidArgs:    []string{"commit", "send", "compare", "template"},
+	ValidArgs:    []string{"commit", "compare", "template"},
 	SilenceUsage: true,
 	RunE:         configRun,
 }
 
 var configSendCmd = &cobra.Command{
-	Use:          "send",
-	Short:        "send raw configuration to a lab",
+	Use:   "send",
+	Short: "send raw configuration to a lab",
+	Long: "send a raw configuration snippet from a file or stdin to the lab nodes bypassing the templates\n" +
+		"reference: https://containerlab.dev/cmd/config/send/",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			return fmt.Errorf("unexpected arguments: %s", args)
 		}
-		return configRun(cmd, []string{"send"})
+		var err error
+		configFormat, err = exec.ParseExecOutputFormat(configFormat)
+		if err != nil {
+			return err
+		}
+		return configSend()
 	},
 }
 
 var configCompareCmd = &cobra.Command{
-	Use:          "compare",
-	Short:        "compare configuration to a running lab",
+	Use:   "compare",
+	Short: "compare configuration to a running lab",
+	Long: "compare the rendered configuration to the running configuration of the lab nodes\n" +
+		"exits with a non-zero code when the configuration of any node drifted\nreference: https://containerlab.dev/cmd/config/compare/",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 0 {
 			return fmt.Errorf("unexpected arguments: %s", args)
 		}
+		var err error
+		configFormat, err = exec.ParseExecOutputFormat(configFormat)
+		if err != nil {
+			return err
+		}
 		return configRun(cmd, []string{"compare"})
 	},
 }
@@ -61,6 +88,13 @@ func configRun(_ *cobra.Command, args []string) error {
 	c, err := clab.NewContainerLab(
 		clab.WithTimeout(timeout),
 		clab.WithTopoFile(topo, varsFile),
+		clab.WithRuntime(rt,
+			&runtime.RuntimeConfig{
+				Debug:            debug,
+				Timeout:          timeout,
+				GracefulShutdown: graceful,
+			},
+		),
 	)
 	if err != nil {
 		return err
@@ -71,6 +105,8 @@ func configRun(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	config.LabCARoot = c.Dir.LabCARoot
+
 	allConfig := config.PrepareVars(c.Nodes, c.Links)
 
 	err = config.RenderAll(allConfig)
@@ -88,8 +124,8 @@ func configRun(_ *cobra.Command, args []string) error {
 		switch action {
 		case "commit":
 
-		case "compare", "send":
-			return fmt.Errorf("%s not implemented yet", action)
+		case "compare":
+			return configCompare(c, allConfig)
 		default:
 			return fmt.Errorf("unexpected arguments: %s", args)
 		}
@@ -124,6 +160,183 @@ func configRun(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// configCompare compares the rendered configuration of the filtered nodes to their running configuration
+// and returns an error when the configuration of any node drifted or could not be compared.
+func configCompare(c *clab.CLab, allConfig map[string]*config.NodeConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	diffs := make([]*config.NodeDiff, 0, len(configFilter))
+
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	compare := func(n string) {
+		defer wg.Done()
+
+		d, err := config.Compare(ctx, allConfig[n], c.Nodes[n])
+		if err != nil {
+			d = &config.NodeDiff{
+				Node:  n,
+				Error: err.Error(),
+			}
+		}
+
+		m.Lock()
+		diffs = append(diffs, d)
+		m.Unlock()
+	}
+	for _, node := range configFilter {
+		if len(allConfig[node].Data) == 0 {
+			log.Infof("%s: no rendered configuration, skipping", node)
+			continue
+		}
+
+		wg.Add(1)
+		// On debug this will not be executed concurrently
+		if log.IsLevelEnabled(log.DebugLevel) {
+			compare(node)
+		} else {
+			go compare(node)
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Node < diffs[j].Node
+	})
+
+	var drifted, failed []string
+	for _, d := range diffs {
+		switch {
+		case d.Error != "":
+			failed = append(failed, d.Node)
+		case d.Drift:
+			drifted = append(drifted, d.Node)
+		}
+	}
+
+	switch configFormat {
+	case exec.ExecFormatJSON:
+		b, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(b))
+	default:
+		for _, d := range diffs {
+			switch {
+			case d.Error != "":
+				log.Errorf("%s: failed to compare configuration: %s", d.Node, d.Error)
+			case d.Drift:
+				fmt.Fprint(os.Stdout, d.Diff)
+			default:
+				log.Infof("%s: running configuration matches the rendered configuration", d.Node)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to compare configuration of nodes: %s", strings.Join(failed, ", "))
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("configuration drift detected on nodes: %s", strings.Join(drifted, ", "))
+	}
+
+	return nil
+}
+
+// configSend sends the raw configuration read from sendFile to the filtered nodes
+// and returns an error when sending to any node failed.
+func configSend() error {
+	if sendFile == "" {
+		return fmt.Errorf("provide the configuration file with --file flag")
+	}
+
+	var data []byte
+	var err error
+	if sendFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(sendFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %v", err)
+	}
+
+	transport.DebugCount = debugCount
+
+	c, err := clab.NewContainerLab(
+		clab.WithTimeout(timeout),
+		labSourceOpt(),
+		clab.WithRuntime(rt,
+			&runtime.RuntimeConfig{
+				Debug:            debug,
+				Timeout:          timeout,
+				GracefulShutdown: graceful,
+			},
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = validateFilter(c.Nodes)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resultCollection := exec.NewExecCollection()
+	var failed []string
+
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	send := func(n string) {
+		defer wg.Done()
+
+		res, err := config.SendRaw(ctx, c.Nodes[n], string(data))
+		if err != nil {
+			res = &exec.ExecResult{
+				Cmd:        []string{"config", "send", sendFile},
+				ReturnCode: 1,
+				Stderr:     err.Error(),
+			}
+		}
+
+		m.Lock()
+		defer m.Unlock()
+		resultCollection.Add(n, res)
+		if res.GetReturnCode() != 0 {
+			failed = append(failed, n)
+		}
+	}
+	wg.Add(len(configFilter))
+	for _, node := range configFilter {
+		// On debug this will not be executed concurrently
+		if log.IsLevelEnabled(log.DebugLevel) {
+			send(node)
+		} else {
+			go send(node)
+		}
+	}
+	wg.Wait()
+
+	output, err := resultCollection.Dump(configFormat)
+	if err != nil {
+		return err
+	}
+	fmt.Println(output)
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("failed to send configuration to nodes: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
 func validateFilter(nodes map[string]nodes.Node) error {
 	if len(configFilter) == 0 {
 		for n := range nodes {
@@ -155,8 +368,20 @@ func init() {
 	configCmd.Flags().SortFlags = false
 
 	configCmd.AddCommand(configSendCmd)
-	configSendCmd.Flags().AddFlagSet(configCmd.Flags())
+	configSendCmd.Flags().StringSliceVarP(&configFilter, "filter", "f", []string{},
+		"comma separated list of nodes to include")
+	configSendCmd.Flags().StringVarP(&sendFile, "file", "", "",
+		"path to the file with the raw configuration to send, '-' for stdin")
+	configSendCmd.Flags().StringVarP(&configFormat, "format", "", exec.ExecFormatPlain,
+		"output format. One of [plain, json]")
+	configSendCmd.Flags().SortFlags = false
 
 	configCmd.AddCommand(configCompareCmd)
 	configCompareCmd.Flags().AddFlagSet(configCmd.Flags())
+	configCompareCmd.Flags().StringVarP(&configFormat, "format", "", exec.ExecFormatPlain,
+		"output format. One of [plain, json]")
+
+	// dry run is registered after the flags are shared with the compare command, which sends no requests
+	configCmd.Flags().BoolVarP(&config.DryRun, "dry-run", "", false,
+		"print the requests instead of sending them, supported by the gnmi transport")
 }
@@ -6,12 +6,14 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"sync"
 
 	cfssllog "github.com/cloudflare/cfssl/log"
+	"github.com/olekukonko/tablewriter"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/cert"
@@ -19,6 +21,7 @@ import (
 	"github.com/srl-labs/containerlab/clab/exec"
 	"github.com/srl-labs/containerlab/nodes"
 	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
 	"github.com/srl-labs/containerlab/utils"
 )
 
@@ -47,6 +50,18 @@ var skipPostDeploy bool
 // template file for topology data export.
 var exportTemplate string
 
+// reconcile flag.
+var reconcile bool
+
+// dry-run flag.
+var dryRun bool
+
+// host of a distributed lab to deploy the nodes of.
+var labHost string
+
+// failure policy of the deployment.
+var onError string
+
 // deployCmd represents the deploy command.
 var deployCmd = &cobra.Command{
 	Use:          "deploy",
@@ -71,17 +86,41 @@ func init() {
 	deployCmd.Flags().BoolVarP(&skipPostDeploy, "skip-post-deploy", "", false, "skip post deploy action")
 	deployCmd.Flags().StringVarP(&exportTemplate, "export-template", "",
 		defaultExportTemplateFPath, "template file for topology data export")
+	deployCmd.Flags().BoolVarP(&reconcile, "reconcile", "", false,
+		"apply the changes of the topology to an already deployed lab")
+	deployCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false,
+		"print the reconcile plan without applying it")
+	deployCmd.Flags().StringVarP(&labHost, "host", "", "",
+		"deploy only the nodes placed on this host of a distributed lab")
+	deployCmd.Flags().StringVarP(&onError, "on-error", "", clab.OnErrorContinue,
+		"failure policy when a node fails to deploy, one of continue, abort or rollback")
 }
 
 // deployFn function runs deploy sub command.
 func deployFn(_ *cobra.Command, _ []string) error {
 	var err error
 
+	if reconfigure && reconcile {
+		return fmt.Errorf("--reconfigure and --reconcile flags are mutually exclusive")
+	}
+	if dryRun && !reconcile {
+		return fmt.Errorf("--dry-run flag can only be used with --reconcile")
+	}
+	if err := clab.ValidateOnErrorPolicy(onError); err != nil {
+		return err
+	}
+	if onError == clab.OnErrorRollback && reconcile {
+		return fmt.Errorf("--on-error rollback can't be used with --reconcile, as it would destroy the deployed lab")
+	}
+
 	log.Infof("Containerlab v%s started", version)
 
 	opts := []clab.ClabOption{
 		clab.WithTimeout(timeout),
+		clab.WithHost(labHost),
+		clab.WithRootless(rootlessMode),
 		clab.WithTopoFile(topo, varsFile),
+		clab.WithSkipPostDeploy(skipPostDeploy),
 		clab.WithRuntime(rt,
 			&runtime.RuntimeConfig{
 				Debug:            debug,
@@ -115,7 +154,23 @@ func deployFn(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	if err = c.CheckTopologyDefinition(ctx); err != nil {
+	var plan *clab.ReconcilePlan
+	if reconcile {
+		plan, err = c.PlanReconcile(ctx)
+		if err != nil {
+			return err
+		}
+		log.Infof("Reconcile plan for lab %q:\n%s", c.Config.Name, plan)
+
+		if dryRun {
+			return nil
+		}
+
+		err = c.CheckReconcileConditions(ctx)
+	} else {
+		err = c.CheckTopologyDefinition(ctx)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -126,6 +181,10 @@ func deployFn(_ *cobra.Command, _ []string) error {
 	log.Info("Creating lab directory: ", c.Dir.Lab)
 	utils.CreateDirectory(c.Dir.Lab, 0755)
 
+	if err := c.RunLabHooks(ctx, types.HookPreDeploy); err != nil {
+		return err
+	}
+
 	// create an empty ansible inventory file that will get populated later
 	// we create it here first, so that bind mounts of ansible-inventory.yml file could work
 	ansibleInvFPath := filepath.Join(c.Dir.Lab, "ansible-inventory.yml")
@@ -145,7 +204,7 @@ func deployFn(_ *cobra.Command, _ []string) error {
 	if debug {
 		cfssllog.Level = cfssllog.LevelDebug
 	}
-	if err := cert.CreateRootCA(c.Config.Name, c.Dir.LabCARoot, c.Nodes); err != nil {
+	if err := cert.CreateRootCA(c.Config.Name, c.Dir.LabCARoot, c.Config.CertificateAuthority, c.Nodes); err != nil {
 		return err
 	}
 
@@ -191,15 +250,50 @@ func deployFn(_ *cobra.Command, _ []string) error {
 		n.Config().ExtraHosts = extraHosts
 	}
 
-	nodesWg, err := c.CreateNodes(ctx, nodeWorkers)
-	if err != nil {
-		return err
+	// deployedNodes are the nodes created by this run,
+	// post-deploy actions and execs are only run for them
+	deployedNodes := c.Nodes
+
+	// res collects the errors of the nodes failed to deploy
+	res := clab.NewDeployResult(onError)
+
+	if reconcile {
+		if err := c.Reconcile(ctx, plan, nodeWorkers, linkWorkers, res); err != nil {
+			return err
+		}
+		deployedNodes = c.ReconciledNodes(plan)
+	} else {
+		nodesWg, err := c.CreateNodes(ctx, nodeWorkers, res)
+		if err != nil {
+			return err
+		}
+		c.CreateLinks(ctx, linkWorkers, res)
+		if nodesWg != nil {
+			nodesWg.Wait()
+		}
 	}
-	c.CreateLinks(ctx, linkWorkers)
-	if nodesWg != nil {
-		nodesWg.Wait()
+
+	if res.Aborted() {
+		switch res.Policy() {
+		case clab.OnErrorRollback:
+			log.Warnf("Rolling back deployment of lab %s", c.Config.Name)
+			if err := destroyLab(ctx, c); err != nil {
+				log.Errorf("failed to roll back deployment of lab %s: %v", c.Config.Name, err)
+			}
+		default:
+			// the nodes and links created before the deployment was aborted are kept,
+			// the lab state allows to destroy them by the lab name
+			if err := c.WriteLabState(); err != nil {
+				log.Errorf("failed to write lab state file: %v", err)
+			}
+		}
+		printDeployErrors(res)
+		return res.Err()
 	}
 
+	// the nodes failed to deploy are skipped by the post-deploy actions and execs
+	deployedNodes = succeededNodes(deployedNodes, res)
+
 	log.Debug("containers created, retrieving state and IP addresses...")
 	// updating nodes with runtime information such as IP addresses assigned by the runtime dynamically
 	for _, n := range c.Nodes {
@@ -209,6 +303,10 @@ func deployFn(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if err := c.RunLabHooks(ctx, types.HookPostCreate); err != nil {
+		return err
+	}
+
 	if err := c.GenerateInventories(); err != nil {
 		return err
 	}
@@ -217,16 +315,26 @@ func deployFn(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	// lab state allows other commands to load the lab without the topology file
+	if err := c.WriteLabState(); err != nil {
+		return fmt.Errorf("failed to write lab state file: %v", err)
+	}
+
 	if !skipPostDeploy {
 		wg := &sync.WaitGroup{}
-		wg.Add(len(c.Nodes))
 
-		for _, node := range c.Nodes {
+		for _, node := range deployedNodes {
+			// the nodes other nodes waited for already ran their post-deploy actions
+			if c.PostDeployed(node.Config().ShortName) {
+				continue
+			}
+			wg.Add(1)
 			go func(node nodes.Node, wg *sync.WaitGroup) {
 				defer wg.Done()
 				err := node.PostDeploy(ctx, c.Nodes)
 				if err != nil {
 					log.Errorf("failed to run postdeploy task for node %s: %v", node.Config().ShortName, err)
+					res.AddNodeError(node, clab.StagePostDeploy, err)
 				}
 			}(node, wg)
 		}
@@ -245,15 +353,17 @@ func deployFn(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	log.Info("Adding containerlab host entries to /etc/hosts file")
-	err = clab.AppendHostsFileEntries(containers, c.Config.Name)
-	if err != nil {
-		log.Errorf("failed to create hosts file: %v", err)
+	if updateHostsFile() {
+		log.Info("Adding containerlab host entries to /etc/hosts file")
+		err = clab.AppendHostsFileEntries(containers, c.Config.Name)
+		if err != nil {
+			log.Errorf("failed to create hosts file: %v", err)
+		}
 	}
 
 	// execute commands specified for nodes with `exec` node parameter
 	execCollection := exec.NewExecCollection()
-	for _, n := range c.Nodes {
+	for _, n := range deployedNodes {
 		execResult, err := n.RunExecs(ctx, n.Config().Exec)
 		if err != nil {
 			log.Warnf("Failed to exec commands for node %q", n.Config().ShortName)
@@ -264,11 +374,58 @@ func deployFn(_ *cobra.Command, _ []string) error {
 	// write to log
 	execCollection.Log()
 
+	if err := c.RunNodesHooks(ctx, deployedNodes, types.HookPostDeploy); err != nil {
+		return err
+	}
+	if err := c.RunLabHooks(ctx, types.HookPostDeploy); err != nil {
+		return err
+	}
+
 	// log new version availability info if ready
 	newVerNotification(vCh)
 
 	// print table summary
-	return printContainerInspect(containers, format)
+	if err := printContainerInspect(containers, vxlanTunnels(c), format); err != nil {
+		return err
+	}
+
+	if err := res.Err(); err != nil {
+		printDeployErrors(res)
+		return err
+	}
+
+	return nil
+}
+
+// succeededNodes returns the nodes of ns which didn't fail to deploy.
+func succeededNodes(ns map[string]nodes.Node, res *clab.DeployResult) map[string]nodes.Node {
+	succeeded := make(map[string]nodes.Node, len(ns))
+	for name, n := range ns {
+		if !res.Failed(name) {
+			succeeded[name] = n
+		}
+	}
+	return succeeded
+}
+
+// printDeployErrors prints the table of the nodes failed to deploy and the links failed to be created to stderr,
+// so that it doesn't mix with the machine readable output of the deploy command.
+func printDeployErrors(res *clab.DeployResult) {
+	errs, linkErrs := res.Errors(), res.LinkErrors()
+	tabData := make([][]string, 0, len(errs)+len(linkErrs))
+	for _, e := range errs {
+		tabData = append(tabData, []string{e.Node, e.Kind, e.Stage, e.Err.Error()})
+	}
+	for _, e := range linkErrs {
+		tabData = append(tabData, []string{e.Link, e.Type, clab.StageLink, e.Err.Error()})
+	}
+
+	table := tablewriter.NewWriter(os.Stderr)
+	table.SetHeader([]string{"Name", "Kind", "Stage", "Error"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
 }
 
 func setFlags(conf *clab.Config) {
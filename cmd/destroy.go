@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -15,6 +16,7 @@ import (
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/runtime/ignite"
 	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
 )
 
 var (
@@ -42,6 +44,8 @@ func init() {
 	destroyCmd.Flags().UintVarP(&maxWorkers, "max-workers", "", 0,
 		"limit the maximum number of workers deleting nodes")
 	destroyCmd.Flags().BoolVarP(&keepMgmtNet, "keep-mgmt-net", "", false, "do not remove the management network")
+	destroyCmd.Flags().StringVarP(&labHost, "host", "", "",
+		"destroy only the nodes placed on this host of a distributed lab")
 }
 
 func destroyFn(_ *cobra.Command, _ []string) error {
@@ -50,11 +54,12 @@ func destroyFn(_ *cobra.Command, _ []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	topos := map[string]struct{}{}
+	// labSources holds the options that load the labs to destroy
+	var labSources []clab.ClabOption
 
 	switch {
 	case !all:
-		topos[topo] = struct{}{}
+		labSources = append(labSources, labSourceOpt())
 	case all:
 		// only WithRuntime option is needed to list all containers of a lab
 		inspectAllOpts := []clab.ClabOption{
@@ -85,17 +90,34 @@ func destroyFn(_ *cobra.Command, _ []string) error {
 		if len(containers) == 0 {
 			return fmt.Errorf("no containerlab labs were found")
 		}
-		// get unique topo files from all labs
+
+		// get unique lab directories with a lab state file and, for the labs without it, unique topo files
+		labDirs := map[string]struct{}{}
+		topos := map[string]struct{}{}
 		for i := range containers {
-			topos[containers[i].Labels["clab-topo-file"]] = struct{}{}
+			labDir := filepath.Dir(containers[i].Labels[clab.NodeLabDirLabel])
+			if utils.FileExists(filepath.Join(labDir, clab.LabStateFName)) {
+				labDirs[labDir] = struct{}{}
+				continue
+			}
+			topos[containers[i].Labels[clab.TopoFileLabel]] = struct{}{}
+		}
+
+		log.Debugf("We got the following lab dirs %+v and topos %+v for destroy", labDirs, topos)
+		for labDir := range labDirs {
+			labSources = append(labSources, clab.WithLabState(labDir))
+		}
+		for topo := range topos {
+			labSources = append(labSources, clab.WithTopoFile(topo, varsFile))
 		}
 	}
 
-	log.Debugf("We got the following topos struct for destroy: %+v", topos)
-	for topo := range topos {
+	for _, labSource := range labSources {
 		opts := []clab.ClabOption{
 			clab.WithTimeout(timeout),
-			clab.WithTopoFile(topo, varsFile),
+			clab.WithHost(labHost),
+			clab.WithRootless(rootlessMode),
+			labSource,
 			clab.WithRuntime(rt,
 				&runtime.RuntimeConfig{
 					Debug:            debug,
@@ -109,7 +131,6 @@ func destroyFn(_ *cobra.Command, _ []string) error {
 			opts = append(opts, clab.WithKeepMgmtNet())
 		}
 
-		log.Debugf("going through extracted topos for destroy, got a topo file %v and generated opts list %+v", topo, opts)
 		nc, err := clab.NewContainerLab(opts...)
 		if err != nil {
 			return err
@@ -151,6 +172,22 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 		return nil
 	}
 
+	if c.HasHooks(types.HookPreDestroy) || c.HasHooks(types.HookPostDestroy) {
+		// the management addresses assigned by the runtime are exported to the hooks
+		for _, n := range c.Nodes {
+			if err := n.UpdateConfigWithRuntimeInfo(ctx); err != nil {
+				log.Debugf("failed to update runtime information of node %q: %v", n.Config().ShortName, err)
+			}
+		}
+	}
+
+	if err := c.RunLabHooks(ctx, types.HookPreDestroy); err != nil {
+		return err
+	}
+	if err := c.RunNodesHooks(ctx, c.Nodes, types.HookPreDestroy); err != nil {
+		return err
+	}
+
 	if maxWorkers == 0 {
 		maxWorkers = uint(len(c.Nodes))
 	}
@@ -173,10 +210,12 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 	log.Infof("Destroying lab: %s", c.Config.Name)
 	c.DeleteNodes(ctx, maxWorkers, serialNodes)
 
-	log.Info("Removing containerlab host entries from /etc/hosts file")
-	err = clab.DeleteEntriesFromHostsFile(c.Config.Name)
-	if err != nil {
-		return fmt.Errorf("error while trying to clean up the hosts file: %w", err)
+	if updateHostsFile() {
+		log.Info("Removing containerlab host entries from /etc/hosts file")
+		err = clab.DeleteEntriesFromHostsFile(c.Config.Name)
+		if err != nil {
+			return fmt.Errorf("error while trying to clean up the hosts file: %w", err)
+		}
 	}
 
 	// delete lab management network
@@ -203,5 +242,9 @@ func destroyLab(ctx context.Context, c *clab.CLab) (err error) {
 	if err != nil {
 		return fmt.Errorf("error during veth cleanup procedure, %w", err)
 	}
-	return err
+
+	if err := c.RunNodesHooks(ctx, c.Nodes, types.HookPostDestroy); err != nil {
+		return err
+	}
+	return c.RunLabHooks(ctx, types.HookPostDestroy)
 }
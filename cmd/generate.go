@@ -209,9 +209,9 @@ func generateTopologyConfig(name, network, ipv4range, ipv6range string,
 					}
 				}
 				config.Topology.Links = append(config.Topology.Links, &types.LinkConfig{
-					Endpoints: []string{
-						node1 + ":" + fmt.Sprintf(interfaceFormat[nodes[i].kind], k+1+interfaceOffset),
-						node2 + ":" + fmt.Sprintf(interfaceFormat[nodes[i+1].kind], j+1),
+					Endpoints: []*types.EndpointConfig{
+						types.NewEndpointConfig(node1, fmt.Sprintf(interfaceFormat[nodes[i].kind], k+1+interfaceOffset)),
+						types.NewEndpointConfig(node2, fmt.Sprintf(interfaceFormat[nodes[i+1].kind], j+1)),
 					},
 				})
 			}
@@ -44,7 +44,7 @@ func graphFn(_ *cobra.Command, _ []string) error {
 
 	opts := []clab.ClabOption{
 		clab.WithTimeout(timeout),
-		clab.WithTopoFile(topo, varsFile),
+		labSourceOpt(),
 		clab.WithRuntime(rt,
 			&runtime.RuntimeConfig{
 				Debug:            debug,
@@ -93,11 +93,19 @@ func graphFn(_ *cobra.Command, _ []string) error {
 		return gtopo.Nodes[i].Name < gtopo.Nodes[j].Name
 	})
 	for _, l := range c.Links {
+		// dummy links are not connected to anything
+		if l.B == nil {
+			continue
+		}
 		gtopo.Links = append(gtopo.Links, clab.Link{
 			Source:         l.A.Node.ShortName,
 			SourceEndpoint: l.A.EndpointName,
+			SourceIPv4:     l.A.IPv4,
+			SourceIPv6:     l.A.IPv6,
 			Target:         l.B.Node.ShortName,
 			TargetEndpoint: l.B.EndpointName,
+			TargetIPv4:     l.B.IPv4,
+			TargetIPv6:     l.B.IPv6,
 		})
 	}
 
@@ -23,6 +23,7 @@ import (
 	mysocketionode "github.com/srl-labs/containerlab/nodes/mysocketio"
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
 )
 
 var (
@@ -65,8 +66,15 @@ func inspectFn(_ *cobra.Command, _ []string) error {
 		),
 	}
 
-	if topo != "" {
+	// a lab referenced by its name is loaded from the lab state file if it exists
+	useState := topo == "" && name != "" &&
+		utils.FileExists(filepath.Join(clab.LabDir(name), clab.LabStateFName))
+
+	switch {
+	case topo != "":
 		opts = append(opts, clab.WithTopoFile(topo, varsFile))
+	case useState:
+		opts = append(opts, clab.WithLabState(clab.LabDir(name)))
 	}
 
 	c, err := clab.NewContainerLab(opts...)
@@ -83,8 +91,8 @@ func inspectFn(_ *cobra.Command, _ []string) error {
 
 	var containers []types.GenericContainer
 
-	// if the topo file is available, use it
-	if topo != "" {
+	// if the topo file or lab state is available, use it
+	if topo != "" || useState {
 		containers, err = c.ListNodesContainers(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to list containers: %s", err)
@@ -121,10 +129,36 @@ func inspectFn(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
-	err = printContainerInspect(containers, format)
+	err = printContainerInspect(containers, vxlanTunnels(c), format)
 	return err
 }
 
+// vxlanTunnels returns the tunnels of the vxlan links of the lab sorted by the node and interface names.
+func vxlanTunnels(c *clab.CLab) []*types.VxlanTunnel {
+	var tunnels []*types.VxlanTunnel
+	for _, l := range c.Links {
+		if l.Vxlan == nil {
+			continue
+		}
+		tunnels = append(tunnels, &types.VxlanTunnel{
+			Node:      l.A.Node.ShortName,
+			Interface: l.A.EndpointName,
+			Remote:    l.Vxlan.Remote.String(),
+			VNI:       l.Vxlan.VNI,
+			UDPPort:   l.Vxlan.UDPPort,
+		})
+	}
+
+	sort.Slice(tunnels, func(i, j int) bool {
+		if tunnels[i].Node == tunnels[j].Node {
+			return tunnels[i].Interface < tunnels[j].Interface
+		}
+		return tunnels[i].Node < tunnels[j].Node
+	})
+
+	return tunnels
+}
+
 func toTableData(det []types.ContainerDetails) [][]string {
 	tabData := make([][]string, 0, len(det))
 	for i := range det {
@@ -145,7 +179,7 @@ func toTableData(det []types.ContainerDetails) [][]string {
 	return tabData
 }
 
-func printContainerInspect(containers []types.GenericContainer, format string) error {
+func printContainerInspect(containers []types.GenericContainer, tunnels []*types.VxlanTunnel, format string) error {
 	contDetails := make([]types.ContainerDetails, 0, len(containers))
 	// do not print published ports unless mysocketio kind is found
 	printMysocket := false
@@ -189,7 +223,7 @@ func printContainerInspect(containers []types.GenericContainer, format string) e
 		return contDetails[i].LabName < contDetails[j].LabName
 	})
 
-	resultData := &types.LabData{Containers: contDetails, MySocketIo: []*types.MySocketIoEntry{}}
+	resultData := &types.LabData{Containers: contDetails, MySocketIo: []*types.MySocketIoEntry{}, Vxlan: tunnels}
 	var socketdata []*types.MySocketIoEntry
 	var tokenFiles []*TokenFileResults
 	var err error
@@ -246,6 +280,10 @@ func printContainerInspect(containers []types.GenericContainer, format string) e
 		table.AppendBulk(tabData)
 		table.Render()
 
+		if len(tunnels) != 0 {
+			printVxlanTunnels(tunnels)
+		}
+
 		// do not print mysocket data if printMysocket is false or we don't have nodes populated
 		// nodes are not populated when `inspect --all` is used, since we don't read topology files
 		if !printMysocket {
@@ -287,6 +325,24 @@ func printContainerInspect(containers []types.GenericContainer, format string) e
 	return nil
 }
 
+// printVxlanTunnels prints the table of the vxlan tunnels.
+func printVxlanTunnels(tunnels []*types.VxlanTunnel) {
+	tabData := make([][]string, 0, len(tunnels))
+	for _, t := range tunnels {
+		tabData = append(tabData, []string{
+			t.Node, t.Interface, t.Remote, strconv.Itoa(t.VNI), strconv.Itoa(t.UDPPort),
+		})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Node", "Interface", "Remote", "VNI", "UDP Port"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	fmt.Println("VxLAN tunnels:")
+	table.Render()
+}
+
 // getMySocketioData uses the mysocketio.http client to retrieve the socket data.
 func getMySocketIoData(tokenfile string) ([]*types.MySocketIoEntry, error) {
 	result := []*types.MySocketIoEntry{}
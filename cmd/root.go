@@ -12,6 +12,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/rootless"
+	"github.com/srl-labs/containerlab/runtime/docker"
 )
 
 var (
@@ -33,6 +36,12 @@ var (
 // lab name.
 var name string
 
+// rootless mode flags.
+var (
+	rootlessMode  bool
+	rootlessHosts bool
+)
+
 // rootCmd represents the base command when called without any subcommands.
 var rootCmd = &cobra.Command{
 	Use:               "containerlab",
@@ -61,16 +70,49 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&rt, "runtime", "r", "", "container runtime")
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "", "info",
 		"logging level; one of [trace, debug, info, warning, error, fatal]")
+	rootCmd.PersistentFlags().BoolVarP(&rootlessMode, "rootless", "", false,
+		"run without root privileges with a rootless docker or podman runtime")
+	rootCmd.PersistentFlags().BoolVarP(&rootlessHosts, "rootless-hosts", "", false,
+		"update /etc/hosts file with the lab nodes in the rootless mode, requires write access to the file")
 }
 
 func sudoCheck(_ *cobra.Command, _ []string) error {
+	// in the rootless mode containerlab is re-executed in the namespaces of the runtime, where it is privileged
+	if rootlessMode {
+		err := rootless.Enter(rootlessRuntime())
+		// the command is complete once the re-executed containerlab exits
+		var code rootless.ExitCode
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
+		return err
+	}
+
 	id := os.Geteuid()
 	if id != 0 {
-		return errors.New("containerlab requires sudo privileges to run")
+		return errors.New("containerlab requires sudo privileges to run, or the --rootless flag with a rootless docker or podman runtime")
 	}
 	return nil
 }
 
+// rootlessRuntime returns the name of the runtime used in the rootless mode,
+// which is set with the --runtime flag or CLAB_RUNTIME env var, docker by default.
+func rootlessRuntime() string {
+	if rt != "" {
+		return rt
+	}
+	if r := os.Getenv("CLAB_RUNTIME"); r != "" {
+		return r
+	}
+	return docker.RuntimeName
+}
+
+// updateHostsFile returns true when /etc/hosts file is updated with the lab nodes,
+// which is opt-in in the rootless mode.
+func updateHostsFile() bool {
+	return !rootlessMode || rootlessHosts
+}
+
 func preRunFn(cmd *cobra.Command, _ []string) error {
 	// setting log level
 	switch {
@@ -95,8 +137,10 @@ func preRunFn(cmd *cobra.Command, _ []string) error {
 // errors if more than one file is found by the glob path.
 func getTopoFilePath(cmd *cobra.Command) error {
 	// set commands which may use topo file find functionality, the rest don't need it
+	// the tools link subcommands operate on the links of a lab referenced by its topo file as well
+	isLinkCmd := cmd.HasParent() && cmd.Parent() == linkCmd
 	if !(cmd.Name() == "deploy" || cmd.Name() == "destroy" || cmd.Name() == "inspect" ||
-		cmd.Name() == "save" || cmd.Name() == "graph" || cmd.Name() == "exec") {
+		cmd.Name() == "save" || cmd.Name() == "graph" || cmd.Name() == "exec" || isLinkCmd) {
 		return nil
 	}
 
@@ -127,3 +171,13 @@ func getTopoFilePath(cmd *cobra.Command) error {
 
 	return err
 }
+
+// labSourceOpt returns an option that loads the lab from the topology file,
+// or from the state file of a deployed lab when only the lab name is provided.
+func labSourceOpt() clab.ClabOption {
+	if topo == "" && name != "" {
+		return clab.WithLabState(clab.LabDir(name))
+	}
+
+	return clab.WithTopoFile(topo, varsFile)
+}
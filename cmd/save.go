@@ -29,7 +29,7 @@ Refer to the https://containerlab.dev/cmd/save/ documentation to see the exact c
 		}
 		opts := []clab.ClabOption{
 			clab.WithTimeout(timeout),
-			clab.WithTopoFile(topo, varsFile),
+			labSourceOpt(),
 			clab.WithRuntime(rt,
 				&runtime.RuntimeConfig{
 					Debug:            debug,
@@ -46,24 +46,29 @@ Refer to the https://containerlab.dev/cmd/save/ documentation to see the exact c
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		var wg sync.WaitGroup
-		wg.Add(len(c.Nodes))
-		for _, node := range c.Nodes {
-			go func(node nodes.Node) {
-				defer wg.Done()
-
-				err := node.SaveConfig(ctx)
-				if err != nil {
-					log.Errorf("err: %v", err)
-				}
-			}(node)
-		}
-		wg.Wait()
+		saveNodesConfig(ctx, c)
 
 		return nil
 	},
 }
 
+// saveNodesConfig saves the configuration of the lab nodes concurrently.
+func saveNodesConfig(ctx context.Context, c *clab.CLab) {
+	var wg sync.WaitGroup
+	wg.Add(len(c.Nodes))
+	for _, node := range c.Nodes {
+		go func(node nodes.Node) {
+			defer wg.Done()
+
+			err := node.SaveConfig(ctx)
+			if err != nil {
+				log.Errorf("err: %v", err)
+			}
+		}(node)
+	}
+	wg.Wait()
+}
+
 func init() {
 	rootCmd.AddCommand(saveCmd)
 }
@@ -0,0 +1,191 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+var (
+	snapshotDir  string
+	snapshotFile string
+)
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+
+	snapshotCmd.PersistentFlags().StringVarP(&snapshotDir, "dir", "", ".", "directory the snapshots are stored in")
+	snapshotRestoreCmd.Flags().StringVarP(&snapshotFile, "file", "f", "",
+		"snapshot file to restore the lab from. When not set, the latest snapshot of the lab is used")
+}
+
+// snapshotCmd represents the snapshot command container.
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "lab snapshot operations",
+	Long:  "snapshot command groups operations on lab snapshots\nreference: https://containerlab.dev/cmd/snapshot/",
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "save the lab nodes configuration and create a lab snapshot",
+	PreRunE: sudoCheck,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if name == "" && topo == "" {
+			return errors.New("provide the lab name with --name flag or the topology file path with --topo flag")
+		}
+
+		opts := []clab.ClabOption{
+			clab.WithTimeout(timeout),
+			labSourceOpt(),
+			clab.WithRuntime(rt,
+				&runtime.RuntimeConfig{
+					Debug:            debug,
+					Timeout:          timeout,
+					GracefulShutdown: graceful,
+				},
+			),
+		}
+		c, err := clab.NewContainerLab(opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		saveNodesConfig(ctx, c)
+
+		fPath := filepath.Join(snapshotDir, clab.SnapshotFileName(c.Config.Name, time.Now()))
+		m, err := c.CreateSnapshot(fPath)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot of lab %q: %v", c.Config.Name, err)
+		}
+
+		log.Infof("Snapshot of lab %q with %d nodes saved to %s", m.Name, len(m.Nodes), fPath)
+		return nil
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "deploy a lab from a snapshot",
+	PreRunE: sudoCheck,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if snapshotFile == "" {
+			if name == "" {
+				return errors.New("provide the snapshot file with --file flag or the lab name with --name flag")
+			}
+
+			var err error
+			snapshotFile, err = latestSnapshot(snapshotDir, name)
+			if err != nil {
+				return err
+			}
+		}
+
+		m, err := clab.ReadSnapshotManifest(snapshotFile)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Restoring lab %q from snapshot %s", m.Name, snapshotFile)
+		topoPath, err := clab.RestoreSnapshot(snapshotFile, filepath.Dir(clab.LabDir(m.Name)))
+		if err != nil {
+			return err
+		}
+
+		// the lab is deployed from the restored topology file
+		// which references the saved nodes configs as their startup-config
+		topo = topoPath
+		name = m.Name
+
+		return deployFn(cmd, args)
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list lab snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshots, err := clab.ListSnapshots(snapshotDir, name)
+		if err != nil {
+			return err
+		}
+
+		if len(snapshots) == 0 {
+			log.Info("no snapshots found")
+			return nil
+		}
+
+		files := sortedSnapshotFiles(snapshots)
+
+		tabData := make([][]string, 0, len(files))
+		for i, f := range files {
+			m := snapshots[f]
+			tabData = append(tabData, []string{
+				strconv.Itoa(i + 1),
+				f,
+				m.Name,
+				m.Created.Format(time.RFC3339),
+				strconv.Itoa(len(m.Nodes)),
+			})
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"#", "Snapshot", "Lab Name", "Created", "Nodes"})
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		table.AppendBulk(tabData)
+		table.Render()
+
+		return nil
+	},
+}
+
+// latestSnapshot returns the path of the latest snapshot of the lab labName found in the directory dir.
+func latestSnapshot(dir, labName string) (string, error) {
+	snapshots, err := clab.ListSnapshots(dir, labName)
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) == 0 {
+		return "", fmt.Errorf("no snapshots of lab %q found in %s", labName, dir)
+	}
+
+	files := sortedSnapshotFiles(snapshots)
+
+	return files[len(files)-1], nil
+}
+
+// sortedSnapshotFiles returns the snapshot files sorted by their creation time.
+func sortedSnapshotFiles(snapshots map[string]*clab.SnapshotManifest) []string {
+	files := make([]string, 0, len(snapshots))
+	for f := range snapshots {
+		files = append(files, f)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return snapshots[files[i]].Created.Before(snapshots[files[j]].Created)
+	})
+
+	return files
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/capture"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	captureNode     string
+	captureIntf     string
+	captureFile     string
+	captureFilter   string
+	captureCount    int
+	captureDuration time.Duration
+	captureSnapLen  int
+)
+
+func init() {
+	toolsCmd.AddCommand(captureCmd)
+
+	captureCmd.Flags().StringVarP(&captureNode, "node", "", "", "container name of the node")
+	captureCmd.Flags().StringVarP(&captureIntf, "intf", "i", "", "interface name to capture on")
+	captureCmd.Flags().StringVarP(&captureFile, "write", "w", "-",
+		"file to write the captured packets to in the pcapng format, '-' for stdout")
+	captureCmd.Flags().StringVarP(&captureFilter, "filter", "f", "",
+		"capture filter of primitives combined with 'and' and 'not', e.g. \"tcp and not port 22\"")
+	captureCmd.Flags().IntVarP(&captureCount, "count", "c", 0,
+		"stop after capturing the number of packets, 0 means no limit")
+	captureCmd.Flags().DurationVarP(&captureDuration, "duration", "", 0,
+		"stop after capturing for the duration, e.g. 30s. 0 means no limit")
+	captureCmd.Flags().IntVarP(&captureSnapLen, "snaplen", "s", capture.DefaultSnapLen,
+		"maximum number of bytes captured from each packet")
+	_ = captureCmd.MarkFlagRequired("node")
+	_ = captureCmd.MarkFlagRequired("intf")
+}
+
+// captureCmd represents the capture command.
+var captureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "capture packets on a node interface",
+	Long: "capture packets on a node interface and write them in the pcapng format to a file or stdout\n" +
+		"reference: https://containerlab.dev/cmd/tools/capture/",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var h *capture.Handle
+
+		// the capture socket is opened in the container network namespace
+		// and keeps capturing there once the namespace is left
+		err := inContainerNetns(captureNode, func() error {
+			var filter []unix.SockFilter
+			var err error
+			if captureFilter != "" {
+				filter, err = capture.CompileFilter(captureFilter, captureSnapLen)
+				if err != nil {
+					return err
+				}
+			}
+
+			h, err = capture.Open(captureIntf, captureSnapLen, filter)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		defer h.Close()
+
+		var out io.Writer = os.Stdout
+		if captureFile != "-" {
+			f, err := os.Create(captureFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		w, err := capture.NewPcapngWriter(out, captureIntf, captureSnapLen)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		if captureDuration > 0 {
+			ctx, cancel = context.WithTimeout(ctx, captureDuration)
+			defer cancel()
+		}
+
+		log.Infof("Capturing on %s:%s", captureNode, captureIntf)
+		n, err := h.Capture(ctx, w, captureCount)
+		log.Infof("%d packets captured", n)
+
+		return err
+	},
+}
@@ -7,13 +7,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
+	"time"
 
 	cfssllog "github.com/cloudflare/cfssl/log"
+	"github.com/olekukonko/tablewriter"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/srl-labs/containerlab/cert"
 	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/utils"
 )
 
 var (
@@ -29,6 +36,11 @@ var (
 	certHosts        []string
 	caCertPath       string
 	caKeyPath        string
+	renewNodes       []string
+	renewCA          bool
+	certFiles        []string
+	keyAlgorithm     string
+	keySize          int
 )
 
 func init() {
@@ -36,6 +48,8 @@ func init() {
 	certCmd.AddCommand(CACmd)
 	certCmd.AddCommand(signCertCmd)
 	CACmd.AddCommand(CACreateCmd)
+	certCmd.AddCommand(renewCertCmd)
+	certCmd.AddCommand(inspectCertCmd)
 
 	CACreateCmd.Flags().StringVarP(&commonName, "cn", "", "containerlab.dev", "Common Name")
 	CACreateCmd.Flags().StringVarP(&country, "c", "", "Internet", "Country")
@@ -46,6 +60,9 @@ func init() {
 	CACreateCmd.Flags().StringVarP(&path, "path", "p", "",
 		"path to write certificates to. Default is current working directory")
 	CACreateCmd.Flags().StringVarP(&caNamePrefix, "name", "n", "ca", "certificate/key filename prefix")
+	CACreateCmd.Flags().StringVarP(&keyAlgorithm, "key-algorithm", "", "rsa", "key algorithm: rsa, ecdsa or ed25519")
+	CACreateCmd.Flags().IntVarP(&keySize, "key-size", "", 0,
+		"rsa key size in bits or ecdsa curve size. Default is 2048 for rsa and 256 for ecdsa")
 
 	signCertCmd.Flags().StringSliceVarP(&certHosts, "hosts", "", []string{},
 		"comma separate list of hosts of a certificate")
@@ -59,6 +76,17 @@ func init() {
 	signCertCmd.Flags().StringVarP(&path, "path", "p", "",
 		"path to write certificate and key to. Default is current working directory")
 	signCertCmd.Flags().StringVarP(&certNamePrefix, "name", "n", "cert", "certificate/key filename prefix")
+	signCertCmd.Flags().StringVarP(&keyAlgorithm, "key-algorithm", "", "rsa", "key algorithm: rsa, ecdsa or ed25519")
+	signCertCmd.Flags().IntVarP(&keySize, "key-size", "", 0,
+		"rsa key size in bits or ecdsa curve size. Default is 2048 for rsa and 256 for ecdsa")
+
+	renewCertCmd.Flags().StringSliceVarP(&renewNodes, "node", "", []string{},
+		"comma separated list of nodes to renew the certificates of. Default is all nodes with certificates")
+	renewCertCmd.Flags().BoolVarP(&renewCA, "ca", "", false,
+		"renew the lab root CA along with the certificates of all nodes")
+
+	inspectCertCmd.Flags().StringSliceVarP(&certFiles, "file", "f", []string{},
+		"comma separated list of certificate files to inspect. Default is the certificates of the lab")
 }
 
 var certCmd = &cobra.Command{
@@ -83,12 +111,25 @@ var signCertCmd = &cobra.Command{
 	RunE:  signCert,
 }
 
+var renewCertCmd = &cobra.Command{
+	Use:     "renew",
+	Short:   "renew certificates of the lab nodes",
+	PreRunE: sudoCheck,
+	RunE:    renewCerts,
+}
+
+var inspectCertCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "inspect certificates of the lab or certificate files",
+	RunE:  inspectCerts,
+}
+
 func createCA(_ *cobra.Command, _ []string) error {
 	csr := `{
 	"CN": "{{.CommonName}}",
 	"key": {
-		"algo": "rsa",
-		"size": 2048
+		"algo": "{{.KeyAlgorithm}}",
+		"size": {{.KeySize}}
 	},
 	"names": [{
 		"C": "{{.Country}}",
@@ -126,8 +167,8 @@ func createCA(_ *cobra.Command, _ []string) error {
 		LabCARoot: path,
 	}
 
-	log.Infof("Certificate attributes: CN=%s, C=%s, L=%s, O=%s, OU=%s, Validity period=%s",
-		commonName, country, locality, organization, organizationUnit, expiry)
+	log.Infof("Certificate attributes: CN=%s, C=%s, L=%s, O=%s, OU=%s, Validity period=%s, Key algorithm=%s",
+		commonName, country, locality, organization, organizationUnit, expiry, keyAlgorithm)
 
 	csrTpl, err := template.New("csr").Parse(csr)
 	if err != nil {
@@ -142,6 +183,8 @@ func createCA(_ *cobra.Command, _ []string) error {
 		OrganizationUnit: organizationUnit,
 		Expiry:           expiry,
 		NamePrefix:       caNamePrefix,
+		KeyAlgorithm:     keyAlgorithm,
+		KeySize:          keySize,
 	},
 	)
 	if err != nil {
@@ -161,8 +204,8 @@ func signCert(_ *cobra.Command, _ []string) error {
 			{{- end}}
 		],
 		"key": {
-			"algo": "rsa",
-			"size": 2048
+			"algo": "{{.KeyAlgorithm}}",
+			"size": {{.KeySize}}
 		},
 		"names": [{
 			"C": "{{.Country}}",
@@ -194,8 +237,8 @@ func signCert(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	log.Infof("Creating and signing certificate: Hosts=%q, CN=%s, C=%s, L=%s, O=%s, OU=%s",
-		certHosts, commonName, country, locality, organization, organizationUnit)
+	log.Infof("Creating and signing certificate: Hosts=%q, CN=%s, C=%s, L=%s, O=%s, OU=%s, Key algorithm=%s",
+		certHosts, commonName, country, locality, organization, organizationUnit, keyAlgorithm)
 
 	csrTpl, err := template.New("csr").Parse(csr)
 	if err != nil {
@@ -210,6 +253,8 @@ func signCert(_ *cobra.Command, _ []string) error {
 		Organization:     organization,
 		OrganizationUnit: organizationUnit,
 		Expiry:           expiry,
+		KeyAlgorithm:     keyAlgorithm,
+		KeySize:          keySize,
 		Name:             certNamePrefix,
 	},
 		path,
@@ -220,3 +265,130 @@ func signCert(_ *cobra.Command, _ []string) error {
 
 	return nil
 }
+
+// renewCerts re-issues the certificates of the lab nodes, and the lab root CA if requested.
+func renewCerts(_ *cobra.Command, _ []string) error {
+	c, err := loadCertLab()
+	if err != nil {
+		return err
+	}
+
+	cfssllog.Level = cfssllog.LevelError
+	if debug {
+		cfssllog.Level = cfssllog.LevelDebug
+	}
+
+	if renewCA {
+		// the generated root CA is removed to be created anew, the imported one is imported again
+		for _, f := range []string{"root-ca.pem", "root-ca-key.pem", "root-ca.csr"} {
+			if err := os.Remove(filepath.Join(c.Dir.LabCARoot, f)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := cert.CreateRootCA(c.Config.Name, c.Dir.LabCARoot, c.Config.CertificateAuthority, c.Nodes); err != nil {
+			return err
+		}
+		log.Info("Renewed the lab root CA")
+	}
+
+	nodeNames := renewNodes
+	// all node certificates are signed by the renewed CA
+	if len(nodeNames) == 0 || renewCA {
+		nodeNames = nil
+		for name, n := range c.Nodes {
+			if cert.NeedsCertificate(n.Config()) {
+				nodeNames = append(nodeNames, name)
+			}
+		}
+	}
+	sort.Strings(nodeNames)
+
+	for _, name := range nodeNames {
+		n, ok := c.Nodes[name]
+		if !ok {
+			return fmt.Errorf("node %q is not found in the lab", name)
+		}
+		if !cert.NeedsCertificate(n.Config()) {
+			return fmt.Errorf("node %q has no certificate issued by the lab root CA", name)
+		}
+
+		if _, err := cert.RenewNodeCertificate(n.Config(), c.Config.Name, c.Dir.LabCA, c.Dir.LabCARoot); err != nil {
+			return err
+		}
+		log.Infof("Renewed the certificate of node %s", name)
+	}
+
+	return nil
+}
+
+// inspectCerts prints the summary of the certificate files, or of the lab root CA and the node certificates.
+func inspectCerts(_ *cobra.Command, _ []string) error {
+	names := certFiles
+	files := certFiles
+
+	if len(files) == 0 {
+		c, err := loadCertLab()
+		if err != nil {
+			return err
+		}
+
+		names = []string{"root CA"}
+		files = []string{filepath.Join(c.Dir.LabCARoot, "root-ca.pem")}
+
+		nodeNames := make([]string, 0, len(c.Nodes))
+		for name := range c.Nodes {
+			nodeNames = append(nodeNames, name)
+		}
+		sort.Strings(nodeNames)
+		for _, name := range nodeNames {
+			f := filepath.Join(c.Dir.LabCA, name, name+".pem")
+			if utils.FileExists(f) {
+				names = append(names, name)
+				files = append(files, f)
+			}
+		}
+	}
+
+	tabData := make([][]string, 0, len(files))
+	for i, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		info, err := cert.ParseInfo(b)
+		if err != nil {
+			return fmt.Errorf("%s: %v", f, err)
+		}
+		tabData = append(tabData, []string{
+			names[i], info.Subject, info.Issuer, strings.Join(info.SANs, ", "), info.Key,
+			info.NotAfter.Format(time.RFC3339),
+		})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Subject", "Issuer", "SANs", "Key", "Expires"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+
+	return nil
+}
+
+// loadCertLab loads the lab whose certificates are renewed or inspected.
+func loadCertLab() (*clab.CLab, error) {
+	if name == "" && topo == "" {
+		return nil, fmt.Errorf("provide topology file path with --topo flag")
+	}
+
+	return clab.NewContainerLab(
+		clab.WithTimeout(timeout),
+		labSourceOpt(),
+		clab.WithRuntime(rt,
+			&runtime.RuntimeConfig{
+				Debug:   debug,
+				Timeout: timeout,
+			},
+		),
+	)
+}
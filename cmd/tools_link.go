@@ -0,0 +1,222 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+var (
+	linkEndpoint string
+	linkAEnd     string
+	linkBEnd     string
+	linkMTU      = clab.DefaultVethLinkMTU
+)
+
+func init() {
+	toolsCmd.AddCommand(linkCmd)
+	linkCmd.AddCommand(linkDownCmd)
+	linkCmd.AddCommand(linkUpCmd)
+	linkCmd.AddCommand(linkDeleteCmd)
+	linkCmd.AddCommand(linkAddCmd)
+
+	for _, c := range []*cobra.Command{linkDownCmd, linkUpCmd, linkDeleteCmd} {
+		c.Flags().StringVarP(&linkEndpoint, "endpoint", "e", "",
+			"link endpoint in the format of <node-name>:<interface-name>")
+		_ = c.MarkFlagRequired("endpoint")
+	}
+
+	linkAddCmd.Flags().StringVarP(&linkAEnd, "a-endpoint", "a", "",
+		"link endpoint A in the format of <node-name>:<interface-name>")
+	linkAddCmd.Flags().StringVarP(&linkBEnd, "b-endpoint", "b", "",
+		"link endpoint B in the format of <node-name>:<interface-name>")
+	linkAddCmd.Flags().IntVarP(&linkMTU, "mtu", "m", linkMTU, "link MTU")
+	_ = linkAddCmd.MarkFlagRequired("a-endpoint")
+	_ = linkAddCmd.MarkFlagRequired("b-endpoint")
+}
+
+// linkCmd represents the link command container.
+var linkCmd = &cobra.Command{
+	Use:   "link",
+	Short: "operations on the links of a running lab",
+}
+
+var linkDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "set a link endpoint down",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return linkEndpointOp(linkEndpoint, func(_ *clab.CLab, n *types.NodeConfig, ifName string) error {
+			return clab.SetLinkState(n, ifName, false)
+		})
+	},
+}
+
+var linkUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "set a link endpoint up",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return linkEndpointOp(linkEndpoint, func(_ *clab.CLab, n *types.NodeConfig, ifName string) error {
+			return clab.SetLinkState(n, ifName, true)
+		})
+	},
+}
+
+var linkDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "delete a link by removing one of its endpoints",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return linkEndpointOp(linkEndpoint, func(c *clab.CLab, n *types.NodeConfig, ifName string) error {
+			if err := c.RemoveLink(n, ifName); err != nil {
+				return err
+			}
+			return writeLinkOpsLabState(c)
+		})
+	},
+}
+
+var linkAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "add a link between the nodes of a running lab",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newLinkOpsLab()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a, err := resolveLinkEndpoint(ctx, c, linkAEnd)
+		if err != nil {
+			return err
+		}
+		b, err := resolveLinkEndpoint(ctx, c, linkBEnd)
+		if err != nil {
+			return err
+		}
+
+		link := &types.Link{
+			Type: types.LinkTypeVeth,
+			A:    a,
+			B:    b,
+			MTU:  linkMTU,
+		}
+
+		if err := c.AddLink(link); err != nil {
+			return err
+		}
+
+		if err := writeLinkOpsLabState(c); err != nil {
+			return err
+		}
+
+		log.Infof("Link %s <--> %s successfully added", linkAEnd, linkBEnd)
+		return nil
+	},
+}
+
+// newLinkOpsLab creates a containerlab instance of the lab referenced by the topology file or the lab name.
+// The lab is loaded from its lab state file when it exists, since the links added and deleted
+// by the link operations are only recorded there.
+func newLinkOpsLab() (*clab.CLab, error) {
+	newLab := func(labSource clab.ClabOption) (*clab.CLab, error) {
+		return clab.NewContainerLab(
+			clab.WithTimeout(timeout),
+			labSource,
+			clab.WithRuntime(rt,
+				&runtime.RuntimeConfig{
+					Debug:            debug,
+					Timeout:          timeout,
+					GracefulShutdown: graceful,
+				},
+			),
+		)
+	}
+
+	c, err := newLab(labSourceOpt())
+	if err != nil || topo == "" {
+		return c, err
+	}
+
+	if utils.FileExists(filepath.Join(c.Dir.Lab, clab.LabStateFName)) {
+		return newLab(clab.WithLabState(c.Dir.Lab))
+	}
+
+	return c, nil
+}
+
+// writeLinkOpsLabState writes the lab state with the links changed by a link operation,
+// so that the commands loading the lab from its state and the lab destroy see them.
+func writeLinkOpsLabState(c *clab.CLab) error {
+	if err := c.WriteLabState(); err != nil {
+		return fmt.Errorf("failed to write lab state file: %v", err)
+	}
+	return nil
+}
+
+// linkEndpointOp resolves the endpoint e of a running lab and runs the operation op on it.
+func linkEndpointOp(e string, op func(c *clab.CLab, n *types.NodeConfig, ifName string) error) error {
+	nodeName, ifName, err := parseLinkEndpoint(e)
+	if err != nil {
+		return err
+	}
+
+	c, err := newLinkOpsLab()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, err := c.ResolveEndpointNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+
+	return op(c, n, ifName)
+}
+
+// resolveLinkEndpoint creates a new endpoint with a generated MAC address
+// for the endpoint e of a running lab.
+func resolveLinkEndpoint(ctx context.Context, c *clab.CLab, e string) (*types.Endpoint, error) {
+	nodeName, ifName, err := parseLinkEndpoint(e)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := c.ResolveEndpointNode(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Endpoint{
+		Node:         n,
+		EndpointName: ifName,
+		MAC:          utils.GenMac(clab.ClabOUI),
+	}, nil
+}
+
+// parseLinkEndpoint splits the endpoint e in the <node-name>:<interface-name> format.
+func parseLinkEndpoint(e string) (nodeName, ifName string, err error) {
+	split := strings.Split(e, ":")
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", "", fmt.Errorf("malformed endpoint %q, expected <node-name>:<interface-name>", e)
+	}
+	if len(split[1]) > 15 {
+		return "", "", fmt.Errorf("interface '%s' name exceeds maximum length of 15 characters", split[1])
+	}
+	return split[0], split[1], nil
+}
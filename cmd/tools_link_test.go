@@ -0,0 +1,111 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseLinkEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		in       string
+		wantNode string
+		wantIf   string
+		wantErr  bool
+	}{
+		"node_and_interface": {
+			in:       "srl1:e1-1",
+			wantNode: "srl1",
+			wantIf:   "e1-1",
+		},
+		"host_endpoint": {
+			in:       "host:srl1-e1-1",
+			wantNode: "host",
+			wantIf:   "srl1-e1-1",
+		},
+		"macvlan_endpoint": {
+			in:       "macvlan:enp0s3",
+			wantNode: "macvlan",
+			wantIf:   "enp0s3",
+		},
+		"missing_colon": {
+			in:      "srl1e1-1",
+			wantErr: true,
+		},
+		"empty_node": {
+			in:      ":e1-1",
+			wantErr: true,
+		},
+		"empty_interface": {
+			in:      "srl1:",
+			wantErr: true,
+		},
+		"empty_endpoint": {
+			in:      "",
+			wantErr: true,
+		},
+		"interface_with_colon": {
+			in:      "srl1:e1-1:1",
+			wantErr: true,
+		},
+		"interface_name_too_long": {
+			in:      "srl1:ethernet-1-1-1-1",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			node, ifName, err := parseLinkEndpoint(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLinkEndpoint(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if node != tt.wantNode || ifName != tt.wantIf {
+				t.Errorf("parseLinkEndpoint(%q) = %q, %q, want %q, %q",
+					tt.in, node, ifName, tt.wantNode, tt.wantIf)
+			}
+		})
+	}
+}
+
+func TestLinkCmdTopoFileDiscovery(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lab.clab.yml"), []byte("name: lab\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	defer func() { topo, name = "", "" }()
+
+	for _, c := range []*cobra.Command{linkDownCmd, linkUpCmd, linkDeleteCmd, linkAddCmd} {
+		topo, name = "", ""
+		if err := getTopoFilePath(c); err != nil {
+			t.Fatalf("link %s: %v", c.Name(), err)
+		}
+		if topo != "lab.clab.yml" {
+			t.Errorf("link %s: expected topology file lab.clab.yml to be discovered, got %q", c.Name(), topo)
+		}
+	}
+
+	// the lab name given explicitly is used instead of the topology file
+	topo, name = "", "lab"
+	if err := getTopoFilePath(linkAddCmd); err != nil {
+		t.Fatal(err)
+	}
+	if topo != "" {
+		t.Errorf("expected no topology file with the lab name set, got %q", topo)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/olekukonko/tablewriter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/vishvananda/netlink"
+)
+
+var (
+	netemNode       string
+	netemIntf       string
+	netemImpairment types.LinkImpairment
+)
+
+func init() {
+	toolsCmd.AddCommand(netemCmd)
+	netemCmd.AddCommand(netemSetCmd)
+	netemCmd.AddCommand(netemShowCmd)
+	netemCmd.AddCommand(netemResetCmd)
+
+	for _, c := range []*cobra.Command{netemSetCmd, netemShowCmd, netemResetCmd} {
+		c.Flags().StringVarP(&netemNode, "node", "", "", "container name of the node")
+		_ = c.MarkFlagRequired("node")
+	}
+
+	netemSetCmd.Flags().StringVarP(&netemIntf, "intf", "i", "", "interface name to set the impairment on")
+	netemSetCmd.Flags().StringVarP(&netemImpairment.Delay, "delay", "", "",
+		"link delay in the duration format, e.g. 10ms")
+	netemSetCmd.Flags().StringVarP(&netemImpairment.Jitter, "jitter", "", "",
+		"delay jitter in the duration format, e.g. 2ms. Requires delay to be set")
+	netemSetCmd.Flags().Float64VarP(&netemImpairment.Loss, "loss", "", 0, "packet loss in percent")
+	netemSetCmd.Flags().Uint64VarP(&netemImpairment.Rate, "rate", "", 0, "link rate limit in kbit/s")
+	netemSetCmd.Flags().Float64VarP(&netemImpairment.Corruption, "corruption", "", 0,
+		"packet corruption probability in percent")
+	netemSetCmd.Flags().Float64VarP(&netemImpairment.Duplicate, "duplicate", "", 0,
+		"packet duplication probability in percent")
+	_ = netemSetCmd.MarkFlagRequired("intf")
+
+	netemShowCmd.Flags().StringVarP(&netemIntf, "intf", "i", "",
+		"interface name to show the impairment of. All interfaces are shown if not set")
+
+	netemResetCmd.Flags().StringVarP(&netemIntf, "intf", "i", "", "interface name to remove the impairment from")
+	_ = netemResetCmd.MarkFlagRequired("intf")
+}
+
+// netemCmd represents the netem command container.
+var netemCmd = &cobra.Command{
+	Use:   "netem",
+	Short: "link impairment operations",
+}
+
+var netemSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "set link impairments on a node interface",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if netemImpairment.IsEmpty() {
+			return errors.New("at least one impairment should be set, use 'netem reset' to remove impairments")
+		}
+		if err := netemImpairment.Validate(); err != nil {
+			return err
+		}
+
+		err := inContainerNetns(netemNode, func() error {
+			return clab.SetImpairment(netemIntf, &netemImpairment)
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Impairment successfully set on %s:%s", netemNode, netemIntf)
+		return nil
+	},
+}
+
+var netemShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "show link impairments of a node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tabData := [][]string{}
+
+		err := inContainerNetns(netemNode, func() error {
+			ifNames := []string{netemIntf}
+			if netemIntf == "" {
+				links, err := netlink.LinkList()
+				if err != nil {
+					return err
+				}
+				ifNames = ifNames[:0]
+				for _, l := range links {
+					if l.Attrs().Name != "lo" {
+						ifNames = append(ifNames, l.Attrs().Name)
+					}
+				}
+			}
+
+			for _, ifName := range ifNames {
+				li, err := clab.GetImpairment(ifName)
+				if err != nil {
+					return err
+				}
+				tabData = append(tabData, impairmentTableRow(ifName, li))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Interface", "Delay", "Jitter", "Loss (%)", "Rate (kbit/s)", "Corruption (%)", "Duplicate (%)"})
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		table.AppendBulk(tabData)
+		table.Render()
+
+		return nil
+	},
+}
+
+var netemResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "remove link impairments from a node interface",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := inContainerNetns(netemNode, func() error {
+			return clab.ResetImpairment(netemIntf)
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Impairment successfully removed from %s:%s", netemNode, netemIntf)
+		return nil
+	},
+}
+
+// inContainerNetns runs f in the network namespace of the container cntName.
+func inContainerNetns(cntName string, f func() error) error {
+	opts := []clab.ClabOption{
+		clab.WithTimeout(timeout),
+		clab.WithRuntime(rt,
+			&runtime.RuntimeConfig{
+				Debug:            debug,
+				Timeout:          timeout,
+				GracefulShutdown: graceful,
+			},
+		),
+	}
+	c, err := clab.NewContainerLab(opts...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nsPath, err := c.GlobalRuntime().GetNSPath(ctx, cntName)
+	if err != nil {
+		return fmt.Errorf("failed to get network namespace of container %q: %v", cntName, err)
+	}
+
+	nodeNS, err := ns.GetNS(nsPath)
+	if err != nil {
+		return err
+	}
+	defer nodeNS.Close()
+
+	return nodeNS.Do(func(_ ns.NetNS) error { return f() })
+}
+
+func impairmentTableRow(ifName string, li *types.LinkImpairment) []string {
+	if li == nil {
+		return []string{ifName, "N/A", "N/A", "N/A", "N/A", "N/A", "N/A"}
+	}
+
+	return []string{
+		ifName,
+		li.Delay,
+		li.Jitter,
+		strconv.FormatFloat(li.Loss, 'f', -1, 64),
+		strconv.FormatUint(li.Rate, 10),
+		strconv.FormatFloat(li.Corruption, 'f', -1, 64),
+		strconv.FormatFloat(li.Duplicate, 'f', -1, 64),
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/nodes"
+	"github.com/srl-labs/containerlab/runtime"
+)
+
+// waitTimeout is the time to wait for the lab nodes to become healthy.
+var waitTimeout time.Duration
+
+// waitCmd represents the wait command.
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "wait for the lab nodes to become healthy",
+	Long: `wait blocks until all nodes of the lab are healthy according to their healthchecks,
+or to the readiness probes of their kinds for the nodes without a healthcheck.
+reference: https://containerlab.dev/cmd/wait/`,
+	PreRunE: sudoCheck,
+	RunE:    waitFn,
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	// the wait timeout shadows the global timeout of the runtime requests
+	waitCmd.Flags().DurationVarP(&waitTimeout, "timeout", "", 10*time.Minute,
+		"time to wait for the nodes to become healthy, e.g: 30s, 10m")
+}
+
+// nodeWaitResult is the result of waiting for a node to become healthy.
+type nodeWaitResult struct {
+	kind    string
+	elapsed time.Duration
+	err     error
+}
+
+func waitFn(_ *cobra.Command, _ []string) error {
+	if name == "" && topo == "" {
+		return fmt.Errorf("provide either a lab name (--name) or a topology file path (--topo)")
+	}
+
+	c, err := clab.NewContainerLab(
+		clab.WithTimeout(timeout),
+		labSourceOpt(),
+		clab.WithRuntime(rt,
+			&runtime.RuntimeConfig{
+				Debug:   debug,
+				Timeout: timeout,
+			},
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	log.Infof("Waiting up to %s for %d nodes to become healthy", waitTimeout, len(c.Nodes))
+
+	start := time.Now()
+	results := make(map[string]*nodeWaitResult, len(c.Nodes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(c.Nodes))
+	for nodeName, n := range c.Nodes {
+		go func(nodeName string, n nodes.Node) {
+			defer wg.Done()
+
+			err := nodes.WaitHealthy(ctx, n)
+			r := &nodeWaitResult{kind: n.Config().Kind, elapsed: time.Since(start), err: err}
+			if err == nil {
+				log.Infof("Node %s is healthy after %s", nodeName, r.elapsed.Round(time.Second))
+			}
+
+			mu.Lock()
+			results[nodeName] = r
+			mu.Unlock()
+		}(nodeName, n)
+	}
+	wg.Wait()
+
+	return printWaitResults(results)
+}
+
+// printWaitResults prints the health status of the nodes and the time they took to become healthy,
+// and returns an error when some of the nodes are not healthy.
+func printWaitResults(results map[string]*nodeWaitResult) error {
+	nodeNames := make([]string, 0, len(results))
+	for nodeName := range results {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+
+	unhealthy := 0
+	tabData := make([][]string, 0, len(results))
+	for _, nodeName := range nodeNames {
+		r := results[nodeName]
+		status, elapsed := "healthy", r.elapsed.Round(time.Second).String()
+		if r.err != nil {
+			unhealthy++
+			status, elapsed = "unhealthy", "-"
+			log.Error(r.err)
+		}
+		tabData = append(tabData, []string{nodeName, r.kind, status, elapsed})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Kind", "Status", "Time"})
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(tabData)
+	table.Render()
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d nodes are not healthy", unhealthy, len(results))
+	}
+	return nil
+}
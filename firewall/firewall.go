@@ -0,0 +1,112 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package firewall manages the firewall rules which allow the external access to the lab management network.
+// The rules are programmed either with the iptables binary, or natively over netlink on the hosts using nftables.
+package firewall
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DockerUserChain is the chain docker evaluates the user rules in before its own forwarding rules.
+	DockerUserChain = "DOCKER-USER"
+	// ForwardChain is the chain of the forwarded packets.
+	ForwardChain = "FORWARD"
+
+	// IPTablesBackend is the name of the backend programming the rules with the iptables binary.
+	IPTablesBackend = "iptables"
+	// NFTablesBackend is the name of the backend programming the rules over netlink.
+	NFTablesBackend = "nftables"
+
+	// ruleComment marks the rules installed by containerlab.
+	ruleComment = "set by containerlab"
+)
+
+// ErrChainNotFound is returned when the chain the rule is installed in doesn't exist.
+var ErrChainNotFound = errors.New("chain not found")
+
+// Client installs and deletes the rules accepting the packets forwarded to a bridge.
+type Client interface {
+	// Name returns the name of the backend of the client.
+	Name() string
+	// InstallForwardingRule installs the rule accepting the packets forwarded to the bridge
+	// on top of the chain of the filter table, unless the rule is already installed.
+	InstallForwardingRule(chain, bridge string) error
+	// DeleteForwardingRule deletes the rule installed with InstallForwardingRule.
+	DeleteForwardingRule(chain, bridge string) error
+}
+
+var (
+	hostClient     Client
+	hostClientOnce sync.Once
+)
+
+// HostClient returns the client of the backend in use on the host.
+// The backend is detected once per containerlab run.
+func HostClient() Client {
+	hostClientOnce.Do(func() {
+		switch detectBackend() {
+		case NFTablesBackend:
+			hostClient = newNFTablesClient()
+		default:
+			hostClient = newIPTablesClient()
+		}
+		log.Debugf("using %s firewall backend", hostClient.Name())
+	})
+
+	return hostClient
+}
+
+// detectBackend returns the name of the backend in use on the host.
+// The nftables backend is used when the iptables binary is missing or is the iptables-nft variant,
+// which programs nftables under the hood.
+func detectBackend() string {
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return NFTablesBackend
+	}
+
+	out, err := exec.Command("iptables", "--version").Output()
+	if err != nil {
+		return IPTablesBackend
+	}
+
+	return backendFromVersion(string(out))
+}
+
+// backendFromVersion returns the backend matching the output of iptables --version,
+// e.g. "iptables v1.8.7 (nf_tables)".
+func backendFromVersion(v string) string {
+	if strings.Contains(v, "nf_tables") {
+		return NFTablesBackend
+	}
+	return IPTablesBackend
+}
+
+// InstallBridgeRule installs the rule accepting the packets forwarded to the bridge in the FORWARD chain
+// with the backend in use on the host. It is used by the runtimes which, unlike docker, have no chain for the user rules.
+// The hosts without the FORWARD chain don't filter the forwarded packets, so the missing chain is not an error.
+func InstallBridgeRule(bridge string) error {
+	err := HostClient().InstallForwardingRule(ForwardChain, bridge)
+	if errors.Is(err, ErrChainNotFound) {
+		log.Debugf("skipping setup of forwarding rule for bridge %q: %v", bridge, err)
+		return nil
+	}
+	return err
+}
+
+// DeleteBridgeRule deletes the rule installed with InstallBridgeRule.
+func DeleteBridgeRule(bridge string) error {
+	err := HostClient().DeleteForwardingRule(ForwardChain, bridge)
+	if errors.Is(err, ErrChainNotFound) {
+		return nil
+	}
+	return err
+}
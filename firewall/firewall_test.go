@@ -0,0 +1,111 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package firewall
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+)
+
+func TestBackendFromVersion(t *testing.T) {
+	tests := map[string]string{
+		"iptables v1.8.7 (nf_tables)\n": NFTablesBackend,
+		"iptables v1.8.7 (legacy)\n":    IPTablesBackend,
+		"iptables v1.4.21\n":            IPTablesBackend,
+	}
+
+	for v, want := range tests {
+		if got := backendFromVersion(v); got != want {
+			t.Errorf("backend of %q: expected %s, got %s", v, want, got)
+		}
+	}
+}
+
+func TestNFTMatchesOutIface(t *testing.T) {
+	if !nftMatchesOutIface(nftForwardingRuleExprs("br-1234"), "br-1234") {
+		t.Errorf("expected the forwarding rule to match its bridge")
+	}
+	if nftMatchesOutIface(nftForwardingRuleExprs("br-1234"), "br-12") {
+		t.Errorf("expected the forwarding rule not to match the bridge name prefix")
+	}
+
+	// nft pads the interface names to IFNAMSIZ
+	padded := make([]byte, 16)
+	copy(padded, "br-1234")
+	exprs := []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: padded},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	}
+	if !nftMatchesOutIface(exprs, "br-1234") {
+		t.Errorf("expected the rule with the padded interface name to match")
+	}
+
+	exprs[0] = &expr.Meta{Key: expr.MetaKeyIIFNAME, Register: 1}
+	if nftMatchesOutIface(exprs, "br-1234") {
+		t.Errorf("expected the rule matching the input interface not to match")
+	}
+}
+
+func TestNFTRuleUserData(t *testing.T) {
+	ud := nftRuleUserData()
+	if ud[0] != 0 || int(ud[1]) != len(ruleComment)+1 || string(ud[2:len(ud)-1]) != ruleComment || ud[len(ud)-1] != 0 {
+		t.Errorf("unexpected user data %q", ud)
+	}
+}
+
+func TestUpdateNFTState(t *testing.T) {
+	fPath := filepath.Join(t.TempDir(), "firewall", "nftables-rules.json")
+
+	steps := []struct {
+		update func(map[string]uint64) error
+		want   map[string]uint64
+	}{
+		{
+			update: func(h map[string]uint64) error { h[handleKey(DockerUserChain, "br-1")] = 10; return nil },
+			want:   map[string]uint64{"DOCKER-USER/br-1": 10},
+		},
+		{
+			update: func(h map[string]uint64) error { h[handleKey(ForwardChain, "br-2")] = 20; return nil },
+			want:   map[string]uint64{"DOCKER-USER/br-1": 10, "FORWARD/br-2": 20},
+		},
+		{
+			// the handles are not written back when the update fails
+			update: func(h map[string]uint64) error { delete(h, "DOCKER-USER/br-1"); return errors.New("failed") },
+			want:   map[string]uint64{"DOCKER-USER/br-1": 10, "FORWARD/br-2": 20},
+		},
+		{
+			update: func(h map[string]uint64) error { delete(h, "DOCKER-USER/br-1"); return nil },
+			want:   map[string]uint64{"FORWARD/br-2": 20},
+		},
+	}
+
+	for i, s := range steps {
+		_ = updateNFTState(fPath, s.update)
+
+		var got map[string]uint64
+		if err := updateNFTState(fPath, func(h map[string]uint64) error { got = h; return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if d := cmp.Diff(s.want, got); d != "" {
+			t.Errorf("step %d: handles mismatch (-want +got):\n%s", i, d)
+		}
+	}
+}
+
+func TestHasNFTRule(t *testing.T) {
+	rules := []*nftables.Rule{{Handle: 5}, {Handle: 7}}
+	if !hasNFTRule(rules, 7) {
+		t.Error("expected the rule with handle 7 to be found")
+	}
+	if hasNFTRule(rules, 6) {
+		t.Error("expected the rule with handle 6 not to be found")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/google/shlex"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	iptCheckCmd = "-vL %s"
+	iptAllowCmd = "-I %s -o %s -j ACCEPT -m comment --comment \"" + ruleComment + "\""
+	iptDelCmd   = "-D %s -o %s -j ACCEPT -m comment --comment \"" + ruleComment + "\""
+)
+
+// iptablesClient programs the rules with the iptables binary.
+type iptablesClient struct{}
+
+func newIPTablesClient() *iptablesClient {
+	return &iptablesClient{}
+}
+
+// Name returns the name of the iptables backend.
+func (*iptablesClient) Name() string {
+	return IPTablesBackend
+}
+
+// InstallForwardingRule calls iptables to install the `allow` rule for the packets forwarded to the bridge.
+func (c *iptablesClient) InstallForwardingRule(chain, bridge string) error {
+	// first check if a rule already exists to not create duplicates
+	found, err := c.ruleExists(chain, bridge)
+	if err != nil {
+		return err
+	}
+	if found {
+		log.Debugf("found iptables forwarding rule targeting the bridge %q. Skipping creation of the forwarding rule.", bridge)
+		return nil
+	}
+
+	cmd, err := shlex.Split(fmt.Sprintf(iptAllowCmd, chain, bridge))
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Installing iptables rules for bridge %q", bridge)
+
+	stdOutErr, err := exec.Command("iptables", cmd...).CombinedOutput()
+	if err != nil {
+		log.Warnf("Iptables install stdout/stderr result is: %s", stdOutErr)
+		return fmt.Errorf("unable to install iptables rule using '%s' command: %w", cmd, err)
+	}
+	return nil
+}
+
+// DeleteForwardingRule calls iptables to delete the `allow` rule installed with InstallForwardingRule.
+func (c *iptablesClient) DeleteForwardingRule(chain, bridge string) error {
+	// first check if a rule exists before trying to delete it
+	found, err := c.ruleExists(chain, bridge)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Debug("external access iptables rule doesn't exist. Skipping deletion")
+		return nil
+	}
+
+	cmd, err := shlex.Split(fmt.Sprintf(iptDelCmd, chain, bridge))
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("removing clab iptables rules for bridge %q", bridge)
+	log.Debugf("trying to delete the forwarding rule with cmd: iptables %s", cmd)
+
+	stdOutErr, err := exec.Command("iptables", cmd...).CombinedOutput()
+	if err != nil {
+		log.Warnf("Iptables delete stdout/stderr result is: %s", stdOutErr)
+		return fmt.Errorf("unable to delete iptables rules: %w", err)
+	}
+
+	return nil
+}
+
+// ruleExists returns true when the chain has a rule referencing the bridge.
+func (*iptablesClient) ruleExists(chain, bridge string) (bool, error) {
+	res, err := exec.Command("iptables", strings.Split(fmt.Sprintf(iptCheckCmd, chain), " ")...).Output()
+	if err != nil {
+		// non nil error typically means that the chain doesn't exist
+		return false, fmt.Errorf("%s iptables chain: %w", chain, ErrChainNotFound)
+	}
+
+	return bytes.Contains(res, []byte(bridge)), nil
+}
@@ -0,0 +1,289 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package firewall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/nftables"
+	"github.com/google/nftables/expr"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// nftFilterTable is the table with the chains programmed by iptables-nft and docker.
+const nftFilterTable = "filter"
+
+// nftStateFile is the file the handles of the installed rules are persisted to, keyed by the chain and the bridge,
+// so that exactly the installed rules are deleted by a later containerlab run, e.g. the one destroying the lab.
+// The bridges of the management networks can be shared by the labs, so the file is kept per host, not per lab.
+var nftStateFile = "/run/containerlab/firewall/nftables-rules.json"
+
+// nftablesClient programs the rules natively over netlink.
+type nftablesClient struct {
+	mu sync.Mutex
+}
+
+func newNFTablesClient() *nftablesClient {
+	return &nftablesClient{}
+}
+
+// Name returns the name of the nftables backend.
+func (*nftablesClient) Name() string {
+	return NFTablesBackend
+}
+
+// InstallForwardingRule inserts the rule accepting the packets forwarded to the bridge on top of the chain.
+// The rule matching the bridge which was not installed by the client, e.g. by an older containerlab version,
+// is left in place and is not adopted, so that it is not deleted with DeleteForwardingRule.
+func (c *nftablesClient) InstallForwardingRule(chain, bridge string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := nftables.New()
+	if err != nil {
+		return err
+	}
+
+	ch, err := findNFTChain(conn, chain)
+	if err != nil {
+		return err
+	}
+
+	key := handleKey(chain, bridge)
+
+	return updateNFTState(nftStateFile, func(handles map[string]uint64) error {
+		// first check if a rule already exists to not create duplicates
+		rules, err := findNFTRules(conn, ch, bridge)
+		if err != nil {
+			return err
+		}
+		if h, ok := handles[key]; ok && hasNFTRule(rules, h) {
+			log.Debugf("nftables forwarding rule for the bridge %q is already installed", bridge)
+			return nil
+		}
+		// the handle of a rule deleted by other means is stale
+		delete(handles, key)
+		if len(rules) != 0 {
+			log.Debugf("found nftables forwarding rule targeting the bridge %q. Skipping creation of the forwarding rule.", bridge)
+			return nil
+		}
+
+		log.Debugf("Installing nftables rule for bridge %q", bridge)
+
+		conn.InsertRule(&nftables.Rule{
+			Table:    ch.Table,
+			Chain:    ch,
+			Exprs:    nftForwardingRuleExprs(bridge),
+			UserData: nftRuleUserData(),
+		})
+		if err := conn.Flush(); err != nil {
+			return fmt.Errorf("unable to install nftables rule for bridge %q: %w", bridge, err)
+		}
+
+		// the handle of the rule is assigned by the kernel, so the installed rule is read back
+		rules, err = findNFTRules(conn, ch, bridge)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return fmt.Errorf("nftables rule for bridge %q not found after its installation", bridge)
+		}
+		handles[key] = rules[0].Handle
+
+		return nil
+	})
+}
+
+// DeleteForwardingRule deletes the rule installed with InstallForwardingRule,
+// which handle is persisted in the state file by the containerlab run that installed it.
+func (c *nftablesClient) DeleteForwardingRule(chain, bridge string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	conn, err := nftables.New()
+	if err != nil {
+		return err
+	}
+
+	ch, err := findNFTChain(conn, chain)
+	if err != nil {
+		return err
+	}
+
+	key := handleKey(chain, bridge)
+
+	return updateNFTState(nftStateFile, func(handles map[string]uint64) error {
+		h, ok := handles[key]
+		if !ok {
+			log.Debug("external access nftables rule doesn't exist. Skipping deletion")
+			return nil
+		}
+
+		rules, err := findNFTRules(conn, ch, bridge)
+		if err != nil {
+			return err
+		}
+		// the handle could be reused by another rule if the installed rule was deleted by other means
+		if !hasNFTRule(rules, h) {
+			log.Debugf("nftables rule for bridge %q with handle %d doesn't exist. Skipping deletion", bridge, h)
+			delete(handles, key)
+			return nil
+		}
+
+		log.Debugf("removing clab nftables rule for bridge %q with handle %d", bridge, h)
+
+		if err := conn.DelRule(&nftables.Rule{Table: ch.Table, Chain: ch, Handle: h}); err != nil {
+			return err
+		}
+		if err := conn.Flush(); err != nil {
+			return fmt.Errorf("unable to delete nftables rules: %w", err)
+		}
+		delete(handles, key)
+
+		return nil
+	})
+}
+
+// hasNFTRule returns true when the rule with the handle h is one of the rules.
+func hasNFTRule(rules []*nftables.Rule, h uint64) bool {
+	for _, r := range rules {
+		if r.Handle == h {
+			return true
+		}
+	}
+	return false
+}
+
+// updateNFTState calls update with the rule handles read from the state file fPath
+// and writes the handles back unless update fails.
+// The file is locked for the time of the update, since the rules can be installed
+// and deleted by several containerlab runs at once.
+func updateNFTState(fPath string, update func(handles map[string]uint64) error) error {
+	if err := os.MkdirAll(filepath.Dir(fPath), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open nftables state file: %v", err)
+	}
+	defer f.Close()
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock nftables state file: %v", err)
+	}
+
+	handles := map[string]uint64{}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if len(b) != 0 {
+		if err := json.Unmarshal(b, &handles); err != nil {
+			return fmt.Errorf("failed to parse nftables state file %s: %v", fPath, err)
+		}
+	}
+
+	if err := update(handles); err != nil {
+		return err
+	}
+
+	b, err = json.Marshal(handles)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(b, 0); err != nil {
+		return fmt.Errorf("failed to write nftables state file: %v", err)
+	}
+
+	return f.Close()
+}
+
+// findNFTChain returns the chain of the IPv4 filter table.
+func findNFTChain(conn *nftables.Conn, chain string) (*nftables.Chain, error) {
+	chains, err := conn.ListChainsOfTableFamily(nftables.TableFamilyIPv4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables chains: %v", err)
+	}
+
+	for _, ch := range chains {
+		if ch.Table.Name == nftFilterTable && ch.Name == chain {
+			return ch, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s nftables chain: %w", chain, ErrChainNotFound)
+}
+
+// findNFTRules returns the rules of the chain installed by containerlab for the bridge.
+func findNFTRules(conn *nftables.Conn, ch *nftables.Chain, bridge string) ([]*nftables.Rule, error) {
+	rules, err := conn.GetRules(ch.Table, ch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables rules of %s chain: %v", ch.Name, err)
+	}
+
+	var found []*nftables.Rule
+	for _, r := range rules {
+		if bytes.Contains(r.UserData, nftRuleUserData()) && nftMatchesOutIface(r.Exprs, bridge) {
+			found = append(found, r)
+		}
+	}
+
+	return found, nil
+}
+
+// nftForwardingRuleExprs returns the expressions of the rule accepting the packets forwarded to the bridge,
+// which is the same rule iptables-nft programs for `-o <bridge> -j ACCEPT`.
+func nftForwardingRuleExprs(bridge string) []expr.Any {
+	return []expr.Any{
+		&expr.Meta{Key: expr.MetaKeyOIFNAME, Register: 1},
+		&expr.Cmp{Op: expr.CmpOpEq, Register: 1, Data: append([]byte(bridge), 0)},
+		&expr.Counter{},
+		&expr.Verdict{Kind: expr.VerdictAccept},
+	}
+}
+
+// nftMatchesOutIface returns true when the expressions of the rule match the packets sent out of the interface.
+func nftMatchesOutIface(exprs []expr.Any, iface string) bool {
+	for i := 0; i < len(exprs)-1; i++ {
+		m, ok := exprs[i].(*expr.Meta)
+		if !ok || m.Key != expr.MetaKeyOIFNAME {
+			continue
+		}
+		cmp, ok := exprs[i+1].(*expr.Cmp)
+		if !ok || cmp.Op != expr.CmpOpEq || cmp.Register != m.Register {
+			continue
+		}
+		// the interface name is null terminated and might be padded to IFNAMSIZ
+		if string(bytes.TrimRight(cmp.Data, "\x00")) == iface {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nftRuleUserData returns the user data with the comment of the rules,
+// encoded as the comment TLV of libnftnl, so that nft and iptables-nft display it.
+func nftRuleUserData() []byte {
+	const udataRuleComment = 0
+
+	comment := append([]byte(ruleComment), 0)
+	return append([]byte{udataRuleComment, byte(len(comment))}, comment...)
+}
+
+func handleKey(chain, bridge string) string {
+	return chain + "/" + bridge
+}
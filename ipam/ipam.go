@@ -0,0 +1,175 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package ipam allocates subnets from address pools.
+package ipam
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Pool is a pool of subnets of the same length carved out of a prefix.
+type Pool struct {
+	Prefix netip.Prefix
+	// Bits is the prefix length of the allocated subnets
+	Bits int
+}
+
+// ParsePool parses a pool definition in the "<prefix> [as /<length>]" format, e.g. "10.0.0.0/16 as /31".
+// When the length of the subnets is omitted, v4Bits or v6Bits is used depending on the address family of the prefix.
+func ParsePool(s string, v4Bits, v6Bits int) (*Pool, error) {
+	f := strings.Fields(s)
+	if len(f) != 1 && (len(f) != 3 || f[1] != "as") {
+		return nil, fmt.Errorf("invalid pool %q, expected format is \"<prefix> [as /<length>]\"", s)
+	}
+
+	prefix, err := netip.ParsePrefix(f[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid pool %q: %v", s, err)
+	}
+
+	p := &Pool{Prefix: prefix.Masked(), Bits: v4Bits}
+	if prefix.Addr().Is6() {
+		p.Bits = v6Bits
+	}
+
+	if len(f) == 3 {
+		p.Bits, err = strconv.Atoi(strings.TrimPrefix(f[2], "/"))
+		if err != nil || !strings.HasPrefix(f[2], "/") {
+			return nil, fmt.Errorf("invalid subnet length %q of pool %q", f[2], s)
+		}
+	}
+
+	if p.Bits < p.Prefix.Bits() || p.Bits > p.Prefix.Addr().BitLen() {
+		return nil, fmt.Errorf("subnet length /%d of pool %q must be between /%d and /%d",
+			p.Bits, s, p.Prefix.Bits(), p.Prefix.Addr().BitLen())
+	}
+
+	return p, nil
+}
+
+// String returns the pool in the format accepted by ParsePool.
+func (p *Pool) String() string {
+	return fmt.Sprintf("%s as /%d", p.Prefix, p.Bits)
+}
+
+// size returns the number of subnets in the pool, capped to the max uint64 value.
+func (p *Pool) size() uint64 {
+	n := p.Bits - p.Prefix.Bits()
+	if n >= 64 {
+		return math.MaxUint64
+	}
+	return 1 << n
+}
+
+// subnet returns the i-th subnet of the pool.
+func (p *Pool) subnet(i uint64) netip.Prefix {
+	b := p.Prefix.Addr().AsSlice()
+
+	n := new(big.Int).SetBytes(b)
+	n.Add(n, new(big.Int).Lsh(new(big.Int).SetUint64(i), uint(len(b)*8-p.Bits)))
+
+	addr, _ := netip.AddrFromSlice(n.FillBytes(make([]byte, len(b))))
+
+	return netip.PrefixFrom(addr, p.Bits)
+}
+
+// Allocator allocates the subnets of a pool to keys.
+// The lowest free subnet is allocated to a key, thus the allocations are deterministic
+// for a given order of the keys and the subnets reserved in advance.
+type Allocator struct {
+	pool *Pool
+	// allocations of subnets to keys
+	allocs map[string]netip.Prefix
+	// used subnets
+	used map[netip.Prefix]struct{}
+	// next is the index of the subnet the search for a free subnet starts from
+	next uint64
+}
+
+// NewAllocator returns an allocator of the subnets of pool p.
+func NewAllocator(p *Pool) *Allocator {
+	return &Allocator{
+		pool:   p,
+		allocs: make(map[string]netip.Prefix),
+		used:   make(map[netip.Prefix]struct{}),
+	}
+}
+
+// Reserve allocates subnet s to key, which is used to restore previous allocations.
+// False is returned when s is not a subnet of the pool or it is already allocated.
+func (a *Allocator) Reserve(key string, s netip.Prefix) bool {
+	if s.Bits() != a.pool.Bits || !a.pool.Prefix.Contains(s.Addr()) || s.Masked() != s {
+		return false
+	}
+	if _, ok := a.used[s]; ok {
+		return false
+	}
+	if _, ok := a.allocs[key]; ok {
+		return false
+	}
+
+	a.allocs[key] = s
+	a.used[s] = struct{}{}
+
+	return true
+}
+
+// Exclude marks subnet s as used without allocating it to a key.
+func (a *Allocator) Exclude(s netip.Prefix) {
+	a.used[s] = struct{}{}
+}
+
+// Allocate returns the subnet allocated to key, allocating the lowest free subnet of the pool
+// when key doesn't have an allocation yet.
+func (a *Allocator) Allocate(key string) (netip.Prefix, error) {
+	if s, ok := a.allocs[key]; ok {
+		return s, nil
+	}
+
+	for ; a.next < a.pool.size(); a.next++ {
+		s := a.pool.subnet(a.next)
+		if _, ok := a.used[s]; ok {
+			continue
+		}
+
+		a.allocs[key] = s
+		a.used[s] = struct{}{}
+		a.next++
+
+		return s, nil
+	}
+
+	return netip.Prefix{}, fmt.Errorf("pool %s is exhausted", a.pool)
+}
+
+// Allocations returns the subnets allocated to keys.
+func (a *Allocator) Allocations() map[string]netip.Prefix {
+	m := make(map[string]netip.Prefix, len(a.allocs))
+	for k, s := range a.allocs {
+		m[k] = s
+	}
+	return m
+}
+
+// PointToPoint returns the addresses of the two ends of a point-to-point subnet with its prefix length.
+// Both addresses of /31 and /127 subnets are used, the first two host addresses otherwise.
+func PointToPoint(s netip.Prefix) (netip.Prefix, netip.Prefix, error) {
+	a := s.Masked().Addr()
+
+	switch s.Addr().BitLen() - s.Bits() {
+	case 0:
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("subnet %s is too small for a point-to-point link", s)
+	case 1:
+	default:
+		a = a.Next()
+	}
+
+	return netip.PrefixFrom(a, s.Bits()), netip.PrefixFrom(a.Next(), s.Bits()), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ipam
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParsePool(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		"ipv4 with length":           {in: "10.0.0.0/16 as /31", want: "10.0.0.0/16 as /31"},
+		"ipv4 default length":        {in: "10.255.0.0/24", want: "10.255.0.0/24 as /32"},
+		"ipv6 default length":        {in: "2001:db8::/64", want: "2001:db8::/64 as /128"},
+		"prefix is masked":           {in: "10.0.0.1/16 as /30", want: "10.0.0.0/16 as /30"},
+		"length shorter than prefix": {in: "10.0.0.0/16 as /8", wantErr: true},
+		"length longer than address": {in: "10.0.0.0/16 as /33", wantErr: true},
+		"missing slash":              {in: "10.0.0.0/16 as 31", wantErr: true},
+		"invalid keyword":            {in: "10.0.0.0/16 by /31", wantErr: true},
+		"invalid prefix":             {in: "10.0.0/16", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := ParsePool(tc.in, 32, 128)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if err == nil && p.String() != tc.want {
+				t.Errorf("expected pool %s, got %s", tc.want, p)
+			}
+		})
+	}
+}
+
+func TestAllocator(t *testing.T) {
+	p, err := ParsePool("2001:db8::/126 as /127", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAllocator(p)
+
+	// a previous allocation of the second subnet
+	if !a.Reserve("b", netip.MustParsePrefix("2001:db8::2/127")) {
+		t.Fatal("expected reservation to succeed")
+	}
+	for _, s := range []string{"2001:db8::2/127", "2001:db8::4/127", "2001:db8::/64"} {
+		if a.Reserve("c", netip.MustParsePrefix(s)) {
+			t.Errorf("expected reservation of %s to fail", s)
+		}
+	}
+
+	got, err := a.Allocate("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := netip.MustParsePrefix("2001:db8::/127"); got != want {
+		t.Errorf("expected %s to be allocated, got %s", want, got)
+	}
+
+	// existing allocations are returned
+	got, _ = a.Allocate("b")
+	if want := netip.MustParsePrefix("2001:db8::2/127"); got != want {
+		t.Errorf("expected %s to be allocated, got %s", want, got)
+	}
+
+	if _, err := a.Allocate("c"); err == nil {
+		t.Error("expected exhausted pool error")
+	}
+}
+
+func TestPointToPoint(t *testing.T) {
+	tests := map[string][2]string{
+		"10.0.0.2/31":     {"10.0.0.2/31", "10.0.0.3/31"},
+		"10.0.0.4/30":     {"10.0.0.5/30", "10.0.0.6/30"},
+		"2001:db8::2/127": {"2001:db8::2/127", "2001:db8::3/127"},
+		"2001:db8::/64":   {"2001:db8::1/64", "2001:db8::2/64"},
+	}
+
+	for in, want := range tests {
+		a, b, err := PointToPoint(netip.MustParsePrefix(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.String() != want[0] || b.String() != want[1] {
+			t.Errorf("%s: expected %v, got [%s %s]", in, want, a, b)
+		}
+	}
+
+	if _, _, err := PointToPoint(netip.MustParsePrefix("10.0.0.1/32")); err == nil {
+		t.Error("expected error for a /32 subnet")
+	}
+}
@@ -5,7 +5,9 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -59,6 +61,20 @@ func (mr *MockDependencyManagerMockRecorder) AddNode(name interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNode", reflect.TypeOf((*MockDependencyManager)(nil).AddNode), name)
 }
 
+// AddStageDependency mocks base method.
+func (m *MockDependencyManager) AddStageDependency(dependee, dependeeStage, depender, dependerStage string, timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStageDependency", dependee, dependeeStage, depender, dependerStage, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddStageDependency indicates an expected call of AddStageDependency.
+func (mr *MockDependencyManagerMockRecorder) AddStageDependency(dependee, dependeeStage, depender, dependerStage, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStageDependency", reflect.TypeOf((*MockDependencyManager)(nil).AddStageDependency), dependee, dependeeStage, depender, dependerStage, timeout)
+}
+
 // CheckAcyclicity mocks base method.
 func (m *MockDependencyManager) CheckAcyclicity() error {
 	m.ctrl.T.Helper()
@@ -73,16 +89,42 @@ func (mr *MockDependencyManagerMockRecorder) CheckAcyclicity() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAcyclicity", reflect.TypeOf((*MockDependencyManager)(nil).CheckAcyclicity))
 }
 
+// HasDependers mocks base method.
+func (m *MockDependencyManager) HasDependers(nodeName, stage string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDependers", nodeName, stage)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasDependers indicates an expected call of HasDependers.
+func (mr *MockDependencyManagerMockRecorder) HasDependers(nodeName, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDependers", reflect.TypeOf((*MockDependencyManager)(nil).HasDependers), nodeName, stage)
+}
+
 // SignalDone mocks base method.
-func (m *MockDependencyManager) SignalDone(nodeName string) {
+func (m *MockDependencyManager) SignalDone(nodeName, stage string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SignalDone", nodeName)
+	m.ctrl.Call(m, "SignalDone", nodeName, stage)
 }
 
 // SignalDone indicates an expected call of SignalDone.
-func (mr *MockDependencyManagerMockRecorder) SignalDone(nodeName interface{}) *gomock.Call {
+func (mr *MockDependencyManagerMockRecorder) SignalDone(nodeName, stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignalDone", reflect.TypeOf((*MockDependencyManager)(nil).SignalDone), nodeName, stage)
+}
+
+// SignalFailed mocks base method.
+func (m *MockDependencyManager) SignalFailed(nodeName, stage string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SignalFailed", nodeName, stage)
+}
+
+// SignalFailed indicates an expected call of SignalFailed.
+func (mr *MockDependencyManagerMockRecorder) SignalFailed(nodeName, stage interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignalDone", reflect.TypeOf((*MockDependencyManager)(nil).SignalDone), nodeName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignalFailed", reflect.TypeOf((*MockDependencyManager)(nil).SignalFailed), nodeName, stage)
 }
 
 // String mocks base method.
@@ -100,15 +142,15 @@ func (mr *MockDependencyManagerMockRecorder) String() *gomock.Call {
 }
 
 // WaitForNodeDependencies mocks base method.
-func (m *MockDependencyManager) WaitForNodeDependencies(nodeName string) error {
+func (m *MockDependencyManager) WaitForNodeDependencies(ctx context.Context, nodeName, stage string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitForNodeDependencies", nodeName)
+	ret := m.ctrl.Call(m, "WaitForNodeDependencies", ctx, nodeName, stage)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WaitForNodeDependencies indicates an expected call of WaitForNodeDependencies.
-func (mr *MockDependencyManagerMockRecorder) WaitForNodeDependencies(nodeName interface{}) *gomock.Call {
+func (mr *MockDependencyManagerMockRecorder) WaitForNodeDependencies(ctx, nodeName, stage interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForNodeDependencies", reflect.TypeOf((*MockDependencyManager)(nil).WaitForNodeDependencies), nodeName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForNodeDependencies", reflect.TypeOf((*MockDependencyManager)(nil).WaitForNodeDependencies), ctx, nodeName, stage)
 }
@@ -52,6 +52,20 @@ func (mr *MockNodeMockRecorder) CheckDeploymentConditions(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDeploymentConditions", reflect.TypeOf((*MockNode)(nil).CheckDeploymentConditions), arg0)
 }
 
+// CheckHealth mocks base method.
+func (m *MockNode) CheckHealth(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckHealth", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckHealth indicates an expected call of CheckHealth.
+func (mr *MockNodeMockRecorder) CheckHealth(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckHealth", reflect.TypeOf((*MockNode)(nil).CheckHealth), arg0)
+}
+
 // CheckInterfaceName mocks base method.
 func (m *MockNode) CheckInterfaceName() error {
 	m.ctrl.T.Helper()
@@ -270,6 +284,20 @@ func (mr *MockNodeMockRecorder) SaveConfig(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveConfig", reflect.TypeOf((*MockNode)(nil).SaveConfig), arg0)
 }
 
+// SavedConfigPath mocks base method.
+func (m *MockNode) SavedConfigPath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavedConfigPath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SavedConfigPath indicates an expected call of SavedConfigPath.
+func (mr *MockNodeMockRecorder) SavedConfigPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavedConfigPath", reflect.TypeOf((*MockNode)(nil).SavedConfigPath))
+}
+
 // UpdateConfigWithRuntimeInfo mocks base method.
 func (m *MockNode) UpdateConfigWithRuntimeInfo(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -112,6 +112,9 @@ func (b *bridge) UpdateConfigWithRuntimeInfo(_ context.Context) error { return n
 // GetContainers is a noop for bridges.
 func (b *bridge) GetContainers(_ context.Context) ([]types.GenericContainer, error) { return nil, nil }
 
+// ReadinessProbe always succeeds for bridges, which are ready once created.
+func (*bridge) ReadinessProbe(_ context.Context) error { return nil }
+
 func (b *bridge) RunExecs(_ context.Context, _ []string) ([]cExec.ExecResultHolder, error) {
 	log.Warnf("Exec operation is not implemented for kind %q", b.Config().Kind)
 
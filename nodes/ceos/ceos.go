@@ -124,6 +124,10 @@ func (n *ceos) SaveConfig(ctx context.Context) error {
 	return nil
 }
 
+func (n *ceos) SavedConfigPath() string {
+	return filepath.Join(n.Cfg.LabDir, "flash", "startup-config")
+}
+
 func (n *ceos) createCEOSFiles(_ context.Context) error {
 	nodeCfg := n.Config()
 	// generate config directory
@@ -108,6 +108,10 @@ func (s *crpd) SaveConfig(ctx context.Context) error {
 	return nil
 }
 
+func (s *crpd) SavedConfigPath() string {
+	return filepath.Join(s.Cfg.LabDir, "config", "juniper.conf")
+}
+
 func createCRPDFiles(node nodes.Node) error {
 	nodeCfg := node.Config()
 	// create config and logs directory that will be bind mounted to crpd
@@ -62,6 +62,8 @@ func (d *DefaultNode) SaveConfig(_ context.Context) error {
 	return nil
 }
 
+func (*DefaultNode) SavedConfigPath() string { return "" }
+
 func (d *DefaultNode) CheckDeploymentConditions(ctx context.Context) error {
 	err := d.OverwriteNode.VerifyHostRequirements()
 	if err != nil {
@@ -239,6 +241,9 @@ type NodeOverwrites interface {
 	VerifyHostRequirements() error
 	PullImage(ctx context.Context) error
 	GetImages(ctx context.Context) map[string]string
+	// ReadinessProbe returns nil when the node is ready, it is used by CheckHealth
+	// when the node has no healthcheck.
+	ReadinessProbe(ctx context.Context) error
 }
 
 // LoadStartupConfigFileVr templates a startup-config using the file specified for VM-based nodes in the topo
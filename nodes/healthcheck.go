@@ -0,0 +1,188 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/clab/exec"
+)
+
+// CheckHealth returns nil when the node is healthy, which is when the healthcheck of the node succeeds,
+// or the readiness probe of the node kind succeeds when the node has no healthcheck.
+func (d *DefaultNode) CheckHealth(ctx context.Context) error {
+	hc := d.Cfg.Healthcheck
+	if hc == nil {
+		return d.OverwriteNode.ReadinessProbe(ctx)
+	}
+
+	// a single probe doesn't last longer than the interval between the probes
+	ctx, cancel := context.WithTimeout(ctx, hc.GetInterval())
+	defer cancel()
+
+	switch {
+	case hc.Exec != "":
+		return d.probeExec(ctx, hc.Exec)
+	case hc.TCPPort != 0:
+		addr, err := d.mgmtAddress(ctx)
+		if err != nil {
+			return err
+		}
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(addr, strconv.Itoa(hc.TCPPort)))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	default:
+		return d.probeHTTP(ctx, hc.HTTP)
+	}
+}
+
+// ReadinessProbe is the default readiness probe of the nodes, which succeeds when the node container is running.
+// The containers that have a healthcheck defined by their image, e.g. vrnetlab based ones,
+// are ready when the container runtime reports them healthy.
+func (d *DefaultNode) ReadinessProbe(ctx context.Context) error {
+	cnts, err := d.GetContainers(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cnts) == 0 {
+		return fmt.Errorf("container %s not found", d.Cfg.LongName)
+	}
+
+	if cnts[0].State != "running" {
+		return fmt.Errorf("container %s is %s", d.Cfg.LongName, cnts[0].State)
+	}
+	// the status of docker containers ends with the health status, e.g. "Up 2 minutes (healthy)"
+	status := cnts[0].Status
+	if strings.Contains(status, "(health: starting)") || strings.Contains(status, "(unhealthy)") {
+		return fmt.Errorf("container %s is not healthy: %s", d.Cfg.LongName, status)
+	}
+
+	return nil
+}
+
+// probeExec executes the command in the node and checks that it exits with the zero code.
+func (d *DefaultNode) probeExec(ctx context.Context, cmd string) error {
+	execCmd, err := exec.NewExecCmdFromString(cmd)
+	if err != nil {
+		return err
+	}
+	res, err := d.GetRuntime().Exec(ctx, d.Cfg.LongName, execCmd)
+	if err != nil {
+		return err
+	}
+	if rc := res.GetReturnCode(); rc != 0 {
+		return fmt.Errorf("command %q exited with code %d: %s", cmd, rc, strings.TrimSpace(res.GetStdErrString()))
+	}
+	return nil
+}
+
+// probeHTTP sends a GET request to the URL u and checks that the response status code is 2xx or 3xx.
+// The URL host defaults to the management address of the node.
+func (d *DefaultNode) probeHTTP(ctx context.Context, u string) error {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if pu.Hostname() == "" {
+		addr, err := d.mgmtAddress(ctx)
+		if err != nil {
+			return err
+		}
+		host := addr
+		if strings.Contains(addr, ":") {
+			host = "[" + addr + "]"
+		}
+		if port := pu.Port(); port != "" {
+			host = net.JoinHostPort(addr, port)
+		}
+		pu.Host = host
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pu.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("GET %s responded with status %s", pu, resp.Status)
+	}
+	return nil
+}
+
+// mgmtAddress returns the management address of the node, which is retrieved from the container runtime
+// when the address is not set in the node configuration.
+func (d *DefaultNode) mgmtAddress(ctx context.Context) (string, error) {
+	if d.Cfg.NetworkMode == "host" {
+		return "127.0.0.1", nil
+	}
+	if d.Cfg.MgmtIPv4Address != "" {
+		return d.Cfg.MgmtIPv4Address, nil
+	}
+	if d.Cfg.MgmtIPv6Address != "" {
+		return d.Cfg.MgmtIPv6Address, nil
+	}
+
+	cnts, err := d.GetContainers(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(cnts) == 0 {
+		return "", fmt.Errorf("container %s not found", d.Cfg.LongName)
+	}
+	if addr := cnts[0].NetworkSettings.IPv4addr; addr != "" {
+		return addr, nil
+	}
+	if addr := cnts[0].NetworkSettings.IPv6addr; addr != "" {
+		return addr, nil
+	}
+	return "", fmt.Errorf("node %s has no management address", d.Cfg.ShortName)
+}
+
+// WaitHealthy blocks until the node is healthy. An error is returned when ctx is done,
+// or when the healthcheck of the node fails the number of its retries in a row after its start period.
+// The failures of the readiness probes of the node kinds are not counted,
+// since the nodes might take long to boot.
+func WaitHealthy(ctx context.Context, n Node) error {
+	cfg := n.Config()
+	hc := cfg.Healthcheck
+	started := time.Now()
+	failures := 0
+
+	for {
+		err := n.CheckHealth(ctx)
+		if err == nil {
+			return nil
+		}
+		log.Debugf("node %s is not healthy yet: %v", cfg.ShortName, err)
+
+		if hc != nil && time.Since(started) >= hc.GetStartPeriod() {
+			failures++
+			if failures >= hc.GetRetries() {
+				return fmt.Errorf("node %s is unhealthy after %d failed healthchecks: %v", cfg.ShortName, failures, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("node %s is not healthy: %v", cfg.ShortName, err)
+		case <-time.After(hc.GetInterval()):
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+// healthNode is a node which becomes healthy once its health is checked healthyAfter times,
+// it never becomes healthy when healthyAfter is zero.
+type healthNode struct {
+	Node
+	cfg          *types.NodeConfig
+	healthyAfter int
+	checks       int
+}
+
+func (n *healthNode) Config() *types.NodeConfig { return n.cfg }
+
+func (n *healthNode) CheckHealth(context.Context) error {
+	n.checks++
+	if n.healthyAfter > 0 && n.checks >= n.healthyAfter {
+		return nil
+	}
+	return errors.New("not ready")
+}
+
+func TestWaitHealthy(t *testing.T) {
+	tests := map[string]struct {
+		healthcheck  *types.HealthcheckConfig
+		healthyAfter int
+		timeout      time.Duration
+		wantChecks   int
+		wantErr      bool
+	}{
+		"healthy": {
+			healthcheck:  &types.HealthcheckConfig{Exec: "true", Interval: "10ms"},
+			healthyAfter: 3,
+			timeout:      time.Second,
+			wantChecks:   3,
+		},
+		"unhealthy_after_retries": {
+			healthcheck: &types.HealthcheckConfig{Exec: "true", Interval: "10ms", Retries: 2},
+			timeout:     time.Second,
+			wantChecks:  2,
+			wantErr:     true,
+		},
+		"failures_not_counted_during_start_period": {
+			healthcheck:  &types.HealthcheckConfig{Exec: "true", Interval: "10ms", Retries: 1, StartPeriod: "1s"},
+			healthyAfter: 3,
+			timeout:      time.Second,
+			wantChecks:   3,
+		},
+		"readiness_probe_until_ctx_done": {
+			timeout:    50 * time.Millisecond,
+			wantChecks: 1,
+			wantErr:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			n := &healthNode{
+				cfg:          &types.NodeConfig{ShortName: "node1", Healthcheck: tt.healthcheck},
+				healthyAfter: tt.healthyAfter,
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			err := WaitHealthy(ctx, n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitHealthy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n.checks != tt.wantChecks {
+				t.Errorf("WaitHealthy() checked the health %d times, want %d", n.checks, tt.wantChecks)
+			}
+		})
+	}
+}
@@ -46,6 +46,9 @@ func (*host) WithMgmtNet(*types.MgmtNet)                    {}
 // UpdateConfigWithRuntimeInfo is a noop for hosts.
 func (*host) UpdateConfigWithRuntimeInfo(_ context.Context) error { return nil }
 
+// ReadinessProbe always succeeds for hosts.
+func (*host) ReadinessProbe(_ context.Context) error { return nil }
+
 // GetContainers returns a basic skeleton of a container to enable graphing of hosts kinds.
 func (*host) GetContainers(_ context.Context) ([]types.GenericContainer, error) {
 	return []types.GenericContainer{
@@ -6,6 +6,7 @@ package keysight_ixiacone
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,7 +17,11 @@ import (
 	"github.com/srl-labs/containerlab/types"
 )
 
-var kindnames = []string{"keysight_ixia-c-one"}
+var (
+	kindnames = []string{"keysight_ixia-c-one"}
+
+	errIxiacNotReady = errors.New("keysight_ixia-c-one node is not ready")
+)
 
 var ixiacStatusConfig = struct {
 	statusSleepDuration time.Duration
@@ -56,23 +61,34 @@ func (l *ixiacOne) PostDeploy(ctx context.Context, _ map[string]nodes.Node) erro
 
 // ixiacPostDeploy runs postdeploy actions which are required for keysight_ixia-c-one node.
 func (l *ixiacOne) ixiacPostDeploy(ctx context.Context) error {
-	ixiacOneCmd := fmt.Sprintf("bash -c 'ls %s'", ixiacStatusConfig.readyFileName)
-	statusInProgressMsg := fmt.Sprintf("ls: %s: No such file or directory", ixiacStatusConfig.readyFileName)
 	for {
-		cmd, _ := exec.NewExecCmdFromString(ixiacOneCmd)
-		execResult, err := l.RunExec(ctx, cmd)
-		if err != nil {
+		err := l.ReadinessProbe(ctx)
+		if err == nil {
+			return nil
+		}
+		if err != errIxiacNotReady {
 			return err
 		}
-		if len(execResult.GetStdErrString()) > 0 {
-			msg := strings.TrimSuffix(execResult.GetStdErrString(), "\n")
-			if msg != statusInProgressMsg {
-				return err
-			}
-			time.Sleep(ixiacStatusConfig.statusSleepDuration)
-		} else {
-			break
+		time.Sleep(ixiacStatusConfig.statusSleepDuration)
+	}
+}
+
+// ReadinessProbe checks that the ready file of keysight_ixia-c-one node is created.
+func (l *ixiacOne) ReadinessProbe(ctx context.Context) error {
+	ixiacOneCmd := fmt.Sprintf("bash -c 'ls %s'", ixiacStatusConfig.readyFileName)
+	statusInProgressMsg := fmt.Sprintf("ls: %s: No such file or directory", ixiacStatusConfig.readyFileName)
+
+	cmd, _ := exec.NewExecCmdFromString(ixiacOneCmd)
+	execResult, err := l.GetRuntime().Exec(ctx, l.Cfg.LongName, cmd)
+	if err != nil {
+		return err
+	}
+	if len(execResult.GetStdErrString()) > 0 {
+		msg := strings.TrimSuffix(execResult.GetStdErrString(), "\n")
+		if msg != statusInProgressMsg {
+			return fmt.Errorf("failed to check the ready file: %s", msg)
 		}
+		return errIxiacNotReady
 	}
 
 	return nil
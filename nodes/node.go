@@ -80,12 +80,18 @@ type Node interface {
 	GetImages(context.Context) map[string]string // GetImages returns the images used for this kind
 	GetRuntime() runtime.ContainerRuntime        // GetRuntime returns the nodes assigned runtime
 	GenerateConfig(dst, templ string) error      // Generate the nodes configuration
+	// SavedConfigPath returns the path of the file in the node's lab directory the configuration is saved to
+	// by SaveConfig, or an empty string if the configuration is not saved to the lab directory
+	SavedConfigPath() string
 	// UpdateConfigWithRuntimeInfo updates node config with runtime info like IP addresses assgined by runtime
 	UpdateConfigWithRuntimeInfo(context.Context) error
 	// RunExecs executes all exec commands specified for the node.
 	RunExecs(ctx context.Context, cmds []string) ([]exec.ExecResultHolder, error)
 	// RunExec execute a single command for a given node.
 	RunExec(ctx context.Context, execCmd *exec.ExecCmd) (exec.ExecResultHolder, error)
+	// CheckHealth returns nil when the node is healthy according to its healthcheck,
+	// or to the readiness probe of its kind when the healthcheck is not set.
+	CheckHealth(ctx context.Context) error
 }
 
 type Initializer func() Node
@@ -56,6 +56,9 @@ func (*ovs) PullImage(_ context.Context) error             { return nil }
 func (*ovs) GetImages(_ context.Context) map[string]string { return map[string]string{} }
 func (*ovs) Delete(_ context.Context) error                { return nil }
 
+// ReadinessProbe always succeeds for ovs bridges, which are ready once created.
+func (*ovs) ReadinessProbe(_ context.Context) error { return nil }
+
 func (o *ovs) RunExecs(_ context.Context, _ []string) ([]exec.ExecResultHolder, error) {
 	log.Warnf("Exec operation is not implemented for kind %q", o.Config().Kind)
 
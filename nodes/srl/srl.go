@@ -183,37 +183,10 @@ func (s *srl) Init(cfg *types.NodeConfig, opts ...nodes.NodeOption) error {
 
 func (s *srl) PreDeploy(_ context.Context, configName, labCADir, labCARoot string) error {
 	utils.CreateDirectory(s.Cfg.LabDir, 0777)
-	// retrieve node certificates
-	nodeCerts, err := cert.RetrieveNodeCertData(s.Cfg, labCADir)
-	// if not available on disk, create cert in next step
+	// retrieve node certificates, which are generated when not available on disk
+	nodeCerts, err := cert.NodeCertificate(s.Cfg, configName, labCADir, labCARoot)
 	if err != nil {
-		// create CERT
-		certTpl, err := template.New("node-cert").Parse(cert.NodeCSRTempl)
-		if err != nil {
-			log.Errorf("failed to parse Node CSR Template: %v", err)
-		}
-		log.Debugf("creating node certificate for %s", s.Cfg.SANs)
-
-		certInput := cert.CertInput{
-			Name:     s.Cfg.ShortName,
-			LongName: s.Cfg.LongName,
-			Fqdn:     s.Cfg.Fqdn,
-			SANs:     s.Cfg.SANs,
-			Prefix:   configName,
-		}
-		nodeCerts, err = cert.GenerateCert(
-			path.Join(labCARoot, "root-ca.pem"),
-			path.Join(labCARoot, "root-ca-key.pem"),
-			certTpl,
-			certInput,
-			path.Join(labCADir, certInput.Name),
-		)
-		if err != nil {
-			log.Errorf("failed to generate certificates for node %s: %v", s.Cfg.ShortName, err)
-		}
-		log.Debugf("%s CSR: %s", s.Cfg.ShortName, string(nodeCerts.Csr))
-		log.Debugf("%s Cert: %s", s.Cfg.ShortName, string(nodeCerts.Cert))
-		log.Debugf("%s Key: %s", s.Cfg.ShortName, string(nodeCerts.Key))
+		return err
 	}
 	s.Cfg.TLSCert = string(nodeCerts.Cert)
 	s.Cfg.TLSKey = string(nodeCerts.Key)
@@ -290,6 +263,10 @@ func (s *srl) SaveConfig(ctx context.Context) error {
 	return nil
 }
 
+func (s *srl) SavedConfigPath() string {
+	return filepath.Join(s.Cfg.LabDir, "config", "config.json")
+}
+
 // Ready returns when the node boot sequence reached the stage when it is ready to accept config commands
 // returns an error if not ready by the expiry of the timer readyTimeout.
 func (s *srl) Ready(ctx context.Context) error {
@@ -303,52 +280,55 @@ func (s *srl) Ready(ctx context.Context) error {
 		case <-ctx.Done():
 			return fmt.Errorf("timed out waiting for SR Linux node %s to boot: %v", s.Cfg.ShortName, err)
 		default:
-			// two commands are checked, first if the mgmt_server is running
-			cmd, _ := exec.NewExecCmdFromString(mgmtServerRdyCmd)
-			execResult, err := s.RunExec(ctx, cmd)
-			if err != nil {
+			if err = s.ReadinessProbe(ctx); err != nil {
+				log.Debugf("SR Linux node %s is not ready yet: %v", s.Cfg.ShortName, err)
 				time.Sleep(retryTimer)
 				continue
 			}
 
-			if len(execResult.GetStdErrString()) != 0 {
-				log.Debugf("error during checking SR Linux boot status: %s", execResult.GetStdErrString())
-				time.Sleep(retryTimer)
-				continue
-			}
+			log.Debugf("Node %s is ready to accept configs", s.Cfg.ShortName)
 
-			if !strings.Contains(execResult.GetStdOutString(), "running") {
-				time.Sleep(retryTimer)
-				continue
-			}
+			return nil
+		}
+	}
+}
 
-			// once mgmt server is running, we need to check if it is ready to accept configuration commands
-			// this is done with checking readyForConfigCmd
-			cmd, _ = exec.NewExecCmdFromString(readyForConfigCmd)
-			execResult, err = s.RunExec(ctx, cmd)
-			if err != nil {
-				log.Debugf("error during readyForConfigCmd execution: %s", err)
-				time.Sleep(retryTimer)
-				continue
-			}
+// ReadinessProbe checks that the management server of the node is running
+// and is ready to accept configuration commands.
+func (s *srl) ReadinessProbe(ctx context.Context) error {
+	// two commands are checked, first if the mgmt_server is running
+	cmd, _ := exec.NewExecCmdFromString(mgmtServerRdyCmd)
+	execResult, err := s.GetRuntime().Exec(ctx, s.Cfg.LongName, cmd)
+	if err != nil {
+		return err
+	}
 
-			if len(execResult.GetStdErrString()) != 0 {
-				log.Debugf("readyForConfigCmd stderr: %s", string(execResult.GetStdErrString()))
-				time.Sleep(retryTimer)
-				continue
-			}
+	if len(execResult.GetStdErrString()) != 0 {
+		return fmt.Errorf("error during checking SR Linux boot status: %s", execResult.GetStdErrString())
+	}
 
-			if !strings.Contains(execResult.GetStdOutString(), "loaded initial configuration") {
-				log.Debugf("Management server readiness files doesn't contain the marker string %s", execResult.GetStdOutString())
-				time.Sleep(retryTimer)
-				continue
-			}
+	if !strings.Contains(execResult.GetStdOutString(), "running") {
+		return fmt.Errorf("management server is not running")
+	}
 
-			log.Debugf("Node %s is ready to accept configs", s.Cfg.ShortName)
+	// once mgmt server is running, we need to check if it is ready to accept configuration commands
+	// this is done with checking readyForConfigCmd
+	cmd, _ = exec.NewExecCmdFromString(readyForConfigCmd)
+	execResult, err = s.GetRuntime().Exec(ctx, s.Cfg.LongName, cmd)
+	if err != nil {
+		return fmt.Errorf("error during readyForConfigCmd execution: %v", err)
+	}
 
-			return nil
-		}
+	if len(execResult.GetStdErrString()) != 0 {
+		return fmt.Errorf("readyForConfigCmd stderr: %s", execResult.GetStdErrString())
+	}
+
+	if !strings.Contains(execResult.GetStdOutString(), "loaded initial configuration") {
+		return fmt.Errorf("management server readiness file doesn't contain the marker string: %s",
+			execResult.GetStdOutString())
 	}
+
+	return nil
 }
 
 func (s *srl) createSRLFiles() error {
@@ -0,0 +1,136 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package rootless runs containerlab without root privileges with a rootless docker or podman runtime.
+// Containerlab is re-executed in the user and network namespaces of the runtime, where it is privileged
+// to wire the links between the containers, whose network namespaces are owned by the same user namespace.
+package rootless
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EnvNamespace is set to the runtime name in the environment of containerlab
+	// re-executed in the namespaces of the rootless runtime.
+	EnvNamespace = "CLAB_ROOTLESS_NS"
+	// EnvNetnsDir is the directory with the links to the network namespaces of the containers,
+	// which is /run/netns/ by default and is not writable without root privileges.
+	EnvNetnsDir = "CLAB_NETNS_DIR"
+
+	dockerRuntime = "docker"
+	podmanRuntime = "podman"
+)
+
+// InNamespace returns true when containerlab runs in the namespaces of the rootless runtime.
+func InNamespace() bool {
+	return os.Getenv(EnvNamespace) != ""
+}
+
+// ExitCode is the exit code of containerlab re-executed in the namespaces of the rootless runtime.
+// It is returned by Enter as an error, since the command ran in the namespaces is complete
+// and the caller is expected to exit with the code.
+type ExitCode int
+
+func (e ExitCode) Error() string {
+	return fmt.Sprintf("containerlab exited with code %d in namespaces of rootless runtime", int(e))
+}
+
+// Enter re-executes containerlab with its arguments in the namespaces of the rootless runtime,
+// and returns the ExitCode of the re-executed containerlab.
+// It returns nil right away when containerlab already runs in the namespaces.
+func Enter(runtime string) error {
+	if InNamespace() {
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd, err := Command(runtime, os.Getenv("XDG_RUNTIME_DIR"), exe, os.Args[1:])
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	log.Debugf("entering namespaces of rootless %s runtime: %s", runtime, cmd.String())
+
+	return run(cmd, runtime)
+}
+
+// run runs the command cmd re-executing containerlab and returns its ExitCode.
+func run(cmd *exec.Cmd, runtime string) error {
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to enter namespaces of rootless %s runtime: %v", runtime, err)
+	}
+
+	return ExitCode(cmd.ProcessState.ExitCode())
+}
+
+// Command returns the command running exe with args in the user and network namespaces of the rootless runtime,
+// whose sockets and state are found in the runtimeDir, which is $XDG_RUNTIME_DIR of the user.
+func Command(runtime, runtimeDir, exe string, args []string) (*exec.Cmd, error) {
+	if runtimeDir == "" {
+		return nil, fmt.Errorf("XDG_RUNTIME_DIR is not set, it is required to find the rootless %s runtime", runtime)
+	}
+
+	var cmd *exec.Cmd
+	env := os.Environ()
+
+	switch runtime {
+	case dockerRuntime:
+		// the namespaces of the rootless docker are the ones of the dockerd process,
+		// the mount namespace is not entered, since /etc of the rootless docker is a copy of the host one
+		pidFile := filepath.Join(runtimeDir, "docker.pid")
+		b, err := os.ReadFile(pidFile)
+		if err != nil {
+			return nil, fmt.Errorf("rootless docker is not running: %v", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid in %s: %v", pidFile, err)
+		}
+
+		cmd = exec.Command("nsenter", append([]string{
+			"-U", "--preserve-credentials", "-n", "-t", strconv.Itoa(pid), "--", exe,
+		}, args...)...)
+		env = setDefaultEnv(env, "DOCKER_HOST", "unix://"+filepath.Join(runtimeDir, "docker.sock"))
+	case podmanRuntime:
+		// the rootless network namespace holds the bridges of the podman networks
+		cmd = exec.Command("podman", append([]string{"unshare", "--rootless-netns", exe}, args...)...)
+		env = setDefaultEnv(env, "CONTAINER_HOST", "unix://"+filepath.Join(runtimeDir, "podman", "podman.sock"))
+	default:
+		return nil, fmt.Errorf("rootless mode is supported with %s and %s runtimes, got %q",
+			dockerRuntime, podmanRuntime, runtime)
+	}
+
+	env = setDefaultEnv(env, EnvNetnsDir, filepath.Join(runtimeDir, "containerlab", "netns"))
+	cmd.Env = append(env, EnvNamespace+"="+runtime)
+
+	return cmd, nil
+}
+
+// setDefaultEnv sets the environment variable unless it is already set.
+func setDefaultEnv(env []string, key, value string) []string {
+	for _, e := range env {
+		if strings.HasPrefix(e, key+"=") {
+			return env
+		}
+	}
+	return append(env, key+"="+value)
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package rootless
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// envValue returns the value of the environment variable in env.
+func envValue(env []string, key string) string {
+	v := ""
+	for _, e := range env {
+		if strings.HasPrefix(e, key+"=") {
+			v = strings.TrimPrefix(e, key+"=")
+		}
+	}
+	return v
+}
+
+func TestCommandDocker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker.pid"), []byte("4242\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_HOST", "")
+	os.Unsetenv("DOCKER_HOST")
+
+	cmd, err := Command("docker", dir, "/usr/bin/containerlab", []string{"deploy", "--rootless"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"nsenter", "-U", "--preserve-credentials", "-n", "-t", "4242", "--",
+		"/usr/bin/containerlab", "deploy", "--rootless",
+	}
+	if d := cmp.Diff(want, cmd.Args); d != "" {
+		t.Errorf("unexpected command (-want +got):\n%s", d)
+	}
+
+	for key, want := range map[string]string{
+		"DOCKER_HOST": "unix://" + filepath.Join(dir, "docker.sock"),
+		EnvNetnsDir:   filepath.Join(dir, "containerlab", "netns"),
+		EnvNamespace:  "docker",
+	} {
+		if got := envValue(cmd.Env, key); got != want {
+			t.Errorf("expected %s=%s, got %q", key, want, got)
+		}
+	}
+}
+
+func TestCommandPodman(t *testing.T) {
+	dir := t.TempDir()
+	// the socket set by the user is kept
+	t.Setenv("CONTAINER_HOST", "unix:///tmp/podman.sock")
+
+	cmd, err := Command("podman", dir, "/usr/bin/containerlab", []string{"destroy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"podman", "unshare", "--rootless-netns", "/usr/bin/containerlab", "destroy"}
+	if d := cmp.Diff(want, cmd.Args); d != "" {
+		t.Errorf("unexpected command (-want +got):\n%s", d)
+	}
+	if got := envValue(cmd.Env, "CONTAINER_HOST"); got != "unix:///tmp/podman.sock" {
+		t.Errorf("expected CONTAINER_HOST set by the user, got %q", got)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	tests := map[string]struct {
+		runtime    string
+		runtimeDir string
+	}{
+		"no runtime dir":      {runtime: "podman"},
+		"docker not running":  {runtime: "docker", runtimeDir: t.TempDir()},
+		"unsupported runtime": {runtime: "containerd", runtimeDir: t.TempDir()},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Command(tc.runtime, tc.runtimeDir, "containerlab", nil); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	tests := map[string]struct {
+		cmd      *exec.Cmd
+		wantCode ExitCode
+		wantErr  bool
+	}{
+		"success": {
+			cmd:      exec.Command("sh", "-c", "exit 0"),
+			wantCode: 0,
+		},
+		"failure": {
+			cmd:      exec.Command("sh", "-c", "exit 3"),
+			wantCode: 3,
+		},
+		"not_started": {
+			cmd:     exec.Command(filepath.Join(t.TempDir(), "containerlab")),
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := run(tt.cmd, "docker")
+
+			var code ExitCode
+			if !errors.As(err, &code) {
+				if !tt.wantErr {
+					t.Fatalf("expected exit code %d, got error %v", tt.wantCode, err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatalf("expected error, got exit code %d", code)
+			}
+			if code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d", tt.wantCode, code)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/srl-labs/containerlab/clab/exec"
+	"github.com/srl-labs/containerlab/firewall"
 	"github.com/srl-labs/containerlab/runtime"
 	"github.com/srl-labs/containerlab/types"
 	"github.com/srl-labs/containerlab/utils"
@@ -75,6 +76,8 @@ type ContainerdRuntime struct {
 	config runtime.RuntimeConfig
 	client *containerd.Client
 	mgmt   *types.MgmtNet
+	// userBridge is true when the management bridge is set by a user, it is not deleted with the lab then
+	userBridge bool
 }
 
 func (c *ContainerdRuntime) WithConfig(cfg *runtime.RuntimeConfig) {
@@ -87,6 +90,7 @@ func (c *ContainerdRuntime) WithConfig(cfg *runtime.RuntimeConfig) {
 }
 
 func (c *ContainerdRuntime) WithMgmtNet(n *types.MgmtNet) {
+	c.userBridge = n.Bridge != ""
 	if n.Bridge == "" {
 		netname := "clab"
 		if n.Network != "" {
@@ -103,11 +107,7 @@ func (c *ContainerdRuntime) WithKeepMgmtNet() {
 func (*ContainerdRuntime) GetName() string                 { return runtimeName }
 func (c *ContainerdRuntime) Config() runtime.RuntimeConfig { return c.config }
 
-func (*ContainerdRuntime) CreateNet(_ context.Context) error {
-	log.Debug("CreateNet() - Not needed with containerd")
-	return nil
-}
-
+// DeleteNet deletes the management bridge, unless it is set by a user or is still in use.
 func (c *ContainerdRuntime) DeleteNet(context.Context) error {
 	var err error
 	bridgename := c.mgmt.Bridge
@@ -126,11 +126,19 @@ func (c *ContainerdRuntime) DeleteNet(context.Context) error {
 			break
 		}
 	}
-	if c.config.KeepMgmtNet || brInUse {
+	if c.config.KeepMgmtNet || c.userBridge || brInUse {
 		log.Infof("Skipping deletion of bridge '%s'", bridgename)
 		return nil
 	}
-	return utils.DeleteLinkByName(bridgename)
+	if err := utils.DeleteLinkByName(bridgename); err != nil {
+		return err
+	}
+	if c.mgmt.ExternalAccess != nil && *c.mgmt.ExternalAccess {
+		if err := firewall.DeleteBridgeRule(bridgename); err != nil {
+			log.Warnf("errors during forwarding rules removal: %v", err)
+		}
+	}
+	return nil
 }
 
 func (c *ContainerdRuntime) PullImageIfRequired(ctx context.Context, imagename string) error {
@@ -189,7 +197,7 @@ func (c *ContainerdRuntime) StartContainer(ctx context.Context, _ string, node *
 			Destination: s[1],
 			Options:     []string{"rbind", "rprivate"},
 		}
-		if len(mount) == 3 {
+		if len(s) == 3 {
 			m.Options = append(m.Options, strings.Split(s[2], ",")...)
 		}
 		mounts[idx] = m
@@ -227,22 +235,38 @@ func (c *ContainerdRuntime) StartContainer(ctx context.Context, _ string, node *
 	if node.CPUSet != "" {
 		opts = append(opts, oci.WithCPUs(node.CPUSet))
 	}
-	if len(mounts) > 0 {
-		opts = append(opts, oci.WithMounts(mounts))
+
+	// the /etc/hosts file with the extra hosts is mounted into the container like docker does
+	hostsMnt, err := hostsMount(node)
+	if err != nil {
+		return nil, err
 	}
+	mounts = append(mounts, hostsMnt)
+	opts = append(opts, oci.WithMounts(mounts))
 
 	var cnic *libcni.CNIConfig
 	var cncl *libcni.NetworkConfigList
 	var cnirc *libcni.RuntimeConf
 
-	switch node.NetworkMode {
+	netMode := strings.SplitN(node.NetworkMode, ":", 2)
+	switch netMode[0] {
 	case "host":
 		opts = append(opts,
 			oci.WithHostNamespace(specs.NetworkNamespace),
-			oci.WithHostHostsFile,
 			oci.WithHostResolvconf)
 	case "none":
 		// Done!
+	// the node joins the network namespace of another container,
+	// which is either a node of the lab or a container created outside of containerlab
+	case "container":
+		nsPath, err := c.referencedNSPath(ctx, node, netMode)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, oci.WithLinuxNamespace(specs.LinuxNamespace{
+			Type: specs.NetworkNamespace,
+			Path: nsPath,
+		}))
 	default:
 		cnic, cncl, cnirc, err = cniInit(node.LongName, "eth0", c.mgmt)
 		if err != nil {
@@ -254,6 +278,11 @@ func (c *ContainerdRuntime) StartContainer(ctx context.Context, _ string, node *
 			cnirc.CapabilityArgs["mac"] = node.MacAddress
 		}
 
+		// request static mgmt addresses from host-local IPAM
+		if ips := staticMgmtIPs(node); len(ips) > 0 {
+			cnirc.CapabilityArgs["ips"] = ips
+		}
+
 		portmappings := []portMapping{}
 
 		for contdatasl, hostdata := range node.PortBindings {
@@ -295,11 +324,7 @@ func (c *ContainerdRuntime) StartContainer(ctx context.Context, _ string, node *
 	log.Debugf("Container '%s' created", node.LongName)
 	log.Debugf("Start container: %s", node.LongName)
 
-	container, err := c.client.LoadContainer(ctx, node.LongName)
-	if err != nil {
-		return nil, err
-	}
-	task, err := container.NewTask(ctx, cio.LogFile("/tmp/clab/"+node.LongName+".log"))
+	task, err := newContainer.NewTask(ctx, cio.LogFile("/tmp/clab/"+node.LongName+".log"))
 	if err != nil {
 		return nil, err
 	}
@@ -328,37 +353,44 @@ func (c *ContainerdRuntime) StartContainer(ctx context.Context, _ string, node *
 		if err != nil {
 			return nil, err
 		}
-		result, _ := current.NewResultFromResult(res)
-
-		ipv4, ipv6, ipv4Gw := "", "", ""
-		ipv4nm, ipv6nm := 0, 0
-		for _, ip := range result.IPs {
-			switch ip.Version {
-			case "4":
-				ipv4 = ip.Address.IP.String()
-				ipv4nm, _ = ip.Address.Mask.Size()
-				ipv4Gw = ip.Gateway.String()
-			case "6":
-				ipv6 = ip.Address.IP.String()
-				ipv6nm, _ = ip.Address.Mask.Size()
-			}
-		}
-
-		additionalLabels := map[string]string{
-			"clab.ipv4.addr":    ipv4,
-			"clab.ipv4.netmask": strconv.Itoa(ipv4nm),
-			"clab.ipv6.addr":    ipv6,
-			"clab.ipv6.netmask": strconv.Itoa(ipv6nm),
-			"clab.ipv4.gateway": ipv4Gw,
-		}
-		_, err = newContainer.SetLabels(ctx, additionalLabels)
+		result, err := current.NewResultFromResult(res)
 		if err != nil {
 			return nil, err
 		}
+
+		// the assigned addresses are read from the CNI cache when the container is listed
+		ips := mgmtIPsFromResult(result)
+		if err := appendHostsEntries(node.LongName, node.ShortName, ips.IPv4addr, ips.IPv6addr); err != nil {
+			return nil, fmt.Errorf("failed to add mgmt addresses to /etc/hosts file of node %q: %v", node.ShortName, err)
+		}
 	}
 	return nil, nil
 }
 
+// referencedNSPath returns the network namespace path of the container referenced in the container network mode of the node.
+func (c *ContainerdRuntime) referencedNSPath(ctx context.Context, node *types.NodeConfig, netMode []string) (string, error) {
+	// We expect exactly two arguments in this case ("container" keyword & cont. name/ID)
+	if len(netMode) != 2 || netMode[1] == "" {
+		return "", fmt.Errorf("container network mode was specified for container %q, but we failed to parse the network-mode instruction: %q",
+			node.ShortName, netMode)
+	}
+
+	// a node of the lab is referenced by its short name,
+	// extract lab/topo prefix to craft a full container name
+	contName := strings.SplitN(node.LongName, node.ShortName, 2)[0] + netMode[1]
+
+	nsPath, err := c.GetNSPath(ctx, contName)
+	if err != nil {
+		log.Debugf("container %q was not found by its name, assuming it is exists externally with unprefixed", contName)
+
+		if nsPath, err = c.GetNSPath(ctx, netMode[1]); err != nil {
+			return "", fmt.Errorf("container %q is referenced in network-mode, but was not found", netMode[1])
+		}
+	}
+
+	return nsPath, nil
+}
+
 func (c *ContainerdRuntime) PauseContainer(ctx context.Context, cID string) error {
 	ctask, err := c.getContainerTask(ctx, cID)
 	if err != nil {
@@ -379,71 +411,6 @@ func (c *ContainerdRuntime) UnpauseContainer(ctx context.Context, cID string) er
 	return err
 }
 
-func cniInit(cId, ifName string, mgmtNet *types.MgmtNet) (*libcni.CNIConfig, *libcni.NetworkConfigList, *libcni.RuntimeConf, error) {
-	// allow overwriting cni plugin binary path via ENV var
-
-	cnic := libcni.NewCNIConfigWithCacheDir([]string{utils.GetCNIBinaryPath()}, cniCache, nil)
-
-	cniConfig := fmt.Sprintf(`
-	{
-		"cniVersion": "0.4.0",
-		"name": "clabmgmt",
-		"plugins": [
-		  {
-			"type": "bridge",
-			"bridge": "%s",
-			"isDefaultGateway": true,
-			"forceAddress": false,
-			"ipMasq": true,
-			"hairpinMode": true,
-			"ipam": {
-			  "type": "host-local",
-			  "ranges": [
-				[
-				  {
-					"subnet": "%s"
-				  }
-				],
-				[
-				  {
-					"subnet": "%s"
-				  }
-				]
-			  ]
-			}
-		  },
-		  {
-			"type": "tuning",
-			"mtu": %s,
-			"capabilities": {
-			  "mac": true
-			}
-		  },
-		  {
-			"type": "portmap",
-			"capabilities": {
-			  "portMappings": true
-			}
-		  }
-		]
-	  }
-	`, mgmtNet.Bridge, mgmtNet.IPv4Subnet, mgmtNet.IPv6Subnet, mgmtNet.MTU)
-
-	cncl, err := libcni.ConfListFromBytes([]byte(cniConfig))
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	cnirc := &libcni.RuntimeConf{
-		ContainerID: cId,
-		IfName:      ifName,
-		// // NetNS must be set later, can just be determined after container start
-		// NetNS:          node.NSPath,
-		CapabilityArgs: make(map[string]interface{}),
-	}
-	return cnic, cncl, cnirc, nil
-}
-
 type portMapping struct {
 	HostPort      int    `json:"hostPort"`
 	HostIP        string `json:"hostIP,omitempty"`
@@ -550,21 +517,16 @@ func (c *ContainerdRuntime) ListContainers(ctx context.Context, filter []*types.
 	return c.produceGenericContainerList(ctx, containerlist)
 }
 
-// GetContainer TODO this will probably not work. need to work out the exact filter format.
+// GetContainer returns the container referenced by its name.
 func (c *ContainerdRuntime) GetContainer(ctx context.Context, containerID string) (*types.GenericContainer, error) {
-	var ctr *types.GenericContainer
-	gFilter := types.GenericFilter{
-		FilterType: "name",
-		Field:      "",
-		Operator:   "",
-		Match:      containerID,
-	}
-	ctrs, err := c.ListContainers(ctx, []*types.GenericFilter{&gFilter})
+	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
+	cont, err := c.client.LoadContainer(ctx, containerID)
 	if err != nil {
-		return ctr, err
+		return nil, err
 	}
-	if len(ctrs) != 1 {
-		return ctr, fmt.Errorf("found unexpected number of containers: %d", len(ctrs))
+	ctrs, err := c.produceGenericContainerList(ctx, []containerd.Container{cont})
+	if err != nil {
+		return nil, err
 	}
 	return &ctrs[0], nil
 }
@@ -601,7 +563,7 @@ func (*ContainerdRuntime) buildFilterString(filter []*types.GenericFilter) strin
 }
 
 // Transform docker-specific to generic container format.
-func (*ContainerdRuntime) produceGenericContainerList(ctx context.Context,
+func (c *ContainerdRuntime) produceGenericContainerList(ctx context.Context,
 	input []containerd.Container,
 ) ([]types.GenericContainer, error) {
 	var result []types.GenericContainer
@@ -621,10 +583,17 @@ func (*ContainerdRuntime) produceGenericContainerList(ctx context.Context,
 		ctr.Image = info.Image
 		ctr.Labels = info.Labels
 
-		ctr.NetworkSettings, err = extractIPInfoFromLabels(ctr.Labels)
+		ctr.NetworkSettings, err = c.cniMgmtIPs(ctr.ID)
 		if err != nil {
 			return nil, err
 		}
+		// the containers deployed by the previous versions keep the mgmt addresses in the labels
+		if ctr.NetworkSettings.IPv4addr == "" && ctr.NetworkSettings.IPv6addr == "" {
+			ctr.NetworkSettings, err = extractIPInfoFromLabels(ctr.Labels)
+			if err != nil {
+				return nil, err
+			}
+		}
 
 		taskfound := true
 		task, err := i.Task(ctx, nil)
@@ -647,8 +616,14 @@ func (*ContainerdRuntime) produceGenericContainerList(ctx context.Context,
 			switch status.Status {
 			case containerd.Stopped:
 				ctr.Status = fmt.Sprintf("Exited (%v) %s", status.ExitStatus, timeSinceInHuman(status.ExitTime))
-			case containerd.Running:
+			case containerd.Running, containerd.Paused:
 				ctr.Status = "Up"
+				if started, err := processStartTime(int(task.Pid())); err == nil {
+					ctr.Status = "Up " + units.HumanDuration(time.Since(started))
+				}
+				if status.Status == containerd.Paused {
+					ctr.Status += " (Paused)"
+				}
 			default:
 				ctr.Status = cases.Title(language.English).String(ctr.State)
 			}
@@ -691,6 +666,44 @@ func timeSinceInHuman(since time.Time) string {
 	return units.HumanDuration(time.Since(since)) + " ago"
 }
 
+// clockTicks is the USER_HZ the start times of the processes are reported in by procfs.
+const clockTicks = 100
+
+// processStartTime returns the start time of the process, which is the start time of the container task.
+func processStartTime(pid int) (time.Time, error) {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	ticks, err := parseStartTicks(string(stat))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return time.Time{}, err
+	}
+	boot := time.Now().Add(-time.Duration(info.Uptime) * time.Second)
+
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// parseStartTicks returns the start time of the process since boot in clock ticks from the /proc/<pid>/stat content.
+func parseStartTicks(stat string) (uint64, error) {
+	// the command name in parentheses may contain spaces and parentheses
+	i := strings.LastIndex(stat, ")")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	// the fields after the command name start with the state, which is the 3rd field, starttime is the 22nd one
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
 func (c *ContainerdRuntime) GetNSPath(ctx context.Context, containername string) (string, error) {
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
 	task, err := c.getContainerTask(ctx, containername)
@@ -720,9 +733,9 @@ func (c *ContainerdRuntime) internalExec(ctx context.Context, containername stri
 		return nil, err
 	}
 
-	var stdinbuf, stdoutbuf, stderrbuf bytes.Buffer
+	var stdoutbuf, stderrbuf bytes.Buffer
 
-	cio_opt := cio.WithStreams(&stdinbuf, &stdoutbuf, &stderrbuf)
+	cio_opt := cio.WithStreams(bytes.NewReader(execCmd.GetStdin()), &stdoutbuf, &stderrbuf)
 	ioCreator := cio.NewCreator(cio_opt)
 
 	spec, err := container.Spec(ctx)
@@ -805,24 +818,30 @@ func (c *ContainerdRuntime) DeleteContainer(ctx context.Context, containerID str
 	log.Debugf("deleting container %s", containerID)
 	ctx = namespaces.WithNamespace(ctx, containerdNamespace)
 
-	err := c.StopContainer(ctx, containerID)
+	cont, err := c.client.LoadContainer(ctx, containerID)
 	if err != nil {
 		return err
 	}
-
-	cnic, cncl, cnirc, err := cniInit(containerID, "eth0", c.mgmt)
+	spec, err := cont.Spec(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = cnic.DelNetworkList(ctx, cncl, cnirc)
+	err = c.StopContainer(ctx, containerID)
 	if err != nil {
 		return err
 	}
 
-	cont, err := c.client.LoadContainer(ctx, containerID)
-	if err != nil {
-		return err
+	if hasOwnNetNS(spec) {
+		cnic, cncl, cnirc, err := cniInit(containerID, "eth0", c.mgmt)
+		if err != nil {
+			return err
+		}
+
+		err = cnic.DelNetworkList(ctx, cncl, cnirc)
+		if err != nil {
+			return err
+		}
 	}
 	var delOpts []containerd.DeleteOpts
 	delOpts = append(delOpts, containerd.WithSnapshotCleanup)
@@ -831,15 +850,37 @@ func (c *ContainerdRuntime) DeleteContainer(ctx context.Context, containerID str
 		return err
 	}
 
+	if err := os.Remove(hostsPath(containerID)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("failed to remove /etc/hosts file of container %s: %v", containerID, err)
+	}
+
 	log.Debugf("successfully deleted container %s", containerID)
 
 	return nil
 }
 
-// GetHostsPath returns fs path to a file which is mounted as /etc/hosts into a given container
-// TODO: do we need it here? currently no-op.
-func (c *ContainerdRuntime) GetHostsPath(context.Context, string) (string, error) {
-	return "", nil
+// hasOwnNetNS returns true when the container is created in a network namespace of its own,
+// which is attached to the management network, unlike the containers in the host or another container namespace.
+func hasOwnNetNS(spec *oci.Spec) bool {
+	if spec.Linux == nil {
+		return false
+	}
+	for _, ns := range spec.Linux.Namespaces {
+		if ns.Type == specs.NetworkNamespace {
+			return ns.Path == ""
+		}
+	}
+	return false
+}
+
+// GetHostsPath returns fs path to a file which is mounted as /etc/hosts into a given container.
+func (*ContainerdRuntime) GetHostsPath(_ context.Context, cID string) (string, error) {
+	hostsPath := hostsPath(cID)
+	if _, err := os.Stat(hostsPath); err != nil {
+		return "", err
+	}
+	log.Debugf("Method GetHostsPath was called with a resulting path %q", hostsPath)
+	return hostsPath, nil
 }
 
 // GetContainerStatus retrieves the ContainerStatus of the named container.
@@ -0,0 +1,144 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package containerd
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestBridgeGateway(t *testing.T) {
+	_, v4, _ := net.ParseCIDR("172.20.20.0/24")
+	_, v6, _ := net.ParseCIDR("2001:172:20:20::/64")
+
+	tests := map[string]struct {
+		subnet       *net.IPNet
+		userGw       string
+		addrs        []net.IP
+		wantGw       string
+		wantAssigned bool
+		wantErr      bool
+	}{
+		"first host of new bridge": {subnet: v4, wantGw: "172.20.20.1"},
+		"first host of ipv6":       {subnet: v6, wantGw: "2001:172:20:20::1"},
+		"address of existing bridge": {
+			subnet:       v4,
+			addrs:        []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("172.20.20.254")},
+			wantGw:       "172.20.20.254",
+			wantAssigned: true,
+		},
+		"user gateway": {
+			subnet: v4,
+			userGw: "172.20.20.100",
+			addrs:  []net.IP{net.ParseIP("172.20.20.1")},
+			wantGw: "172.20.20.100",
+		},
+		"user gateway outside of subnet": {subnet: v4, userGw: "10.0.0.1", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			gw, assigned, err := bridgeGateway(tc.subnet, tc.userGw, tc.addrs)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if gw.String() != tc.wantGw || assigned != tc.wantAssigned {
+				t.Errorf("expected gateway %s assigned %v, got %s assigned %v", tc.wantGw, tc.wantAssigned, gw, assigned)
+			}
+		})
+	}
+}
+
+func TestCNINetConfList(t *testing.T) {
+	b, err := cniNetConfList(&types.MgmtNet{
+		Bridge:     "br-clab",
+		IPv4Subnet: "172.20.20.0/24",
+		IPv4Gw:     "172.20.20.1",
+		MTU:        "9000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := struct {
+		Plugins []struct {
+			Type string `json:"type"`
+			MTU  int    `json:"mtu"`
+			IPAM struct {
+				Ranges [][]map[string]string `json:"ranges"`
+			} `json:"ipam"`
+			Capabilities map[string]bool `json:"capabilities"`
+		} `json:"plugins"`
+	}{}
+	if err := json.Unmarshal(b, &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	br := conf.Plugins[0]
+	if br.Type != "bridge" || br.MTU != 9000 || !br.Capabilities["ips"] {
+		t.Errorf("unexpected bridge plugin config: %s", b)
+	}
+	// no range of the ipv6 subnet which is not set
+	want := [][]map[string]string{{{"subnet": "172.20.20.0/24", "gateway": "172.20.20.1"}}}
+	if d := cmp.Diff(want, br.IPAM.Ranges); d != "" {
+		t.Errorf("unexpected ipam ranges (-want +got):\n%s", d)
+	}
+
+	if _, err := cniNetConfList(&types.MgmtNet{MTU: "jumbo"}); err == nil {
+		t.Errorf("expected error of invalid mtu")
+	}
+}
+
+func TestHostsEntries(t *testing.T) {
+	got := string(hostsEntries([]string{"srl1:172.20.20.2", "srl1:2001:172:20:20::2", "invalid"},
+		"client", "172.20.20.3", ""))
+	want := "172.20.20.2\tsrl1\n2001:172:20:20::2\tsrl1\n172.20.20.3\tclient\n"
+	if got != want {
+		t.Errorf("expected hosts entries %q, got %q", want, got)
+	}
+}
+
+func TestParseStartTicks(t *testing.T) {
+	stat := "4242 (my (proc) name) S 1 4242 4242 0 -1 4194560 1143 0 0 0 2 1 0 0 20 0 1 0 123456 9461760 885"
+	ticks, err := parseStartTicks(stat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticks != 123456 {
+		t.Errorf("expected start ticks 123456, got %d", ticks)
+	}
+
+	if _, err := parseStartTicks("4242 (sh) S 1"); err == nil {
+		t.Errorf("expected error of truncated stat")
+	}
+}
+
+func TestHasOwnNetNS(t *testing.T) {
+	tests := map[string]struct {
+		namespaces []specs.LinuxNamespace
+		want       bool
+	}{
+		"own netns":       {namespaces: []specs.LinuxNamespace{{Type: specs.NetworkNamespace}}, want: true},
+		"host netns":      {namespaces: []specs.LinuxNamespace{{Type: specs.PIDNamespace}}},
+		"container netns": {namespaces: []specs.LinuxNamespace{{Type: specs.NetworkNamespace, Path: "/proc/42/ns/net"}}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &specs.Spec{Linux: &specs.Linux{Namespaces: tc.namespaces}}
+			if got := hasOwnNetNS(spec); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package containerd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// hostsDir is the directory of the /etc/hosts files mounted into the containers,
+// containerd doesn't manage /etc/hosts files of the containers unlike docker.
+const hostsDir = "/run/containerlab/containerd/hosts"
+
+// defaultHosts are the /etc/hosts entries of the containers with a network namespace of their own.
+const defaultHosts = `127.0.0.1	localhost
+::1	localhost ip6-localhost ip6-loopback
+fe00::0	ip6-localnet
+ff00::0	ip6-mcastprefix
+ff02::1	ip6-allnodes
+ff02::2	ip6-allrouters
+`
+
+// hostsPath returns the path of the /etc/hosts file of the container.
+func hostsPath(cID string) string {
+	return filepath.Join(hostsDir, cID)
+}
+
+// hostsEntries returns the /etc/hosts entries of the extra hosts in the name:ip format
+// followed by the entries of the hostname with its addresses.
+func hostsEntries(extraHosts []string, hostname string, ips ...string) []byte {
+	var b bytes.Buffer
+	for _, h := range extraHosts {
+		name, ip, ok := strings.Cut(h, ":")
+		if !ok || ip == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s\t%s\n", ip, name)
+	}
+	for _, ip := range ips {
+		if ip != "" {
+			fmt.Fprintf(&b, "%s\t%s\n", ip, hostname)
+		}
+	}
+	return b.Bytes()
+}
+
+// hostsMount writes the /etc/hosts file of the node with its extra hosts
+// and returns the mount of the file into the container.
+// The nodes in the host network namespace get a copy of the host /etc/hosts file.
+func hostsMount(node *types.NodeConfig) (specs.Mount, error) {
+	hosts := []byte(defaultHosts)
+	if node.NetworkMode == "host" {
+		var err error
+		if hosts, err = os.ReadFile("/etc/hosts"); err != nil {
+			return specs.Mount{}, err
+		}
+	}
+	hosts = append(hosts, hostsEntries(node.ExtraHosts, node.ShortName)...)
+
+	utils.CreateDirectory(hostsDir, 0755)
+	p := hostsPath(node.LongName)
+	if err := os.WriteFile(p, hosts, 0644); err != nil { // skipcq: GSC-G302
+		return specs.Mount{}, fmt.Errorf("failed to write /etc/hosts file of node %q: %v", node.ShortName, err)
+	}
+
+	return specs.Mount{
+		Source:      p,
+		Destination: "/etc/hosts",
+		Type:        "bind",
+		Options:     []string{"rbind", "rprivate"},
+	}, nil
+}
+
+// appendHostsEntries adds the entries of the hostname with the management addresses
+// to the /etc/hosts file of the container.
+func appendHostsEntries(cID, hostname string, ips ...string) error {
+	f, err := os.OpenFile(hostsPath(cID), os.O_APPEND|os.O_WRONLY, 0644) // skipcq: GSC-G302
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(hostsEntries(nil, hostname, ips...))
+	return err
+}
@@ -0,0 +1,348 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package containerd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/containernetworking/cni/libcni"
+	current "github.com/containernetworking/cni/pkg/types/040"
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/firewall"
+	"github.com/srl-labs/containerlab/types"
+	"github.com/srl-labs/containerlab/utils"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	sysctlBase = "/proc/sys"
+	// cniNetName is the name of the CNI network of the management bridge.
+	cniNetName = "clabmgmt"
+)
+
+// CreateNet creates the linux bridge of the management network or reuses it if it exists,
+// e.g. when the bridge is set by a user or was created by another lab with the same network.
+// The gateway addresses are assigned to the bridge, so that nodes can use them prior to being deployed.
+func (c *ContainerdRuntime) CreateNet(_ context.Context) error {
+	mtu, err := parseMTU(c.mgmt.MTU)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Checking if bridge %q of network %q exists", c.mgmt.Bridge, c.mgmt.Network)
+	br, err := netlink.LinkByName(c.mgmt.Bridge)
+	switch {
+	case errors.As(err, &netlink.LinkNotFoundError{}):
+		log.Infof("Creating management bridge: Name=%q, IPv4Subnet=%q, IPv6Subnet=%q, MTU=%q",
+			c.mgmt.Bridge, c.mgmt.IPv4Subnet, c.mgmt.IPv6Subnet, c.mgmt.MTU)
+
+		la := netlink.NewLinkAttrs()
+		la.Name = c.mgmt.Bridge
+		la.MTU = mtu
+		if err := netlink.LinkAdd(&netlink.Bridge{LinkAttrs: la}); err != nil {
+			return fmt.Errorf("failed to create bridge %q: %v", c.mgmt.Bridge, err)
+		}
+		if br, err = netlink.LinkByName(c.mgmt.Bridge); err != nil {
+			return err
+		}
+	case err == nil:
+		if _, ok := br.(*netlink.Bridge); !ok {
+			return fmt.Errorf("%q already exists but is not a bridge", c.mgmt.Bridge)
+		}
+		log.Debugf("bridge %q was found. Reusing it...", c.mgmt.Bridge)
+	default:
+		return err
+	}
+
+	if err := c.setBridgeGateways(br); err != nil {
+		return err
+	}
+	if err := netlink.LinkSetUp(br); err != nil {
+		return fmt.Errorf("failed to set bridge %q up: %v", c.mgmt.Bridge, err)
+	}
+
+	log.Debugf("Management network %q, bridge name %q, ipv4 gw %q, ipv6 gw %q",
+		c.mgmt.Network, c.mgmt.Bridge, c.mgmt.IPv4Gw, c.mgmt.IPv6Gw)
+
+	return c.postCreateNetActions()
+}
+
+// setBridgeGateways assigns the gateway addresses of the management subnets to the bridge
+// and saves them under mgmt struct. The addresses of an existing bridge are used as the gateways,
+// unless the gateways are set by a user.
+func (c *ContainerdRuntime) setBridgeGateways(br netlink.Link) error {
+	families := []struct {
+		subnet string
+		gw     *string
+		family int
+	}{
+		{subnet: c.mgmt.IPv4Subnet, gw: &c.mgmt.IPv4Gw, family: netlink.FAMILY_V4},
+		{subnet: c.mgmt.IPv6Subnet, gw: &c.mgmt.IPv6Gw, family: netlink.FAMILY_V6},
+	}
+
+	for _, f := range families {
+		if f.subnet == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(f.subnet)
+		if err != nil {
+			return fmt.Errorf("invalid management subnet %q: %v", f.subnet, err)
+		}
+
+		addrs, err := netlink.AddrList(br, f.family)
+		if err != nil {
+			return err
+		}
+		ips := make([]net.IP, 0, len(addrs))
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+
+		gw, assigned, err := bridgeGateway(subnet, *f.gw, ips)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			addr := &netlink.Addr{IPNet: &net.IPNet{IP: gw, Mask: subnet.Mask}}
+			if err := netlink.AddrAdd(br, addr); err != nil {
+				return fmt.Errorf("failed to add address %s to bridge %q: %v", addr.IPNet, c.mgmt.Bridge, err)
+			}
+		}
+		*f.gw = gw.String()
+	}
+
+	return nil
+}
+
+// bridgeGateway returns the gateway of the subnet and whether it is already assigned to the bridge with addrs.
+// The gateway set by a user is used first, then the address of the bridge in the subnet,
+// then the first host address of the subnet, which is the default gateway of host-local IPAM.
+func bridgeGateway(subnet *net.IPNet, userGw string, addrs []net.IP) (net.IP, bool, error) {
+	if userGw != "" {
+		gw := net.ParseIP(userGw)
+		if gw == nil || !subnet.Contains(gw) {
+			return nil, false, fmt.Errorf("gateway %q is not an address of the management subnet %s", userGw, subnet)
+		}
+		for _, a := range addrs {
+			if a.Equal(gw) {
+				return gw, true, nil
+			}
+		}
+		return gw, false, nil
+	}
+
+	for _, a := range addrs {
+		if subnet.Contains(a) {
+			return a, true, nil
+		}
+	}
+
+	gw := make(net.IP, len(subnet.IP))
+	copy(gw, subnet.IP)
+	for i := len(gw) - 1; i >= 0; i-- {
+		gw[i]++
+		if gw[i] != 0 {
+			break
+		}
+	}
+	return gw, false, nil
+}
+
+// postCreateNetActions performs additional actions after the management bridge has been created.
+func (c *ContainerdRuntime) postCreateNetActions() error {
+	// the containers reach the outside world via the gateways on the bridge
+	log.Debug("Enable IP forwarding on the host")
+	if err := setSysctl("net/ipv4/ip_forward", 1); err != nil {
+		return fmt.Errorf("failed to enable IPv4 forwarding: %v", err)
+	}
+	if c.mgmt.IPv6Subnet != "" {
+		if err := setSysctl("net/ipv6/conf/all/forwarding", 1); err != nil {
+			return fmt.Errorf("failed to enable IPv6 forwarding: %v", err)
+		}
+	}
+
+	log.Debug("Disable RPF check on the host")
+	if err := setSysctl("net/ipv4/conf/all/rp_filter", 0); err != nil {
+		return fmt.Errorf("failed to disable RP filter on the host for the 'all' scope: %v", err)
+	}
+	if err := setSysctl("net/ipv4/conf/default/rp_filter", 0); err != nil {
+		return fmt.Errorf("failed to disable RP filter on the host for the 'default' scope: %v", err)
+	}
+
+	log.Debugf("Enable LLDP on the linux bridge %s", c.mgmt.Bridge)
+	file := "/sys/class/net/" + c.mgmt.Bridge + "/bridge/group_fwd_mask"
+	if err := os.WriteFile(file, []byte(strconv.Itoa(16384)), 0640); err != nil { // skipcq: GO-S2306
+		log.Warnf("failed to enable LLDP on the management bridge: %v", err)
+	}
+
+	log.Debugf("Disabling TX checksum offloading for the %s bridge interface...", c.mgmt.Bridge)
+	if err := utils.EthtoolTXOff(c.mgmt.Bridge); err != nil {
+		log.Warnf("failed to disable TX checksum offloading for the %s bridge interface: %v", c.mgmt.Bridge, err)
+	}
+
+	if c.mgmt.ExternalAccess != nil && *c.mgmt.ExternalAccess {
+		if err := firewall.InstallBridgeRule(c.mgmt.Bridge); err != nil {
+			log.Warnf("errors during forwarding rules install: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func setSysctl(sysctl string, newVal int) error {
+	return os.WriteFile(path.Join(sysctlBase, sysctl), []byte(strconv.Itoa(newVal)), 0600)
+}
+
+// parseMTU returns the MTU of the management network, 0 when it is not set.
+func parseMTU(mtu string) (int, error) {
+	if mtu == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(mtu)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid mtu %q of the management network", mtu)
+	}
+	return v, nil
+}
+
+func cniInit(cId, ifName string, mgmtNet *types.MgmtNet) (*libcni.CNIConfig, *libcni.NetworkConfigList, *libcni.RuntimeConf, error) {
+	// allow overwriting cni plugin binary path via ENV var
+	cnic := libcni.NewCNIConfigWithCacheDir([]string{utils.GetCNIBinaryPath()}, cniCache, nil)
+
+	cniConfig, err := cniNetConfList(mgmtNet)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cncl, err := libcni.ConfListFromBytes(cniConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cnirc := &libcni.RuntimeConf{
+		ContainerID: cId,
+		IfName:      ifName,
+		// // NetNS must be set later, can just be determined after container start
+		// NetNS:          node.NSPath,
+		CapabilityArgs: make(map[string]interface{}),
+	}
+	return cnic, cncl, cnirc, nil
+}
+
+// cniNetConfList returns the CNI network configuration list attaching the containers to the management bridge.
+// The gateways are assigned to the bridge by CreateNet, the static management addresses of the nodes
+// are passed to host-local IPAM with the ips capability, the mac addresses with the mac capability.
+func cniNetConfList(mgmtNet *types.MgmtNet) ([]byte, error) {
+	mtu, err := parseMTU(mgmtNet.MTU)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges [][]map[string]string
+	var routes []map[string]string
+	for _, r := range []struct{ subnet, gw, dst string }{
+		{subnet: mgmtNet.IPv4Subnet, gw: mgmtNet.IPv4Gw, dst: "0.0.0.0/0"},
+		{subnet: mgmtNet.IPv6Subnet, gw: mgmtNet.IPv6Gw, dst: "::/0"},
+	} {
+		if r.subnet == "" {
+			continue
+		}
+		ipRange := map[string]string{"subnet": r.subnet}
+		if r.gw != "" {
+			ipRange["gateway"] = r.gw
+		}
+		ranges = append(ranges, []map[string]string{ipRange})
+		routes = append(routes, map[string]string{"dst": r.dst})
+	}
+
+	bridge := map[string]interface{}{
+		"type":        "bridge",
+		"bridge":      mgmtNet.Bridge,
+		"ipMasq":      true,
+		"hairpinMode": true,
+		"capabilities": map[string]bool{
+			"ips": true,
+		},
+		"ipam": map[string]interface{}{
+			"type":   "host-local",
+			"ranges": ranges,
+			"routes": routes,
+		},
+	}
+	if mtu > 0 {
+		bridge["mtu"] = mtu
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"cniVersion": "0.4.0",
+		"name":       cniNetName,
+		"plugins": []interface{}{
+			bridge,
+			map[string]interface{}{
+				"type":         "tuning",
+				"capabilities": map[string]bool{"mac": true},
+			},
+			map[string]interface{}{
+				"type":         "portmap",
+				"capabilities": map[string]bool{"portMappings": true},
+			},
+		},
+	})
+}
+
+// staticMgmtIPs returns the static management addresses of the node requested from host-local IPAM.
+func staticMgmtIPs(node *types.NodeConfig) []string {
+	var ips []string
+	for _, ip := range []string{node.MgmtIPv4Address, node.MgmtIPv6Address} {
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// mgmtIPsFromResult returns the management addresses assigned to the container by the CNI plugins.
+func mgmtIPsFromResult(result *current.Result) types.GenericMgmtIPs {
+	ips := types.GenericMgmtIPs{}
+	for _, ip := range result.IPs {
+		pLen, _ := ip.Address.Mask.Size()
+		gw := ""
+		if ip.Gateway != nil {
+			gw = ip.Gateway.String()
+		}
+		switch ip.Version {
+		case "4":
+			ips.IPv4addr, ips.IPv4pLen, ips.IPv4Gw = ip.Address.IP.String(), pLen, gw
+		case "6":
+			ips.IPv6addr, ips.IPv6pLen, ips.IPv6Gw = ip.Address.IP.String(), pLen, gw
+		}
+	}
+	return ips
+}
+
+// cniMgmtIPs returns the management addresses of the container cached by the CNI plugins,
+// which are empty if the container is not attached to the management network.
+func (c *ContainerdRuntime) cniMgmtIPs(cID string) (types.GenericMgmtIPs, error) {
+	cnic, cncl, cnirc, err := cniInit(cID, "eth0", c.mgmt)
+	if err != nil {
+		return types.GenericMgmtIPs{}, err
+	}
+	res, err := cnic.GetNetworkListCachedResult(cncl, cnirc)
+	if err != nil || res == nil {
+		return types.GenericMgmtIPs{}, err
+	}
+	result, err := current.NewResultFromResult(res)
+	if err != nil {
+		return types.GenericMgmtIPs{}, err
+	}
+	return mgmtIPsFromResult(result), nil
+}
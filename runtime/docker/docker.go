@@ -281,9 +281,9 @@ func (d *DockerRuntime) postCreateNetActions() (err error) {
 	if err != nil {
 		log.Warnf("failed to disable TX checksum offloading for the %s bridge interface: %v", d.mgmt.Bridge, err)
 	}
-	err = d.installIPTablesFwdRule()
+	err = d.installFwdRule()
 	if err != nil {
-		log.Warnf("errors during iptables rules install: %v", err)
+		log.Warnf("errors during forwarding rules install: %v", err)
 	}
 
 	return nil
@@ -318,9 +318,9 @@ func (d *DockerRuntime) DeleteNet(ctx context.Context) (err error) {
 		return err
 	}
 
-	err = d.deleteIPTablesFwdRule()
+	err = d.deleteFwdRule()
 	if err != nil {
-		log.Warnf("errors during iptables rules removal: %v", err)
+		log.Warnf("errors during forwarding rules removal: %v", err)
 	}
 
 	return nil
@@ -688,8 +688,10 @@ func (d *DockerRuntime) Exec(ctx context.Context, cID string, execCmd *exec.Exec
 	if err != nil {
 		return nil, err
 	}
+	stdin := execCmd.GetStdin()
 	execID, err := d.Client.ContainerExecCreate(ctx, cID, dockerTypes.ExecConfig{
 		User:         "root",
+		AttachStdin:  len(stdin) != 0,
 		AttachStderr: true,
 		AttachStdout: true,
 		Cmd:          execCmd.GetCmd(),
@@ -708,6 +710,18 @@ func (d *DockerRuntime) Exec(ctx context.Context, cID string, execCmd *exec.Exec
 	defer rsp.Close()
 	log.Debugf("%s exec attached %v", cont.Name, cID)
 
+	if len(stdin) != 0 {
+		go func() {
+			if _, err := rsp.Conn.Write(stdin); err != nil {
+				log.Errorf("failed to write the stdin of exec in container %s: %v", cont.Name, err)
+			}
+			// the command reading its stdin to the end is done once the stdin is closed
+			if err := rsp.CloseWrite(); err != nil {
+				log.Errorf("failed to close the stdin of exec in container %s: %v", cont.Name, err)
+			}
+		}()
+	}
+
 	var outBuf, errBuf bytes.Buffer
 	outputDone := make(chan error)
 
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/firewall"
+	"github.com/srl-labs/containerlab/utils"
+)
+
+// installFwdRule installs the `allow` rule for traffic destined nodes on the clab management network
+// with the firewall backend in use on the host.
+func (d *DockerRuntime) installFwdRule() (err error) {
+	if !*d.mgmt.ExternalAccess {
+		return
+	}
+
+	if d.mgmt.Bridge == "" {
+		log.Debug("skipping setup of forwarding rules for non-bridged management network")
+		return
+	}
+
+	fw := firewall.HostClient()
+	err = fw.InstallForwardingRule(firewall.DockerUserChain, d.mgmt.Bridge)
+	if errors.Is(err, firewall.ErrChainNotFound) {
+		// missing DOCKER-USER chain typically happens with old docker installations (centos7 hello) from default repos
+		return fmt.Errorf("missing DOCKER-USER %s chain. See http://containerlab.dev/manual/network/#external-access", fw.Name())
+	}
+
+	return err
+}
+
+// deleteFwdRule deletes `allow` rule installed with installFwdRule when the bridge interface doesn't exist anymore.
+func (d *DockerRuntime) deleteFwdRule() (err error) {
+	if !*d.mgmt.ExternalAccess {
+		return
+	}
+
+	br := d.mgmt.Bridge
+
+	if br == "docker0" {
+		log.Debug("skipping deletion of forwarding rule for non-bridged or default management network")
+		return
+	}
+
+	// we are not deleting the rule if the bridge still exists
+	// it happens when bridge is either still in use by docker network
+	// or it is managed externally (created manually)
+	_, err = utils.BridgeByName(br)
+	if err == nil {
+		log.Debugf("bridge %s is still in use, not removing the forwarding rule", br)
+		return nil
+	}
+
+	fw := firewall.HostClient()
+	err = fw.DeleteForwardingRule(firewall.DockerUserChain, br)
+	if errors.Is(err, firewall.ErrChainNotFound) {
+		return fmt.Errorf("missing DOCKER-USER %s chain. See http://containerlab.dev/manual/network/#external-access", fw.Name())
+	}
+
+	return err
+}
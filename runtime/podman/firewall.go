@@ -0,0 +1,32 @@
+//go:build linux && podman
+// +build linux,podman
+
+package podman
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/firewall"
+)
+
+// installFwdRule installs the `allow` rule for traffic destined nodes on the clab management network.
+func (r *PodmanRuntime) installFwdRule() error {
+	if r.mgmt.ExternalAccess == nil || !*r.mgmt.ExternalAccess {
+		return nil
+	}
+
+	if r.mgmt.Bridge == "" {
+		log.Debug("skipping setup of forwarding rules for non-bridged management network")
+		return nil
+	}
+
+	return firewall.InstallBridgeRule(r.mgmt.Bridge)
+}
+
+// deleteFwdRule deletes the `allow` rule installed with installFwdRule.
+func (r *PodmanRuntime) deleteFwdRule() error {
+	if r.mgmt.ExternalAccess == nil || !*r.mgmt.ExternalAccess || r.mgmt.Bridge == "" {
+		return nil
+	}
+
+	return firewall.DeleteBridgeRule(r.mgmt.Bridge)
+}
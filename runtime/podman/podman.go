@@ -4,6 +4,8 @@
 package podman
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -104,7 +106,10 @@ func (r *PodmanRuntime) CreateNet(ctx context.Context) error {
 		}
 		log.Debugf("Create network response was: %+v", resp)
 	}
-	return err
+	if err := r.installFwdRule(); err != nil {
+		log.Warnf("errors during forwarding rules install: %v", err)
+	}
+	return nil
 }
 
 // DeleteNet deletes a clab mgmt bridge.
@@ -123,6 +128,9 @@ func (r *PodmanRuntime) DeleteNet(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("error while trying to remove a mgmt network %w", err)
 	}
+	if err := r.deleteFwdRule(); err != nil {
+		log.Warnf("errors during forwarding rules removal: %v", err)
+	}
 	return nil
 }
 
@@ -240,9 +248,11 @@ func (r *PodmanRuntime) Exec(ctx context.Context, cID string, execCmd *exec.Exec
 	if err != nil {
 		return nil, err
 	}
+	stdin := execCmd.GetStdin()
 	execCreateConf := handlers.ExecCreateConfig{
 		ExecConfig: dockerTypes.ExecConfig{
 			User:         "root",
+			AttachStdin:  len(stdin) != 0,
 			AttachStderr: true,
 			AttachStdout: true,
 			Cmd:          execCmd.GetCmd(),
@@ -256,6 +266,10 @@ func (r *PodmanRuntime) Exec(ctx context.Context, cID string, execCmd *exec.Exec
 	var sOut, sErr podmanWriterCloser
 	execSAAOpts := new(containers.ExecStartAndAttachOptions).WithOutputStream(&sOut).WithErrorStream(
 		&sErr).WithAttachOutput(true).WithAttachError(true)
+	if len(stdin) != 0 {
+		// the stdin is closed once it is written
+		execSAAOpts = execSAAOpts.WithAttachInput(true).WithInputStream(*bufio.NewReader(bytes.NewReader(stdin)))
+	}
 
 	err = containers.ExecStartAndAttach(ctx, execID, execSAAOpts)
 	if err != nil {
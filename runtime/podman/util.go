@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 
 	netTypes "github.com/containers/common/libnetwork/types"
@@ -35,8 +36,17 @@ func (*podmanWriterCloser) Close() error {
 	return nil
 }
 
+// defaultSocket is the socket of the podman service run by root.
+const defaultSocket = "unix://run/podman/podman.sock"
+
+// connect connects to the podman service, whose socket is set with CONTAINER_HOST env var
+// for the rootless podman service run by the user.
 func (*PodmanRuntime) connect(ctx context.Context) (context.Context, error) {
-	return bindings.NewConnection(ctx, "unix://run/podman/podman.sock")
+	uri := defaultSocket
+	if h, ok := os.LookupEnv("CONTAINER_HOST"); ok && h != "" {
+		uri = h
+	}
+	return bindings.NewConnection(ctx, uri)
 }
 
 func (r *PodmanRuntime) createContainerSpec(ctx context.Context, cfg *types.NodeConfig) (specgen.SpecGenerator, error) {
@@ -0,0 +1,223 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// KeyAlgorithmRSA, KeyAlgorithmECDSA and KeyAlgorithmEd25519 are the supported algorithms of the generated keys.
+	KeyAlgorithmRSA     = "rsa"
+	KeyAlgorithmECDSA   = "ecdsa"
+	KeyAlgorithmEd25519 = "ed25519"
+
+	// DefaultCAExpiry is the validity period of the generated lab root CA certificate.
+	DefaultCAExpiry = "262800h"
+	// DefaultCertificatePath is the path the issued certificate and key are mounted to in the node.
+	DefaultCertificatePath = "/etc/containerlab/tls"
+)
+
+// CertificateAuthority is the lab root CA, which signs the certificates of the nodes.
+// The CA is generated for the lab unless an existing CA certificate and key are imported.
+type CertificateAuthority struct {
+	// Cert and Key are the paths to the certificate and the key of an existing CA to import
+	Cert string `yaml:"cert,omitempty" json:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty" json:"key,omitempty"`
+	// KeyAlgorithm of the generated CA key, rsa by default
+	KeyAlgorithm string `yaml:"key-algorithm,omitempty" json:"key-algorithm,omitempty"`
+	// KeySize is the size of the rsa key in bits or the curve size of the ecdsa key
+	KeySize int `yaml:"key-size,omitempty" json:"key-size,omitempty"`
+	// Expiry is the validity period of the generated CA certificate, e.g. 87600h
+	Expiry string `yaml:"expiry,omitempty" json:"expiry,omitempty"`
+	// Subject of the generated CA certificate
+	Subject *CertificateSubject `yaml:"subject,omitempty" json:"subject,omitempty"`
+}
+
+// CertificateSubject is the subject of a generated certificate.
+// The fields which are not set take the default values of the certificate.
+type CertificateSubject struct {
+	CommonName       string `yaml:"common-name,omitempty" json:"common-name,omitempty"`
+	Country          string `yaml:"country,omitempty" json:"country,omitempty"`
+	Locality         string `yaml:"locality,omitempty" json:"locality,omitempty"`
+	Organization     string `yaml:"organization,omitempty" json:"organization,omitempty"`
+	OrganizationUnit string `yaml:"organization-unit,omitempty" json:"organization-unit,omitempty"`
+}
+
+// DefaultKeySize returns the default size of the keys of the given algorithm:
+// 2048 bits for rsa keys, the P-256 curve for ecdsa keys and 0 for ed25519 keys, which have a fixed size.
+func DefaultKeySize(algo string) int {
+	switch algo {
+	case KeyAlgorithmECDSA:
+		return 256
+	case KeyAlgorithmEd25519:
+		return 0
+	}
+	return 2048
+}
+
+// ValidateKey checks that the key size is valid for the key algorithm.
+// A zero size stands for the default size of the algorithm.
+func ValidateKey(algo string, size int) error {
+	if size == 0 {
+		size = DefaultKeySize(algo)
+	}
+
+	switch algo {
+	case KeyAlgorithmRSA:
+		if size < 2048 || size > 8192 {
+			return fmt.Errorf("rsa key-size must be in the range of 2048-8192, got %d", size)
+		}
+	case KeyAlgorithmECDSA:
+		switch size {
+		case 256, 384, 521:
+		default:
+			return fmt.Errorf("ecdsa key-size must be one of 256, 384 or 521, got %d", size)
+		}
+	case KeyAlgorithmEd25519:
+		if size != 0 {
+			return fmt.Errorf("key-size can't be set for ed25519 keys")
+		}
+	default:
+		return fmt.Errorf("unsupported key-algorithm %q, expected one of %s, %s or %s",
+			algo, KeyAlgorithmRSA, KeyAlgorithmECDSA, KeyAlgorithmEd25519)
+	}
+
+	return nil
+}
+
+// Validate checks that the CA is either imported or has valid parameters of the CA to generate.
+func (ca *CertificateAuthority) Validate() error {
+	if ca == nil {
+		return nil
+	}
+
+	if (ca.Cert == "") != (ca.Key == "") {
+		return fmt.Errorf("both cert and key must be set to import a CA")
+	}
+	if ca.Cert != "" {
+		if ca.KeyAlgorithm != "" || ca.KeySize != 0 || ca.Expiry != "" || ca.Subject != nil {
+			return fmt.Errorf("key-algorithm, key-size, expiry and subject can't be set for an imported CA")
+		}
+		return nil
+	}
+
+	if err := ValidateKey(ca.GetKeyAlgorithm(), ca.KeySize); err != nil {
+		return err
+	}
+
+	if d, err := time.ParseDuration(ca.GetExpiry()); err != nil || d <= 0 {
+		return fmt.Errorf("invalid expiry %q, expected a positive duration, e.g. 87600h", ca.Expiry)
+	}
+
+	return nil
+}
+
+// IsImported returns true when an existing CA is imported.
+func (ca *CertificateAuthority) IsImported() bool {
+	return ca != nil && ca.Cert != ""
+}
+
+// GetKeyAlgorithm returns the algorithm of the generated CA key, rsa by default.
+func (ca *CertificateAuthority) GetKeyAlgorithm() string {
+	if ca == nil || ca.KeyAlgorithm == "" {
+		return KeyAlgorithmRSA
+	}
+	return ca.KeyAlgorithm
+}
+
+// GetKeySize returns the size of the generated CA key, see DefaultKeySize for its default.
+func (ca *CertificateAuthority) GetKeySize() int {
+	if ca == nil || ca.KeySize == 0 {
+		return DefaultKeySize(ca.GetKeyAlgorithm())
+	}
+	return ca.KeySize
+}
+
+// GetExpiry returns the validity period of the generated CA certificate.
+func (ca *CertificateAuthority) GetExpiry() string {
+	if ca == nil || ca.Expiry == "" {
+		return DefaultCAExpiry
+	}
+	return ca.Expiry
+}
+
+// GetSubject returns the subject of the generated CA certificate.
+func (ca *CertificateAuthority) GetSubject() *CertificateSubject {
+	if ca == nil {
+		return nil
+	}
+	return ca.Subject
+}
+
+// CertificateConfig is the certificate of a node issued by the lab root CA.
+type CertificateConfig struct {
+	// Issue enables the certificate of the node
+	Issue *bool `yaml:"issue,omitempty" json:"issue,omitempty"`
+	// SANs are the subject alternative names of the certificate added to the node names
+	SANs []string `yaml:"sans,omitempty" json:"sans,omitempty"`
+	// Path is the directory the certificate and the key are mounted to in the node
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// KeyAlgorithm of the node key, rsa by default
+	KeyAlgorithm string `yaml:"key-algorithm,omitempty" json:"key-algorithm,omitempty"`
+	// KeySize is the size of the rsa key in bits or the curve size of the ecdsa key
+	KeySize int `yaml:"key-size,omitempty" json:"key-size,omitempty"`
+	// Subject of the node certificate
+	Subject *CertificateSubject `yaml:"subject,omitempty" json:"subject,omitempty"`
+}
+
+// Validate checks the key algorithm and size of the node certificate.
+func (c *CertificateConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	return ValidateKey(c.GetKeyAlgorithm(), c.KeySize)
+}
+
+// IsIssued returns true when the certificate of the node is to be issued.
+func (c *CertificateConfig) IsIssued() bool {
+	return c != nil && c.Issue != nil && *c.Issue
+}
+
+// GetSANs returns the subject alternative names of the certificate.
+func (c *CertificateConfig) GetSANs() []string {
+	if c == nil {
+		return nil
+	}
+	return c.SANs
+}
+
+// GetPath returns the directory the certificate and the key are mounted to in the node.
+func (c *CertificateConfig) GetPath() string {
+	if c == nil || c.Path == "" {
+		return DefaultCertificatePath
+	}
+	return c.Path
+}
+
+// GetKeyAlgorithm returns the algorithm of the node key, rsa by default.
+func (c *CertificateConfig) GetKeyAlgorithm() string {
+	if c == nil || c.KeyAlgorithm == "" {
+		return KeyAlgorithmRSA
+	}
+	return c.KeyAlgorithm
+}
+
+// GetKeySize returns the size of the node key, see DefaultKeySize for its default.
+func (c *CertificateConfig) GetKeySize() int {
+	if c == nil || c.KeySize == 0 {
+		return DefaultKeySize(c.GetKeyAlgorithm())
+	}
+	return c.KeySize
+}
+
+// GetSubject returns the subject of the node certificate.
+func (c *CertificateConfig) GetSubject() *CertificateSubject {
+	if c == nil {
+		return nil
+	}
+	return c.Subject
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCertificateAuthorityValidate(t *testing.T) {
+	tests := map[string]struct {
+		ca      *CertificateAuthority
+		wantErr bool
+	}{
+		"not set":               {ca: nil},
+		"defaults":              {ca: &CertificateAuthority{}},
+		"ecdsa":                 {ca: &CertificateAuthority{KeyAlgorithm: "ecdsa", KeySize: 384, Expiry: "8760h"}},
+		"ed25519":               {ca: &CertificateAuthority{KeyAlgorithm: "ed25519", Subject: &CertificateSubject{CommonName: "lab CA"}}},
+		"ed25519 with key size": {ca: &CertificateAuthority{KeyAlgorithm: "ed25519", KeySize: 256}, wantErr: true},
+		"import":                {ca: &CertificateAuthority{Cert: "ca.pem", Key: "ca-key.pem"}},
+		"import with subject":   {ca: &CertificateAuthority{Cert: "ca.pem", Key: "ca-key.pem", Subject: &CertificateSubject{}}, wantErr: true},
+		"import without key":    {ca: &CertificateAuthority{Cert: "ca.pem"}, wantErr: true},
+		"import with key size":  {ca: &CertificateAuthority{Cert: "ca.pem", Key: "ca-key.pem", KeySize: 4096}, wantErr: true},
+		"weak rsa key":          {ca: &CertificateAuthority{KeySize: 1024}, wantErr: true},
+		"invalid ecdsa curve":   {ca: &CertificateAuthority{KeyAlgorithm: "ecdsa", KeySize: 2048}, wantErr: true},
+		"unsupported algo":      {ca: &CertificateAuthority{KeyAlgorithm: "dsa"}, wantErr: true},
+		"invalid expiry":        {ca: &CertificateAuthority{Expiry: "10y"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.ca.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetNodeCertificate(t *testing.T) {
+	topo := &Topology{
+		Defaults: &NodeDefinition{
+			Certificate: &CertificateConfig{
+				Issue:   boolptr(true),
+				SANs:    []string{"lab.example.com"},
+				Subject: &CertificateSubject{Organization: "lab"},
+			},
+		},
+		Kinds: map[string]*NodeDefinition{
+			"linux": {Certificate: &CertificateConfig{Path: "/etc/tls", KeyAlgorithm: "ecdsa"}},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {
+				Kind:        "linux",
+				Certificate: &CertificateConfig{SANs: []string{"node1.example.com"}, KeySize: 384},
+			},
+			"node2": {
+				Kind:        "srl",
+				Certificate: &CertificateConfig{Issue: boolptr(false)},
+			},
+		},
+	}
+
+	want := map[string]*CertificateConfig{
+		"node1": {
+			Issue:        boolptr(true),
+			SANs:         []string{"node1.example.com", "lab.example.com"},
+			Path:         "/etc/tls",
+			KeyAlgorithm: "ecdsa",
+			KeySize:      384,
+			Subject:      &CertificateSubject{Organization: "lab"},
+		},
+		"node2": {
+			Issue:   boolptr(false),
+			SANs:    []string{"lab.example.com"},
+			Subject: &CertificateSubject{Organization: "lab"},
+		},
+	}
+
+	for name, w := range want {
+		if d := cmp.Diff(w, topo.GetNodeCertificate(name)); d != "" {
+			t.Errorf("%s certificate mismatch (-want +got):\n%s", name, d)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultHealthcheckInterval is the default interval between the probes of a node health.
+	DefaultHealthcheckInterval = 5 * time.Second
+	// DefaultHealthcheckRetries is the default number of the failed probes after which a node is unhealthy.
+	DefaultHealthcheckRetries = 3
+)
+
+// HealthcheckConfig is the healthcheck of a node, which reports the node healthy when its probe succeeds.
+// Exactly one of the exec, tcp-port and http probes is set.
+type HealthcheckConfig struct {
+	// Exec is the command executed in the node, which succeeds when it exits with the zero code
+	Exec string `yaml:"exec,omitempty" json:"exec,omitempty"`
+	// TCPPort is the port accepting TCP connections on the management address of the node
+	TCPPort int `yaml:"tcp-port,omitempty" json:"tcp-port,omitempty"`
+	// HTTP is the URL which responds with a 2xx or 3xx status code.
+	// The URL host defaults to the management address of the node, e.g. http://:8080/healthz
+	HTTP string `yaml:"http,omitempty" json:"http,omitempty"`
+	// Interval between the probes, 5s by default
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Retries is the number of consecutive failed probes after which the node is unhealthy, 3 by default
+	Retries int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// StartPeriod is the time given to the node to start, the probes failed during it are not counted
+	StartPeriod string `yaml:"start-period,omitempty" json:"start-period,omitempty"`
+}
+
+// Validate checks that a single probe of the healthcheck is set and its timings are valid.
+func (h *HealthcheckConfig) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	probes := 0
+	if h.Exec != "" {
+		probes++
+	}
+	if h.TCPPort != 0 {
+		probes++
+		if h.TCPPort < 0 || h.TCPPort > 65535 {
+			return fmt.Errorf("invalid tcp-port %d", h.TCPPort)
+		}
+	}
+	if h.HTTP != "" {
+		probes++
+		u, err := url.Parse(h.HTTP)
+		if err != nil {
+			return fmt.Errorf("invalid http URL %q: %v", h.HTTP, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid http URL %q, expected http or https scheme", h.HTTP)
+		}
+	}
+	if probes != 1 {
+		return fmt.Errorf("exactly one of exec, tcp-port and http must be set")
+	}
+
+	if h.Interval != "" {
+		if d, err := time.ParseDuration(h.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid interval %q, expected a positive duration, e.g. 10s", h.Interval)
+		}
+	}
+	if h.StartPeriod != "" {
+		if d, err := time.ParseDuration(h.StartPeriod); err != nil || d < 0 {
+			return fmt.Errorf("invalid start-period %q, expected a duration, e.g. 2m", h.StartPeriod)
+		}
+	}
+	if h.Retries < 0 {
+		return fmt.Errorf("retries must not be negative, got %d", h.Retries)
+	}
+
+	return nil
+}
+
+// GetInterval returns the interval between the probes.
+func (h *HealthcheckConfig) GetInterval() time.Duration {
+	if h == nil || h.Interval == "" {
+		return DefaultHealthcheckInterval
+	}
+	// the interval is checked by Validate
+	d, _ := time.ParseDuration(h.Interval)
+	return d
+}
+
+// GetRetries returns the number of consecutive failed probes after which the node is unhealthy.
+func (h *HealthcheckConfig) GetRetries() int {
+	if h == nil || h.Retries == 0 {
+		return DefaultHealthcheckRetries
+	}
+	return h.Retries
+}
+
+// GetStartPeriod returns the time given to the node to start.
+func (h *HealthcheckConfig) GetStartPeriod() time.Duration {
+	if h == nil {
+		return 0
+	}
+	// the start period is checked by Validate
+	d, _ := time.ParseDuration(h.StartPeriod)
+	return d
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthcheckValidate(t *testing.T) {
+	tests := map[string]struct {
+		hc      *HealthcheckConfig
+		wantErr bool
+	}{
+		"not set":              {hc: nil},
+		"exec":                 {hc: &HealthcheckConfig{Exec: "cat /tmp/ready", Interval: "2s", Retries: 5, StartPeriod: "1m"}},
+		"tcp":                  {hc: &HealthcheckConfig{TCPPort: 57400}},
+		"http":                 {hc: &HealthcheckConfig{HTTP: "http://:8080/healthz"}},
+		"no probe":             {hc: &HealthcheckConfig{Interval: "2s"}, wantErr: true},
+		"two probes":           {hc: &HealthcheckConfig{Exec: "true", TCPPort: 22}, wantErr: true},
+		"invalid port":         {hc: &HealthcheckConfig{TCPPort: 70000}, wantErr: true},
+		"invalid http scheme":  {hc: &HealthcheckConfig{HTTP: "ftp://:21/"}, wantErr: true},
+		"zero interval":        {hc: &HealthcheckConfig{Exec: "true", Interval: "0s"}, wantErr: true},
+		"invalid start period": {hc: &HealthcheckConfig{Exec: "true", StartPeriod: "soon"}, wantErr: true},
+		"negative retries":     {hc: &HealthcheckConfig{Exec: "true", Retries: -1}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.hc.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHealthcheckDefaults(t *testing.T) {
+	var hc *HealthcheckConfig
+	if hc.GetInterval() != DefaultHealthcheckInterval || hc.GetRetries() != DefaultHealthcheckRetries ||
+		hc.GetStartPeriod() != 0 {
+		t.Errorf("unexpected defaults of a nil healthcheck")
+	}
+
+	hc = &HealthcheckConfig{Exec: "true", Interval: "1m", Retries: 10, StartPeriod: "30s"}
+	if hc.GetInterval() != time.Minute || hc.GetRetries() != 10 || hc.GetStartPeriod() != 30*time.Second {
+		t.Errorf("unexpected timings of %+v", hc)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"fmt"
+)
+
+// Stages of the lab lifecycle the hooks are run at.
+const (
+	HookPreDeploy   = "pre-deploy"
+	HookPostCreate  = "post-create"
+	HookPostDeploy  = "post-deploy"
+	HookPreDestroy  = "pre-destroy"
+	HookPostDestroy = "post-destroy"
+)
+
+// Failure policies of the hooks.
+const (
+	// HookOnErrorFail fails the lab operation when the hook fails
+	HookOnErrorFail = "fail"
+	// HookOnErrorWarn logs a warning when the hook fails
+	HookOnErrorWarn = "warn"
+	// HookOnErrorIgnore ignores the failures of the hook
+	HookOnErrorIgnore = "ignore"
+)
+
+// Hook is a command run on the containerlab host at a stage of the lab lifecycle.
+// The hook is set either as a command string, or as a map with the command and the failure policy.
+type Hook struct {
+	// Command is run with sh -c in the directory of the topology file
+	Command string `yaml:"command" json:"command"`
+	// OnError is the failure policy of the hook, fail by default
+	OnError string `yaml:"on-error,omitempty" json:"on-error,omitempty"`
+}
+
+// hook is an alias of Hook used to unmarshal the map form of the hook.
+type hook Hook
+
+// UnmarshalYAML unmarshals the hook in either the string or the map form.
+func (h *Hook) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*h = Hook{Command: s}
+		return nil
+	}
+
+	hk := hook{}
+	if err := unmarshal(&hk); err != nil {
+		return err
+	}
+	*h = Hook(hk)
+
+	return nil
+}
+
+// Validate checks that the hook has a command and a valid failure policy.
+func (h *Hook) Validate() error {
+	if h == nil || h.Command == "" {
+		return fmt.Errorf("hook command is not set")
+	}
+
+	switch h.OnError {
+	case "", HookOnErrorFail, HookOnErrorWarn, HookOnErrorIgnore:
+	default:
+		return fmt.Errorf("invalid on-error policy %q of hook %q, expected one of %s, %s or %s",
+			h.OnError, h.Command, HookOnErrorFail, HookOnErrorWarn, HookOnErrorIgnore)
+	}
+
+	return nil
+}
+
+// GetOnError returns the failure policy of the hook.
+func (h *Hook) GetOnError() string {
+	if h.OnError == "" {
+		return HookOnErrorFail
+	}
+	return h.OnError
+}
+
+// Hooks are the hooks run at the stages of the lab lifecycle.
+type Hooks struct {
+	PreDeploy   []*Hook `yaml:"pre-deploy,omitempty" json:"pre-deploy,omitempty"`
+	PostCreate  []*Hook `yaml:"post-create,omitempty" json:"post-create,omitempty"`
+	PostDeploy  []*Hook `yaml:"post-deploy,omitempty" json:"post-deploy,omitempty"`
+	PreDestroy  []*Hook `yaml:"pre-destroy,omitempty" json:"pre-destroy,omitempty"`
+	PostDestroy []*Hook `yaml:"post-destroy,omitempty" json:"post-destroy,omitempty"`
+}
+
+// Validate checks the hooks of all stages.
+func (h *Hooks) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	for _, stage := range []string{HookPreDeploy, HookPostCreate, HookPostDeploy, HookPreDestroy, HookPostDestroy} {
+		for _, hk := range h.Get(stage) {
+			if err := hk.Validate(); err != nil {
+				return fmt.Errorf("%s: %v", stage, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get returns the hooks of the stage.
+func (h *Hooks) Get(stage string) []*Hook {
+	if h == nil {
+		return nil
+	}
+
+	switch stage {
+	case HookPreDeploy:
+		return h.PreDeploy
+	case HookPostCreate:
+		return h.PostCreate
+	case HookPostDeploy:
+		return h.PostDeploy
+	case HookPreDestroy:
+		return h.PreDestroy
+	case HookPostDestroy:
+		return h.PostDestroy
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+func TestHooksUnmarshal(t *testing.T) {
+	in := `
+pre-deploy:
+  - ip link add tap0 type dummy
+post-destroy:
+  - command: ip link del tap0
+    on-error: ignore
+`
+	h := &Hooks{}
+	if err := yaml.UnmarshalStrict([]byte(in), h); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Hooks{
+		PreDeploy:   []*Hook{{Command: "ip link add tap0 type dummy"}},
+		PostDestroy: []*Hook{{Command: "ip link del tap0", OnError: HookOnErrorIgnore}},
+	}
+	if d := cmp.Diff(want, h); d != "" {
+		t.Errorf("unexpected hooks (-want +got):\n%s", d)
+	}
+
+	if err := yaml.UnmarshalStrict([]byte("pre-deploy: [{cmd: ls}]"), &Hooks{}); err == nil {
+		t.Errorf("expected error on an unknown hook field")
+	}
+}
+
+func TestHooksValidate(t *testing.T) {
+	tests := map[string]struct {
+		h       *Hooks
+		wantErr bool
+	}{
+		"not set":        {h: nil},
+		"string form":    {h: &Hooks{PostDeploy: []*Hook{{Command: "true"}}}},
+		"warn policy":    {h: &Hooks{PreDestroy: []*Hook{{Command: "true", OnError: HookOnErrorWarn}}}},
+		"no command":     {h: &Hooks{PostCreate: []*Hook{{OnError: HookOnErrorIgnore}}}, wantErr: true},
+		"invalid policy": {h: &Hooks{PreDeploy: []*Hook{{Command: "true", OnError: "retry"}}}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.h.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetNodeHooks(t *testing.T) {
+	nodeHook := []*Hook{{Command: "node"}}
+	kindHook := []*Hook{{Command: "kind"}}
+	defaultsHook := []*Hook{{Command: "defaults"}}
+
+	topo := &Topology{
+		Defaults: &NodeDefinition{Hooks: &Hooks{PostDeploy: defaultsHook, PreDestroy: defaultsHook}},
+		Kinds: map[string]*NodeDefinition{
+			"srl": {Hooks: &Hooks{PostDeploy: kindHook}},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"node1": {Kind: "srl", Hooks: &Hooks{PreDeploy: nodeHook}},
+			"node2": {Kind: "linux"},
+		},
+	}
+
+	want := &Hooks{PreDeploy: nodeHook, PostDeploy: kindHook, PreDestroy: defaultsHook}
+	if d := cmp.Diff(want, topo.GetNodeHooks("node1")); d != "" {
+		t.Errorf("unexpected hooks of node1 (-want +got):\n%s", d)
+	}
+
+	want = &Hooks{PostDeploy: defaultsHook, PreDestroy: defaultsHook}
+	if d := cmp.Diff(want, topo.GetNodeHooks("node2")); d != "" {
+		t.Errorf("unexpected hooks of node2 (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,30 @@
+package types
+
+// IPAM defines the pools the addresses of the links and the loopback addresses of the nodes are allocated from.
+type IPAM struct {
+	// P2P pools the link subnets are allocated from, e.g. "10.0.0.0/16 as /31"
+	P2P IPAMPools `yaml:"p2p,omitempty" json:"p2p,omitempty"`
+	// Loopback pools the loopback addresses are allocated from, e.g. "10.255.0.0/24"
+	Loopback IPAMPools `yaml:"loopback,omitempty" json:"loopback,omitempty"`
+}
+
+// IPAMPools is a list of IPv4 and IPv6 pools.
+// A single pool can be set as a string instead of a list.
+type IPAMPools []string
+
+// UnmarshalYAML unmarshals either a single pool or a list of pools.
+func (p *IPAMPools) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*p = IPAMPools{s}
+		return nil
+	}
+
+	var l []string
+	if err := unmarshal(&l); err != nil {
+		return err
+	}
+	*p = l
+
+	return nil
+}
@@ -63,8 +63,16 @@ type NodeDefinition struct {
 	Sysctls map[string]string `yaml:"sysctls,omitempty"`
 	// Extra options, may be kind specific
 	Extras *Extras `yaml:"extras,omitempty"`
-	// List of node names to wait for before satarting this particular node
-	WaitFor []string `yaml:"wait-for,omitempty"`
+	// List of the nodes, and the stages of their lifecycle, to wait for before starting this particular node
+	WaitFor []*WaitFor `yaml:"wait-for,omitempty"`
+	// Name of the host of a distributed lab the node is deployed on
+	Host string `yaml:"host,omitempty"`
+	// TLS certificate of the node issued by the lab root CA
+	Certificate *CertificateConfig `yaml:"certificate,omitempty"`
+	// Healthcheck of the node, which overrides the readiness probe of the node kind
+	Healthcheck *HealthcheckConfig `yaml:"healthcheck,omitempty"`
+	// Hooks run on the containerlab host at the stages of the node lifecycle
+	Hooks *Hooks `yaml:"hooks,omitempty"`
 }
 
 func (n *NodeDefinition) GetKind() string {
@@ -299,13 +307,41 @@ func (n *NodeDefinition) GetSANs() []string {
 	return n.SANs
 }
 
-func (n *NodeDefinition) GetWaitFor() []string {
+func (n *NodeDefinition) GetWaitFor() []*WaitFor {
 	if n == nil {
-		return []string{}
+		return nil
 	}
 	return n.WaitFor
 }
 
+func (n *NodeDefinition) GetHost() string {
+	if n == nil {
+		return ""
+	}
+	return n.Host
+}
+
+func (n *NodeDefinition) GetCertificate() *CertificateConfig {
+	if n == nil {
+		return nil
+	}
+	return n.Certificate
+}
+
+func (n *NodeDefinition) GetHealthcheck() *HealthcheckConfig {
+	if n == nil {
+		return nil
+	}
+	return n.Healthcheck
+}
+
+func (n *NodeDefinition) GetHooks() *Hooks {
+	if n == nil {
+		return nil
+	}
+	return n.Hooks
+}
+
 // ImportEnvs imports all environment variales defined in the shell
 // if __IMPORT_ENVS is set to true.
 func (n *NodeDefinition) ImportEnvs() {
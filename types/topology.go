@@ -1,6 +1,11 @@
 package types
 
 import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
 	"github.com/docker/go-connections/nat"
 	"github.com/srl-labs/containerlab/utils"
 )
@@ -11,6 +16,8 @@ type Topology struct {
 	Kinds    map[string]*NodeDefinition `yaml:"kinds,omitempty"`
 	Nodes    map[string]*NodeDefinition `yaml:"nodes,omitempty"`
 	Links    []*LinkConfig              `yaml:"links,omitempty"`
+	// Hosts a distributed lab is deployed on, keyed by the host name
+	Hosts map[string]*HostConfig `yaml:"hosts,omitempty"`
 }
 
 func NewTopology() *Topology {
@@ -22,10 +29,290 @@ func NewTopology() *Topology {
 	}
 }
 
+// HostConfig is a host of a distributed lab.
+type HostConfig struct {
+	// Address the vxlan tunnels of the links to the nodes of the host are terminated on
+	Address string `yaml:"address"`
+	// Dev is the interface the vxlan tunnels of the host are sourced from,
+	// when it is not set, the interface of the route to the remote host is used
+	Dev string `yaml:"dev,omitempty"`
+}
+
+// Validate checks that the address of the host is set and valid.
+func (h *HostConfig) Validate() error {
+	if h == nil || h.Address == "" {
+		return fmt.Errorf("address is not set")
+	}
+	if _, err := netip.ParseAddr(h.Address); err != nil {
+		return fmt.Errorf("invalid address %q", h.Address)
+	}
+	return nil
+}
+
 type LinkConfig struct {
-	Endpoints []string
+	// Type of the link, veth by default
+	Type      string `yaml:"type,omitempty"`
+	Endpoints []*EndpointConfig
 	Labels    map[string]string      `yaml:"labels,omitempty"`
 	Vars      map[string]interface{} `yaml:"vars,omitempty"`
+	// Mode of the macvlan link, bridge by default
+	Mode string `yaml:"mode,omitempty"`
+	// per-endpoint attributes listed in the order of the endpoints,
+	// an empty value leaves the attribute of the respective endpoint unset
+	IPv4 []string `yaml:"ipv4,omitempty"`
+	IPv6 []string `yaml:"ipv6,omitempty"`
+	MAC  []string `yaml:"mac,omitempty"`
+	MTU  []int    `yaml:"mtu,omitempty"`
+	// netem impairments of the link
+	LinkImpairment `yaml:",inline"`
+}
+
+// EndpointConfig is a link endpoint defined in the topology either in the "node:interface" string form
+// or in the map form, which can carry the attributes of the endpoint.
+type EndpointConfig struct {
+	Node          string `yaml:"node"`
+	Interface     string `yaml:"interface"`
+	EndpointAttrs `yaml:",inline"`
+	// str is the endpoint in the string form as found in the topology
+	str string
+}
+
+// NewEndpointConfig returns the endpoint of node's interface intf.
+func NewEndpointConfig(node, intf string) *EndpointConfig {
+	return &EndpointConfig{Node: node, Interface: intf}
+}
+
+// endpointConfig is the EndpointConfig without the yaml (un)marshalling methods.
+type endpointConfig EndpointConfig
+
+// UnmarshalYAML unmarshals the endpoint in either the string or the map form.
+// The string form is validated with Validate.
+func (e *EndpointConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*e = EndpointConfig{str: s}
+		e.Node, e.Interface, _ = strings.Cut(s, ":")
+		return nil
+	}
+
+	ec := endpointConfig{}
+	if err := unmarshal(&ec); err != nil {
+		return err
+	}
+	*e = EndpointConfig(ec)
+
+	return nil
+}
+
+// MarshalYAML marshals the endpoint in the string form, unless it has attributes.
+func (e *EndpointConfig) MarshalYAML() (interface{}, error) {
+	if e.EndpointAttrs == (EndpointAttrs{}) {
+		return e.String(), nil
+	}
+	return (*endpointConfig)(e), nil
+}
+
+// String returns the endpoint in the "node:interface" form.
+func (e *EndpointConfig) String() string {
+	if e.str != "" {
+		return e.str
+	}
+	return e.Node + ":" + e.Interface
+}
+
+// Validate checks that the node and the interface of the endpoint are set.
+// The interface of the vxlan endpoints holds the tunnel parameters, which are validated with ParseVxlanParams.
+func (e *EndpointConfig) Validate() error {
+	if e.Node == VxlanNodeName {
+		if _, err := ParseVxlanParams(e.Interface); err != nil {
+			return fmt.Errorf("endpoint %q: %v", e, err)
+		}
+		return nil
+	}
+	if e.str != "" && strings.Count(e.str, ":") != 1 {
+		return fmt.Errorf("endpoint %q has wrong syntax, expected \"node:interface\"", e.str)
+	}
+	if e.Node == "" {
+		return fmt.Errorf("endpoint %q has no node", e)
+	}
+	if e.Interface == "" {
+		return fmt.Errorf("endpoint %q has no interface", e)
+	}
+	if len(e.Interface) > 15 {
+		return fmt.Errorf("interface %q name exceeds maximum length of 15 characters", e.Interface)
+	}
+	return nil
+}
+
+// EndpointAttrs are the attributes of a link endpoint set in the topology.
+type EndpointAttrs struct {
+	// IPv4 and IPv6 addresses with the prefix length
+	IPv4 string `yaml:"ipv4,omitempty"`
+	IPv6 string `yaml:"ipv6,omitempty"`
+	MAC  string `yaml:"mac,omitempty"`
+	MTU  int    `yaml:"mtu,omitempty"`
+}
+
+// Validate checks that the attribute values are valid.
+func (a *EndpointAttrs) Validate() error {
+	if a.IPv4 != "" {
+		if p, err := netip.ParsePrefix(a.IPv4); err != nil || !p.Addr().Is4() {
+			return fmt.Errorf("invalid ipv4 address %q, expected address with prefix length", a.IPv4)
+		}
+	}
+	if a.IPv6 != "" {
+		if p, err := netip.ParsePrefix(a.IPv6); err != nil || !p.Addr().Is6() {
+			return fmt.Errorf("invalid ipv6 address %q, expected address with prefix length", a.IPv6)
+		}
+	}
+	if a.MAC != "" {
+		if _, err := net.ParseMAC(a.MAC); err != nil {
+			return fmt.Errorf("invalid mac address %q: %v", a.MAC, err)
+		}
+	}
+	if a.MTU != 0 && (a.MTU < 68 || a.MTU > 65535) {
+		return fmt.Errorf("mtu must be in the range of 68-65535, got %d", a.MTU)
+	}
+	return nil
+}
+
+// EndpointAttrs returns the attributes of the i-th endpoint of the link,
+// which are set either on the endpoint in the map form or listed on the link.
+func (l *LinkConfig) EndpointAttrs(i int) EndpointAttrs {
+	var a EndpointAttrs
+	if i < len(l.Endpoints) && l.Endpoints[i] != nil {
+		a = l.Endpoints[i].EndpointAttrs
+	}
+	if i < len(l.IPv4) && a.IPv4 == "" {
+		a.IPv4 = l.IPv4[i]
+	}
+	if i < len(l.IPv6) && a.IPv6 == "" {
+		a.IPv6 = l.IPv6[i]
+	}
+	if i < len(l.MAC) && a.MAC == "" {
+		a.MAC = l.MAC[i]
+	}
+	if i < len(l.MTU) && a.MTU == 0 {
+		a.MTU = l.MTU[i]
+	}
+	return a
+}
+
+// ValidateEndpointAttrs checks that the per-endpoint attributes are set either on the endpoints
+// or with the attribute lists of the link, which list a value for every endpoint.
+func (l *LinkConfig) ValidateEndpointAttrs() error {
+	lens := map[string]int{"ipv4": len(l.IPv4), "ipv6": len(l.IPv6), "mac": len(l.MAC), "mtu": len(l.MTU)}
+	lists := false
+	for name, n := range lens {
+		if n != 0 && n != len(l.Endpoints) {
+			return fmt.Errorf("%s must list a value for each of the %d endpoints, found %d",
+				name, len(l.Endpoints), n)
+		}
+		lists = lists || n != 0
+	}
+
+	if !lists {
+		return nil
+	}
+	for _, e := range l.Endpoints {
+		if e != nil && e.EndpointAttrs != (EndpointAttrs{}) {
+			return fmt.Errorf("endpoint %q has attributes set while the link has the ipv4, ipv6, mac or mtu lists, "+
+				"the attributes should be set either on the endpoints or with the lists", e)
+		}
+	}
+	return nil
+}
+
+// LinkType returns the type of the link, which is vxlan for the links with a vxlan endpoint
+// and veth for other links without the type set.
+func (l *LinkConfig) LinkType() string {
+	switch {
+	case l.Type != "":
+		return l.Type
+	case l.VxlanEndpointIdx() >= 0:
+		return LinkTypeVxlan
+	}
+	return LinkTypeVeth
+}
+
+// ValidateType checks that the link type is supported and the link has the endpoints the type requires:
+// two endpoints of veth links, a node endpoint and a host interface of macvlan and host-device links,
+// a node endpoint and a vxlan endpoint of vxlan links, a single node endpoint of dummy links.
+func (l *LinkConfig) ValidateType() error {
+	t := l.LinkType()
+	if l.Mode != "" && t != LinkTypeMacvlan {
+		return fmt.Errorf("mode is only supported by %s links", LinkTypeMacvlan)
+	}
+	if t != LinkTypeVxlan && l.VxlanEndpointIdx() >= 0 {
+		return fmt.Errorf("%s endpoints are only supported by %s links", VxlanNodeName, LinkTypeVxlan)
+	}
+
+	switch t {
+	case LinkTypeVeth:
+		if len(l.Endpoints) != 2 {
+			return fmt.Errorf("expected 2 endpoints, found %d", len(l.Endpoints))
+		}
+	case LinkTypeMacvlan, LinkTypeHostDevice:
+		if !l.hasNodeAndSpecialEndpoint("host") {
+			return fmt.Errorf("%s link expects a node endpoint and a host interface endpoint, e.g. [\"node:eth1\", \"host:eth0\"]", t)
+		}
+		if l.EndpointAttrs(l.HostEndpointIdx()) != (EndpointAttrs{}) {
+			return fmt.Errorf("attributes of the host interface of %s links are not supported", t)
+		}
+	case LinkTypeVxlan:
+		if !l.hasNodeAndSpecialEndpoint(VxlanNodeName) {
+			return fmt.Errorf("%s link expects a node endpoint and a vxlan endpoint, e.g. [\"node:eth1\", \"vxlan:remote=10.0.0.2\"]", t)
+		}
+		if l.EndpointAttrs(l.VxlanEndpointIdx()) != (EndpointAttrs{}) {
+			return fmt.Errorf("attributes of the vxlan endpoint are not supported")
+		}
+	case LinkTypeDummy:
+		if len(l.Endpoints) != 1 {
+			return fmt.Errorf("%s link expects a single endpoint, found %d", t, len(l.Endpoints))
+		}
+	default:
+		return fmt.Errorf("unsupported link type %q, expected one of %s, %s, %s, %s or %s",
+			t, LinkTypeVeth, LinkTypeMacvlan, LinkTypeHostDevice, LinkTypeVxlan, LinkTypeDummy)
+	}
+
+	switch l.Mode {
+	case "", MacvlanModeBridge, MacvlanModePrivate, MacvlanModeVepa, MacvlanModePassthru:
+	default:
+		return fmt.Errorf("unsupported macvlan mode %q, expected one of %s, %s, %s or %s",
+			l.Mode, MacvlanModeBridge, MacvlanModePrivate, MacvlanModeVepa, MacvlanModePassthru)
+	}
+
+	return nil
+}
+
+// hasNodeAndSpecialEndpoint returns true when the link has two endpoints, one of which refers to the special node
+// and the other one to a regular node.
+func (l *LinkConfig) hasNodeAndSpecialEndpoint(special string) bool {
+	i := l.endpointIdx(special)
+	if len(l.Endpoints) != 2 || i < 0 || l.Endpoints[1-i] == nil {
+		return false
+	}
+	peer := l.Endpoints[1-i].Node
+	return peer != "host" && peer != VxlanNodeName
+}
+
+// HostEndpointIdx returns the index of the first endpoint referring to the host interface or -1 if there is none.
+func (l *LinkConfig) HostEndpointIdx() int {
+	return l.endpointIdx("host")
+}
+
+// VxlanEndpointIdx returns the index of the first vxlan endpoint or -1 if there is none.
+func (l *LinkConfig) VxlanEndpointIdx() int {
+	return l.endpointIdx(VxlanNodeName)
+}
+
+func (l *LinkConfig) endpointIdx(node string) int {
+	for i, e := range l.Endpoints {
+		if e != nil && e.Node == node {
+			return i
+		}
+	}
+	return -1
 }
 
 func (t *Topology) GetDefaults() *NodeDefinition {
@@ -463,15 +750,130 @@ func (t *Topology) GetNodeExtras(name string) *Extras {
 }
 
 // GetWaitFor return the wait-for configuration for the given node.
-func (t *Topology) GetWaitFor(name string) []string {
+// The dependencies of the node take precedence over the kind ones on the same node and stage.
+func (t *Topology) GetWaitFor(name string) []*WaitFor {
 	if ndef, ok := t.Nodes[name]; ok {
-		return utils.MergeStringSlices(
-			t.GetKind(t.GetNodeKind(name)).GetWaitFor(),
-			ndef.GetWaitFor())
+		return mergeWaitFor(
+			ndef.GetWaitFor(),
+			t.GetKind(t.GetNodeKind(name)).GetWaitFor())
 	}
 	return nil
 }
 
+// GetNodeHost returns the name of the host of a distributed lab the given node is deployed on.
+func (t *Topology) GetNodeHost(name string) string {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetHost() != "" {
+			return ndef.GetHost()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetHost() != "" {
+			return t.GetKind(t.GetNodeKind(name)).GetHost()
+		}
+		return t.GetDefaults().GetHost()
+	}
+	return ""
+}
+
+// GetNodeHealthcheck returns the healthcheck of the given node set on the node, kind or defaults level.
+func (t *Topology) GetNodeHealthcheck(name string) *HealthcheckConfig {
+	if ndef, ok := t.Nodes[name]; ok {
+		if ndef.GetHealthcheck() != nil {
+			return ndef.GetHealthcheck()
+		}
+		if t.GetKind(t.GetNodeKind(name)).GetHealthcheck() != nil {
+			return t.GetKind(t.GetNodeKind(name)).GetHealthcheck()
+		}
+		return t.GetDefaults().GetHealthcheck()
+	}
+	return nil
+}
+
+// GetNodeHooks returns the hooks of the given node.
+// The hooks of each stage are taken from the first of the node, kind and defaults levels that sets them.
+func (t *Topology) GetNodeHooks(name string) *Hooks {
+	ndef, ok := t.Nodes[name]
+	if !ok {
+		return nil
+	}
+
+	levels := []*Hooks{
+		ndef.GetHooks(),
+		t.GetKind(t.GetNodeKind(name)).GetHooks(),
+		t.GetDefaults().GetHooks(),
+	}
+
+	var hooks *Hooks
+	for _, h := range levels {
+		if h == nil {
+			continue
+		}
+		if hooks == nil {
+			hooks = new(Hooks)
+		}
+		if hooks.PreDeploy == nil {
+			hooks.PreDeploy = h.PreDeploy
+		}
+		if hooks.PostCreate == nil {
+			hooks.PostCreate = h.PostCreate
+		}
+		if hooks.PostDeploy == nil {
+			hooks.PostDeploy = h.PostDeploy
+		}
+		if hooks.PreDestroy == nil {
+			hooks.PreDestroy = h.PreDestroy
+		}
+		if hooks.PostDestroy == nil {
+			hooks.PostDestroy = h.PostDestroy
+		}
+	}
+
+	return hooks
+}
+
+// GetNodeCertificate returns the certificate configuration for the given node.
+// The issue flag, the path, the key and the subject set on the node level take precedence
+// over the kind and the defaults levels, while the SANs of all levels are merged.
+func (t *Topology) GetNodeCertificate(name string) *CertificateConfig {
+	ndef, ok := t.Nodes[name]
+	if !ok {
+		return nil
+	}
+
+	levels := []*CertificateConfig{
+		ndef.GetCertificate(),
+		t.GetKind(t.GetNodeKind(name)).GetCertificate(),
+		t.GetDefaults().GetCertificate(),
+	}
+
+	var cert *CertificateConfig
+	for _, c := range levels {
+		if c == nil {
+			continue
+		}
+		if cert == nil {
+			cert = new(CertificateConfig)
+		}
+		if cert.Issue == nil {
+			cert.Issue = c.Issue
+		}
+		if cert.Path == "" {
+			cert.Path = c.Path
+		}
+		if cert.KeyAlgorithm == "" {
+			cert.KeyAlgorithm = c.KeyAlgorithm
+		}
+		if cert.KeySize == 0 {
+			cert.KeySize = c.KeySize
+		}
+		if cert.Subject == nil {
+			cert.Subject = c.Subject
+		}
+		cert.SANs = utils.MergeStringSlices(cert.SANs, c.SANs)
+	}
+
+	return cert
+}
+
 func (t *Topology) ImportEnvs() {
 	t.Defaults.ImportEnvs()
 
@@ -1,9 +1,11 @@
 package types
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
 )
 
 func boolptr(b bool) *bool {
@@ -485,3 +487,200 @@ func TestGetNodeAutoRemove(t *testing.T) {
 		}
 	}
 }
+
+func TestEndpointConfigUnmarshal(t *testing.T) {
+	in := `
+endpoints:
+  - r1:e1-1
+  - node: r2
+    interface: eth1
+    mac: aa:c1:ab:00:00:01
+    mtu: 1500
+  - node: r3
+    interface: eth1
+    ipv4: 10.0.0.1/31
+  - r4
+`
+	l := &LinkConfig{}
+	if err := yaml.UnmarshalStrict([]byte(in), l); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := []string{l.Endpoints[0].String(), l.Endpoints[1].String()}; !cmp.Equal(got, []string{"r1:e1-1", "r2:eth1"}) {
+		t.Errorf("unexpected endpoints %q", got)
+	}
+
+	want := []EndpointAttrs{{}, {MAC: "aa:c1:ab:00:00:01", MTU: 1500}, {IPv4: "10.0.0.1/31"}}
+	for i, w := range want {
+		if d := cmp.Diff(w, l.EndpointAttrs(i)); d != "" {
+			t.Errorf("endpoint %d attributes mismatch (-want +got):\n%s", i, d)
+		}
+	}
+
+	for i, wantErr := range []bool{false, false, false, true} {
+		if err := l.Endpoints[i].Validate(); (err != nil) != wantErr {
+			t.Errorf("endpoint %s: expected error %v, got %v", l.Endpoints[i], wantErr, err)
+		}
+	}
+
+	// unknown endpoint attributes are rejected
+	if err := yaml.UnmarshalStrict([]byte("endpoints: [{node: r1, intf: eth1}]"), &LinkConfig{}); err == nil {
+		t.Error("expected error for unknown endpoint field")
+	}
+
+	// endpoints without attributes are marshalled in the string form
+	b, err := yaml.Marshal(l.Endpoints[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "- r1:e1-1\n- node: r2\n") {
+		t.Errorf("unexpected marshalled endpoints:\n%s", b)
+	}
+}
+
+func TestValidateEndpointAttrs(t *testing.T) {
+	tests := map[string]struct {
+		attrs   EndpointAttrs
+		wantErr bool
+	}{
+		"valid": {
+			attrs: EndpointAttrs{IPv4: "10.0.0.0/31", IPv6: "2001:db8::/127", MAC: "aa:c1:ab:00:00:01", MTU: 1500},
+		},
+		"ipv4 without prefix length": {
+			attrs:   EndpointAttrs{IPv4: "10.0.0.0"},
+			wantErr: true,
+		},
+		"ipv6 address as ipv4": {
+			attrs:   EndpointAttrs{IPv4: "2001:db8::/127"},
+			wantErr: true,
+		},
+		"invalid mac": {
+			attrs:   EndpointAttrs{MAC: "aa:c1:ab"},
+			wantErr: true,
+		},
+		"mtu out of range": {
+			attrs:   EndpointAttrs{MTU: 65536},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.attrs.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	l := &LinkConfig{
+		Endpoints: []*EndpointConfig{NewEndpointConfig("n1", "eth1"), NewEndpointConfig("n2", "eth1")},
+		MTU:       []int{1500},
+	}
+	if err := l.ValidateEndpointAttrs(); err == nil {
+		t.Error("expected error for the mtu missing a value of an endpoint")
+	}
+
+	lists := &LinkConfig{
+		Endpoints: []*EndpointConfig{NewEndpointConfig("n1", "eth1"), NewEndpointConfig("n2", "eth1")},
+		IPv4:      []string{"10.0.0.0/31", "10.0.0.1/31"},
+		MTU:       []int{1500, 1500},
+	}
+	if err := lists.ValidateEndpointAttrs(); err != nil {
+		t.Errorf("expected no error for the attributes set with the lists, got %v", err)
+	}
+
+	// the attributes are set either on the endpoints or with the lists
+	mixed := &LinkConfig{
+		Endpoints: []*EndpointConfig{
+			{Node: "n1", Interface: "eth1", EndpointAttrs: EndpointAttrs{MAC: "aa:c1:ab:00:00:01"}},
+			NewEndpointConfig("n2", "eth1"),
+		},
+		IPv4: []string{"10.0.0.0/31", "10.0.0.1/31"},
+	}
+	if err := mixed.ValidateEndpointAttrs(); err == nil {
+		t.Error("expected error for the attributes set both on an endpoint and with the lists")
+	}
+}
+
+func TestValidateLinkType(t *testing.T) {
+	ep := NewEndpointConfig
+	tests := map[string]struct {
+		link    LinkConfig
+		wantErr bool
+	}{
+		"veth by default": {
+			link: LinkConfig{Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("n2", "eth1")}},
+		},
+		"veth with a single endpoint": {
+			link:    LinkConfig{Type: LinkTypeVeth, Endpoints: []*EndpointConfig{ep("n1", "eth1")}},
+			wantErr: true,
+		},
+		"macvlan with host interface first": {
+			link: LinkConfig{
+				Type: LinkTypeMacvlan, Mode: MacvlanModePrivate,
+				Endpoints: []*EndpointConfig{ep("host", "eth0"), ep("n1", "eth1")},
+			},
+		},
+		"macvlan without host interface": {
+			link:    LinkConfig{Type: LinkTypeMacvlan, Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("n2", "eth1")}},
+			wantErr: true,
+		},
+		"macvlan with invalid mode": {
+			link: LinkConfig{
+				Type: LinkTypeMacvlan, Mode: "source",
+				Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("host", "eth0")},
+			},
+			wantErr: true,
+		},
+		"host-device with attributes of host interface": {
+			link: LinkConfig{
+				Type:      LinkTypeHostDevice,
+				Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("host", "enp3s0")},
+				MTU:       []int{0, 1500},
+			},
+			wantErr: true,
+		},
+		"host-device with mode": {
+			link: LinkConfig{
+				Type: LinkTypeHostDevice, Mode: MacvlanModeBridge,
+				Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("host", "enp3s0")},
+			},
+			wantErr: true,
+		},
+		"vxlan by the endpoint": {
+			link: LinkConfig{Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("vxlan", "remote=10.0.0.2,vni=10")}},
+		},
+		"vxlan endpoint of veth link": {
+			link: LinkConfig{
+				Type:      LinkTypeVeth,
+				Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("vxlan", "remote=10.0.0.2")},
+			},
+			wantErr: true,
+		},
+		"vxlan to host": {
+			link:    LinkConfig{Endpoints: []*EndpointConfig{ep("host", "eth1"), ep("vxlan", "remote=10.0.0.2")}},
+			wantErr: true,
+		},
+		"dummy": {
+			link: LinkConfig{Type: LinkTypeDummy, Endpoints: []*EndpointConfig{ep("n1", "eth1")}},
+		},
+		"dummy with two endpoints": {
+			link:    LinkConfig{Type: LinkTypeDummy, Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("n2", "eth1")}},
+			wantErr: true,
+		},
+		"unsupported type": {
+			link:    LinkConfig{Type: "ipip", Endpoints: []*EndpointConfig{ep("n1", "eth1"), ep("n2", "eth1")}},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.link.ValidateType()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
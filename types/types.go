@@ -7,6 +7,7 @@ package types
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/docker/go-connections/nat"
@@ -15,20 +16,125 @@ import (
 	"github.com/srl-labs/containerlab/virt"
 )
 
+// link types
+const (
+	// LinkTypeVeth is a veth pair between two nodes, or a node and a bridge or the host.
+	LinkTypeVeth = "veth"
+	// LinkTypeMacvlan is a macvlan interface of a node on top of a host interface.
+	LinkTypeMacvlan = "macvlan"
+	// LinkTypeHostDevice is a host interface moved to a node.
+	LinkTypeHostDevice = "host-device"
+	// LinkTypeVxlan is a veth pair between a node and the host, which host end is stitched to a VxLAN tunnel.
+	LinkTypeVxlan = "vxlan"
+	// LinkTypeDummy is a dummy interface of a node, which is not connected anywhere.
+	LinkTypeDummy = "dummy"
+)
+
+// macvlan modes
+const (
+	MacvlanModeBridge   = "bridge"
+	MacvlanModePrivate  = "private"
+	MacvlanModeVepa     = "vepa"
+	MacvlanModePassthru = "passthru"
+)
+
 // Link is a struct that contains the information of a link between 2 containers.
+// Links of other types than veth have the node endpoint as A, while B is the host interface
+// of the macvlan and host-device links, the vxlan endpoint of the vxlan links and is nil for the dummy links.
 type Link struct {
+	Type   string
 	A      *Endpoint
 	B      *Endpoint
 	MTU    int
 	Labels map[string]string
 	Vars   map[string]interface{}
+	// Impairment is the netem impairment applied to both ends of the link
+	Impairment *LinkImpairment
+	// MacvlanMode is the mode of the macvlan link
+	MacvlanMode string
+	// Vxlan holds the tunnel parameters of the vxlan link
+	Vxlan *VxlanParams
 }
 
 func (link *Link) String() string {
+	if link.B == nil {
+		return fmt.Sprintf("link [%s:%s]", link.A.Node.ShortName, link.A.EndpointName)
+	}
 	return fmt.Sprintf("link [%s:%s, %s:%s]", link.A.Node.ShortName,
 		link.A.EndpointName, link.B.Node.ShortName, link.B.EndpointName)
 }
 
+// IsVeth returns true when the link is a veth pair.
+func (link *Link) IsVeth() bool {
+	return link.Type == "" || link.Type == LinkTypeVeth
+}
+
+// Endpoints returns the endpoints of the link which interfaces are created by containerlab,
+// which excludes the host interface of the macvlan and host-device links.
+func (link *Link) Endpoints() []*Endpoint {
+	if !link.IsVeth() {
+		return []*Endpoint{link.A}
+	}
+	return []*Endpoint{link.A, link.B}
+}
+
+// LinkImpairment defines the network impairments (netem) applied to a link.
+type LinkImpairment struct {
+	// delay and jitter in the time.Duration string format, e.g. 10ms
+	Delay  string `yaml:"delay,omitempty" json:"delay,omitempty"`
+	Jitter string `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+	// packet loss, corruption and duplication in percent
+	Loss       float64 `yaml:"loss,omitempty" json:"loss,omitempty"`
+	Corruption float64 `yaml:"corruption,omitempty" json:"corruption,omitempty"`
+	Duplicate  float64 `yaml:"duplicate,omitempty" json:"duplicate,omitempty"`
+	// rate limit in kbit/s
+	Rate uint64 `yaml:"rate,omitempty" json:"rate,omitempty"`
+}
+
+// IsEmpty returns true when no impairment is set.
+func (li *LinkImpairment) IsEmpty() bool {
+	return li == nil || *li == LinkImpairment{}
+}
+
+// DelayDuration returns the parsed delay and jitter values.
+func (li *LinkImpairment) DelayDuration() (delay, jitter time.Duration, err error) {
+	if li.Delay != "" {
+		if delay, err = time.ParseDuration(li.Delay); err != nil {
+			return 0, 0, fmt.Errorf("failed to parse delay %q: %v", li.Delay, err)
+		}
+	}
+	if li.Jitter != "" {
+		if jitter, err = time.ParseDuration(li.Jitter); err != nil {
+			return 0, 0, fmt.Errorf("failed to parse jitter %q: %v", li.Jitter, err)
+		}
+	}
+	return delay, jitter, nil
+}
+
+// Validate checks that the impairment values are valid.
+func (li *LinkImpairment) Validate() error {
+	delay, jitter, err := li.DelayDuration()
+	if err != nil {
+		return err
+	}
+	if delay < 0 || jitter < 0 {
+		return fmt.Errorf("delay and jitter must not be negative")
+	}
+	if jitter > 0 && delay == 0 {
+		return fmt.Errorf("jitter can only be set together with delay")
+	}
+	for name, v := range map[string]float64{
+		"loss":       li.Loss,
+		"corruption": li.Corruption,
+		"duplicate":  li.Duplicate,
+	} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("%s must be a percentage in the range of 0-100, got %v", name, v)
+		}
+	}
+	return nil
+}
+
 // Endpoint is a struct that contains information of a link endpoint.
 type Endpoint struct {
 	Node *NodeConfig
@@ -36,6 +142,11 @@ type Endpoint struct {
 	EndpointName string
 	// mac address
 	MAC string
+	// IPv4 and IPv6 addresses with the prefix length, set in the topology or allocated by IPAM
+	IPv4 string
+	IPv6 string
+	// MTU of the interface, the link MTU is used when not set
+	MTU int
 }
 
 // MgmtNet struct defines the management network options.
@@ -112,6 +223,9 @@ type NodeConfig struct {
 	TLSKey               string `json:"-"` // Do not marshal into JSON - highly sensitive data
 	TLSAnchor            string `json:"tls-anchor,omitempty"`
 	NSPath               string `json:"nspath,omitempty"` // network namespace path for this node
+	// LoopbackIPv4 and LoopbackIPv6 are the loopback addresses allocated by IPAM
+	LoopbackIPv4 string `json:"loopback-ipv4,omitempty"`
+	LoopbackIPv6 string `json:"loopback-ipv6,omitempty"`
 	// list of ports to publish with mysocketctl
 	Publish []string `json:"publish,omitempty"`
 	// Extra /etc/hosts entries for all nodes.
@@ -121,6 +235,12 @@ type NodeConfig struct {
 	Endpoints []Endpoint `json:"-"`
 	// List of Subject Alternative Names (SAN) to be added to the node's TLS certificate
 	SANs []string `json:"SANs,omitempty"`
+	// TLS certificate of the node issued by the lab root CA
+	Certificate *CertificateConfig `json:"certificate,omitempty"`
+	// Healthcheck of the node, which overrides the readiness probe of the node kind
+	Healthcheck *HealthcheckConfig `json:"healthcheck,omitempty"`
+	// Hooks run on the containerlab host at the stages of the node lifecycle
+	Hooks *Hooks `json:"hooks,omitempty"`
 	// Ignite sandbox and kernel imageNames
 	Sandbox string `json:"sandbox,omitempty"`
 	Kernel  string `json:"kernel,omitempty"`
@@ -135,8 +255,8 @@ type NodeConfig struct {
 	DeploymentStatus string `json:"deployment-status,omitempty"`
 
 	// Extras
-	Extras  *Extras  `json:"extras,omitempty"` // Extra node parameters
-	WaitFor []string `json:"wait-for,omitempty"`
+	Extras  *Extras    `json:"extras,omitempty"` // Extra node parameters
+	WaitFor []*WaitFor `json:"wait-for,omitempty"`
 }
 
 type HostRequirements struct {
@@ -321,4 +441,14 @@ func (mse *MySocketIoEntry) getContainerName() (string, error) {
 type LabData struct {
 	Containers []ContainerDetails `json:"containers"`
 	MySocketIo []*MySocketIoEntry `json:"mysocketio"`
+	Vxlan      []*VxlanTunnel     `json:"vxlan,omitempty"`
+}
+
+// VxlanTunnel describes the tunnel of a vxlan link.
+type VxlanTunnel struct {
+	Node      string `json:"node"`
+	Interface string `json:"interface"`
+	Remote    string `json:"remote"`
+	VNI       int    `json:"vni"`
+	UDPPort   int    `json:"udp_port"`
 }
@@ -70,3 +70,38 @@ func TestMySocketIoEntry_isClabEntry(t *testing.T) {
 		}
 	}
 }
+
+func TestLinkImpairmentValidate(t *testing.T) {
+	tests := map[string]struct {
+		li      LinkImpairment
+		wantErr bool
+	}{
+		"valid": {
+			li: LinkImpairment{Delay: "10ms", Jitter: "2ms", Loss: 1.5, Rate: 10000, Corruption: 0.1, Duplicate: 100},
+		},
+		"bad_delay": {
+			li:      LinkImpairment{Delay: "10"},
+			wantErr: true,
+		},
+		"jitter_without_delay": {
+			li:      LinkImpairment{Jitter: "2ms"},
+			wantErr: true,
+		},
+		"loss_out_of_range": {
+			li:      LinkImpairment{Loss: 101},
+			wantErr: true,
+		},
+		"negative_duplicate": {
+			li:      LinkImpairment{Duplicate: -1},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.li.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
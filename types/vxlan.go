@@ -0,0 +1,85 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+const (
+	// VxlanNodeName is the reserved node name of the remote end of the VxLAN stitched links.
+	VxlanNodeName = "vxlan"
+	// DefaultVxlanUDPPort is the IANA assigned VxLAN UDP port.
+	DefaultVxlanUDPPort = 4789
+	// MaxVxlanVNI is the highest VxLAN network identifier.
+	MaxVxlanVNI = 1<<24 - 1
+)
+
+// VxlanParams are the tunnel parameters of the remote end of a VxLAN stitched link,
+// which is defined in the topology as "vxlan:remote=<address>[,vni=<id>][,udp-port=<port>][,dev=<interface>]".
+type VxlanParams struct {
+	// Remote is the address of the remote VTEP
+	Remote netip.Addr
+	// VNI is the VxLAN network identifier, 0 when it is to be allocated automatically
+	VNI     int
+	UDPPort int
+	// Dev is the interface the tunnel is sourced from,
+	// when it is not set, the interface of the route to the remote VTEP is used
+	Dev string
+}
+
+// ParseVxlanParams parses the tunnel parameters in the "remote=<address>[,vni=<id>][,udp-port=<port>][,dev=<interface>]" format.
+func ParseVxlanParams(s string) (*VxlanParams, error) {
+	p := &VxlanParams{UDPPort: DefaultVxlanUDPPort}
+
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || v == "" {
+			return nil, fmt.Errorf("invalid vxlan parameter %q, expected key=value", kv)
+		}
+
+		var err error
+		switch k {
+		case "remote":
+			if p.Remote, err = netip.ParseAddr(v); err != nil {
+				return nil, fmt.Errorf("invalid vxlan remote address %q", v)
+			}
+		case "vni":
+			if p.VNI, err = strconv.Atoi(v); err != nil || p.VNI < 1 || p.VNI > MaxVxlanVNI {
+				return nil, fmt.Errorf("invalid vxlan vni %q, expected a number in the range of 1-%d", v, MaxVxlanVNI)
+			}
+		case "udp-port":
+			if p.UDPPort, err = strconv.Atoi(v); err != nil || p.UDPPort < 1 || p.UDPPort > 65535 {
+				return nil, fmt.Errorf("invalid vxlan udp-port %q, expected a number in the range of 1-65535", v)
+			}
+		case "dev":
+			p.Dev = v
+		default:
+			return nil, fmt.Errorf("unknown vxlan parameter %q, expected one of remote, vni, udp-port or dev", k)
+		}
+	}
+
+	if !p.Remote.IsValid() {
+		return nil, fmt.Errorf("vxlan remote address is not set")
+	}
+
+	return p, nil
+}
+
+// String returns the parameters in the format accepted by ParseVxlanParams.
+func (p *VxlanParams) String() string {
+	s := "remote=" + p.Remote.String()
+	if p.VNI != 0 {
+		s += ",vni=" + strconv.Itoa(p.VNI)
+	}
+	s += ",udp-port=" + strconv.Itoa(p.UDPPort)
+	if p.Dev != "" {
+		s += ",dev=" + p.Dev
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import "testing"
+
+func TestParseVxlanParams(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		"all parameters":     {in: "remote=10.0.0.2,vni=100,udp-port=14789,dev=eth1", want: "remote=10.0.0.2,vni=100,udp-port=14789,dev=eth1"},
+		"defaults":           {in: "remote=2001:db8::2", want: "remote=2001:db8::2,udp-port=4789"},
+		"missing remote":     {in: "vni=100", wantErr: true},
+		"invalid remote":     {in: "remote=10.0.0", wantErr: true},
+		"vni out of range":   {in: "remote=10.0.0.2,vni=16777216", wantErr: true},
+		"invalid udp port":   {in: "remote=10.0.0.2,udp-port=0", wantErr: true},
+		"unknown parameter":  {in: "remote=10.0.0.2,ttl=10", wantErr: true},
+		"parameter no value": {in: "remote=10.0.0.2,vni", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := ParseVxlanParams(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if err == nil && p.String() != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, p)
+			}
+		})
+	}
+}
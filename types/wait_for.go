@@ -0,0 +1,145 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Stages of the node lifecycle the dependent nodes wait for.
+const (
+	// WaitForStageCreate is reached when the node is created
+	WaitForStageCreate = "create"
+	// WaitForStageHealthy is reached when the node passes its healthcheck or the readiness probe of its kind
+	WaitForStageHealthy = "healthy"
+	// WaitForStagePostDeploy is reached when the node finished its post-deploy actions
+	WaitForStagePostDeploy = "post-deploy"
+)
+
+// WaitForStages are the stages of the node lifecycle the dependent nodes wait for.
+var WaitForStages = []string{WaitForStageCreate, WaitForStageHealthy, WaitForStagePostDeploy}
+
+// DefaultWaitForHealthyTimeout is the time the nodes wait for a node to become healthy, unless set otherwise,
+// so that the nodes which never become healthy don't hold back the deployment forever.
+const DefaultWaitForHealthyTimeout = 10 * time.Minute
+
+// WaitFor is a dependency of a node on another node reaching a stage of its lifecycle.
+// The dependency is set either as a node name, which waits for the node to be created,
+// or as a map with the node, the stage and the timeout of the wait.
+type WaitFor struct {
+	Node string `yaml:"node" json:"node"`
+	// Stage of the node to wait for, create by default
+	Stage string `yaml:"stage,omitempty" json:"stage,omitempty"`
+	// Timeout of the wait in the Go duration format, e.g. 5m.
+	// The creation and the post-deploy stages are waited for without a timeout by default
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// waitFor is an alias of WaitFor used to unmarshal the map form of the dependency.
+type waitFor WaitFor
+
+// UnmarshalYAML unmarshals the dependency in either the string or the map form.
+func (w *WaitFor) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*w = WaitFor{Node: s}
+		return nil
+	}
+
+	wf := waitFor{}
+	if err := unmarshal(&wf); err != nil {
+		return err
+	}
+	*w = WaitFor(wf)
+
+	return nil
+}
+
+// UnmarshalJSON unmarshals the dependency in either the string or the map form,
+// the string form is found in the state files of the labs deployed by the previous versions.
+func (w *WaitFor) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*w = WaitFor{Node: s}
+		return nil
+	}
+
+	wf := waitFor{}
+	if err := json.Unmarshal(b, &wf); err != nil {
+		return err
+	}
+	*w = WaitFor(wf)
+
+	return nil
+}
+
+// Validate checks that the dependency has a node, a known stage and a valid timeout.
+func (w *WaitFor) Validate() error {
+	if w == nil || w.Node == "" {
+		return fmt.Errorf("wait-for node is not set")
+	}
+
+	switch w.Stage {
+	case "", WaitForStageCreate, WaitForStageHealthy, WaitForStagePostDeploy:
+	default:
+		return fmt.Errorf("invalid wait-for stage %q of node %q, expected one of %s, %s or %s",
+			w.Stage, w.Node, WaitForStageCreate, WaitForStageHealthy, WaitForStagePostDeploy)
+	}
+
+	if w.Timeout != "" {
+		d, err := time.ParseDuration(w.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid wait-for timeout %q of node %q: %v", w.Timeout, w.Node, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("wait-for timeout of node %q must be positive", w.Node)
+		}
+	}
+
+	return nil
+}
+
+// GetStage returns the stage of the node to wait for.
+func (w *WaitFor) GetStage() string {
+	if w.Stage == "" {
+		return WaitForStageCreate
+	}
+	return w.Stage
+}
+
+// GetTimeout returns the timeout of the wait, zero means no timeout.
+// The timeout is expected to be validated.
+func (w *WaitFor) GetTimeout() time.Duration {
+	if w.Timeout != "" {
+		d, _ := time.ParseDuration(w.Timeout)
+		return d
+	}
+	if w.GetStage() == WaitForStageHealthy {
+		return DefaultWaitForHealthyTimeout
+	}
+	return 0
+}
+
+// mergeWaitFor merges the dependencies, the later dependency on the same node and stage is skipped.
+func mergeWaitFor(wfs ...[]*WaitFor) []*WaitFor {
+	var res []*WaitFor
+	seen := map[string]struct{}{}
+	for _, ws := range wfs {
+		for _, w := range ws {
+			if w == nil {
+				continue
+			}
+			key := w.Node + "/" + w.GetStage()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			res = append(res, w)
+		}
+	}
+	return res
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
+)
+
+func TestWaitForUnmarshal(t *testing.T) {
+	in := `
+wait-for:
+  - srl1
+  - node: spine1
+    stage: healthy
+    timeout: 5m
+`
+	n := &NodeDefinition{}
+	if err := yaml.UnmarshalStrict([]byte(in), n); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*WaitFor{
+		{Node: "srl1"},
+		{Node: "spine1", Stage: WaitForStageHealthy, Timeout: "5m"},
+	}
+	if d := cmp.Diff(want, n.WaitFor); d != "" {
+		t.Errorf("unexpected wait-for (-want +got):\n%s", d)
+	}
+
+	// state files of the labs deployed by the previous versions keep the node names only
+	cfg := &NodeConfig{}
+	if err := json.Unmarshal([]byte(`{"wait-for":["srl1",{"node":"spine1","stage":"healthy","timeout":"5m"}]}`), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(want, cfg.WaitFor); d != "" {
+		t.Errorf("unexpected wait-for of the node config (-want +got):\n%s", d)
+	}
+}
+
+func TestWaitForValidate(t *testing.T) {
+	tests := map[string]struct {
+		w       *WaitFor
+		wantErr bool
+	}{
+		"string form":      {w: &WaitFor{Node: "srl1"}},
+		"post-deploy":      {w: &WaitFor{Node: "srl1", Stage: WaitForStagePostDeploy, Timeout: "90s"}},
+		"no node":          {w: &WaitFor{Stage: WaitForStageHealthy}, wantErr: true},
+		"invalid stage":    {w: &WaitFor{Node: "srl1", Stage: "running"}, wantErr: true},
+		"invalid timeout":  {w: &WaitFor{Node: "srl1", Timeout: "5"}, wantErr: true},
+		"negative timeout": {w: &WaitFor{Node: "srl1", Timeout: "-1m"}, wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := tc.w.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	if d := (&WaitFor{Node: "srl1"}).GetTimeout(); d != 0 {
+		t.Errorf("expected no timeout of the create stage, got %s", d)
+	}
+	if d := (&WaitFor{Node: "srl1", Stage: WaitForStageHealthy}).GetTimeout(); d != DefaultWaitForHealthyTimeout {
+		t.Errorf("expected default timeout of the healthy stage, got %s", d)
+	}
+	if d := (&WaitFor{Node: "srl1", Stage: WaitForStageHealthy, Timeout: "1m"}).GetTimeout(); d != time.Minute {
+		t.Errorf("expected timeout of 1m, got %s", d)
+	}
+}
+
+func TestGetWaitFor(t *testing.T) {
+	topo := &Topology{
+		Kinds: map[string]*NodeDefinition{
+			"linux": {WaitFor: []*WaitFor{
+				{Node: "srl1"},
+				{Node: "srl2", Stage: WaitForStageHealthy},
+			}},
+		},
+		Nodes: map[string]*NodeDefinition{
+			"client": {Kind: "linux", WaitFor: []*WaitFor{
+				{Node: "srl2", Stage: WaitForStageHealthy, Timeout: "1m"},
+				{Node: "srl2"},
+			}},
+		},
+	}
+
+	want := []*WaitFor{
+		{Node: "srl2", Stage: WaitForStageHealthy, Timeout: "1m"},
+		{Node: "srl2"},
+		{Node: "srl1"},
+	}
+	if d := cmp.Diff(want, topo.GetWaitFor("client")); d != "" {
+		t.Errorf("unexpected wait-for of client (-want +got):\n%s", d)
+	}
+}
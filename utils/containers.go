@@ -56,7 +56,7 @@ func GetCNIBinaryPath() string {
 // ContainerNSToPID resolves the name of a container via
 // the "/run/netns/<CONTAINERNAME>" to its PID.
 func ContainerNSToPID(cID string) (int, error) {
-	pnns, err := filepath.EvalSymlinks("/run/netns/" + cID)
+	pnns, err := filepath.EvalSymlinks(filepath.Join(NetnsDir(), cID))
 	if err != nil {
 		return 0, err
 	}
@@ -33,6 +33,16 @@ func FileExists(filename string) bool {
 	return !f.IsDir()
 }
 
+// DirExists returns true if a directory referenced by path exists & accessible.
+func DirExists(path string) bool {
+	f, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	return f.IsDir()
+}
+
 // CopyFile copies a file from src to dst. If src and dst files exist, and are
 // the same, then return success. Otherwise, copy the file contents from src to dst.
 // mode is the desired target file permissions, e.g. "0644".
@@ -8,8 +8,10 @@ import (
 	"crypto/rand"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/rootless"
 	"github.com/vishvananda/netlink"
 )
 
@@ -26,11 +28,23 @@ func BridgeByName(name string) (*netlink.Bridge, error) {
 	return br, nil
 }
 
+// defaultNetnsDir is the directory of the named network namespaces of iproute2 utility.
+const defaultNetnsDir = "/run/netns/"
+
+// NetnsDir returns the directory of the symlinks to the network namespaces of the containers,
+// which is set with CLAB_NETNS_DIR env var when the default directory is not writable, e.g. in the rootless mode.
+func NetnsDir() string {
+	if d, ok := os.LookupEnv(rootless.EnvNetnsDir); ok && d != "" {
+		return d
+	}
+	return defaultNetnsDir
+}
+
 // LinkContainerNS creates a symlink for containers network namespace
 // so that it can be managed by iproute2 utility.
 func LinkContainerNS(nspath, containerName string) error {
-	CreateDirectory("/run/netns/", 0755)
-	dst := "/run/netns/" + containerName
+	CreateDirectory(NetnsDir(), 0755)
+	dst := filepath.Join(NetnsDir(), containerName)
 	if _, err := os.Lstat(dst); err == nil {
 		os.Remove(dst)
 	}
@@ -79,7 +93,7 @@ func GenMac(oui string) string {
 // DeleteNetnsSymlink deletes a network namespace and removes the symlink created by LinkContainerNS func.
 func DeleteNetnsSymlink(n string) error {
 	log.Debug("Deleting netns symlink: ", n)
-	sl := fmt.Sprintf("/run/netns/%s", n)
+	sl := filepath.Join(NetnsDir(), n)
 	err := os.Remove(sl)
 	if err != nil {
 		log.Debug("Failed to delete netns symlink by path:", sl)